@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/SatGate-io/satgate-cli/internal/audit"
+	"github.com/SatGate-io/satgate-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	auditSince   string
+	auditCommand string
+	auditToken   string
+	auditLimit   int
+	auditFormat  string
+	auditOutput  string
+)
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Query the local audit log of mutating commands or the server-side trail",
+	Long: `Every mutating API call made by this CLI (mint, revoke, ...) is appended to a
+hash-chained JSON-lines log under ~/.satgate/audit/. Secrets in arguments and
+request bodies are redacted before they are written.
+
+Set audit.disabled: true or audit.dir in the config file to change this.
+
+Use 'audit remote' to see actions recorded by the gateway itself, including
+those taken from the dashboard or by other administrators.`,
+}
+
+var auditListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List audited actions, newest last",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entries, err := readAuditLog()
+		if err != nil {
+			return err
+		}
+		entries, err = filterAuditEntries(entries)
+		if err != nil {
+			return err
+		}
+		if auditLimit > 0 && len(entries) > auditLimit {
+			entries = entries[len(entries)-auditLimit:]
+		}
+
+		if flagJSON {
+			out, _ := json.MarshalIndent(entries, "", "  ")
+			fmt.Println(string(out))
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "SEQ\tTIME\tUSER\tCOMMAND\tREQUEST\tSTATUS\tTOKENS")
+		fmt.Fprintln(w, "───\t────\t────\t───────\t───────\t──────\t──────")
+		for _, e := range entries {
+			status := fmt.Sprint(e.Status)
+			if e.Error != "" {
+				status = "error"
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s %s\t%s\t%s\n",
+				e.Seq, truncate(e.Time, 19), e.User, e.Command, e.Method, e.Path, status, strings.Join(e.TokenIDs, ","))
+		}
+		w.Flush()
+
+		fmt.Fprintf(os.Stderr, "\n%d entries\n", len(entries))
+		return nil
+	},
+}
+
+var auditVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check the audit log's hash chain (non-zero exit if tampered)",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entries, err := readAuditLog()
+		if err != nil {
+			return err
+		}
+		problems := audit.Verify(entries)
+
+		if flagJSON {
+			out, _ := json.MarshalIndent(map[string]interface{}{
+				"entries":  len(entries),
+				"valid":    len(problems) == 0,
+				"problems": problems,
+			}, "", "  ")
+			fmt.Println(string(out))
+		} else if len(problems) == 0 {
+			fmt.Printf("✓ Audit log intact: %d entries, chain verified\n", len(entries))
+		} else {
+			for _, p := range problems {
+				fmt.Printf("✗ seq %d: %s\n", p.Seq, p.Reason)
+			}
+		}
+
+		if len(problems) > 0 {
+			return fmt.Errorf("audit log integrity check failed: %d problem(s)", len(problems))
+		}
+		return nil
+	},
+}
+
+var auditExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export audited actions as JSON lines or CSV",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entries, err := readAuditLog()
+		if err != nil {
+			return err
+		}
+		entries, err = filterAuditEntries(entries)
+		if err != nil {
+			return err
+		}
+
+		out := io.Writer(os.Stdout)
+		if auditOutput != "" {
+			f, err := os.OpenFile(auditOutput, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+			if err != nil {
+				return fmt.Errorf("creating %s: %w", auditOutput, err)
+			}
+			defer f.Close()
+			out = f
+		}
+
+		switch auditFormat {
+		case "jsonl", "json":
+			enc := json.NewEncoder(out)
+			for _, e := range entries {
+				if err := enc.Encode(e); err != nil {
+					return err
+				}
+			}
+		case "csv":
+			w := csv.NewWriter(out)
+			w.Write([]string{"seq", "time", "user", "profile", "gateway", "surface", "tenant", "command", "args", "method", "path", "request_body", "status", "token_ids", "error", "hash"})
+			for _, e := range entries {
+				w.Write([]string{
+					fmt.Sprint(e.Seq), e.Time, e.User, e.Profile, e.Gateway, e.Surface, e.Tenant,
+					e.Command, strings.Join(e.Args, " "), e.Method, e.Path, string(e.RequestBody),
+					fmt.Sprint(e.Status), strings.Join(e.TokenIDs, " "), e.Error, e.Hash,
+				})
+			}
+			w.Flush()
+			if err := w.Error(); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("invalid --format %q (use jsonl or csv)", auditFormat)
+		}
+
+		if auditOutput != "" {
+			fmt.Fprintf(os.Stderr, "✓ Exported %d entries to %s\n", len(entries), auditOutput)
+		}
+		return nil
+	},
+}
+
+func init() {
+	for _, c := range []*cobra.Command{auditListCmd, auditExportCmd} {
+		c.Flags().StringVar(&auditSince, "since", "", "only entries after this time (e.g. 24h, 7d, 2026-10-01)")
+		c.Flags().StringVar(&auditCommand, "command", "", "only entries for this command (e.g. mint, revoke)")
+		c.Flags().StringVar(&auditToken, "token", "", "only entries affecting this token ID")
+	}
+	auditListCmd.Flags().IntVar(&auditLimit, "limit", 50, "show at most this many entries (0 for all)")
+	auditExportCmd.Flags().StringVar(&auditFormat, "format", "jsonl", "export format: jsonl or csv")
+	auditExportCmd.Flags().StringVarP(&auditOutput, "output", "o", "", "write to a file instead of stdout")
+
+	auditCmd.AddCommand(auditListCmd)
+	auditCmd.AddCommand(auditVerifyCmd)
+	auditCmd.AddCommand(auditExportCmd)
+	rootCmd.AddCommand(auditCmd)
+}
+
+// auditDir returns the configured audit directory
+func auditDir() string {
+	if dir := config.Get().Audit.Dir; dir != "" {
+		return dir
+	}
+	return audit.DefaultDir()
+}
+
+func readAuditLog() ([]audit.Entry, error) {
+	return audit.Read(auditDir())
+}
+
+// filterAuditEntries applies --since, --command and --token
+func filterAuditEntries(entries []audit.Entry) ([]audit.Entry, error) {
+	var since time.Time
+	if auditSince != "" {
+		var err error
+		if since, err = parseTimeFlag(auditSince, clock()); err != nil {
+			return nil, fmt.Errorf("--since: %w", err)
+		}
+	}
+
+	var out []audit.Entry
+	for _, e := range entries {
+		if !since.IsZero() {
+			if t, err := time.Parse(time.RFC3339Nano, e.Time); err == nil && t.Before(since) {
+				continue
+			}
+		}
+		if auditCommand != "" && e.Command != "satgate "+auditCommand && e.Command != auditCommand {
+			continue
+		}
+		if auditToken != "" {
+			found := false
+			for _, id := range e.TokenIDs {
+				if id == auditToken || strings.HasPrefix(id, auditToken) {
+					found = true
+				}
+			}
+			if !found {
+				continue
+			}
+		}
+		out = append(out, e)
+	}
+	return out, nil
+}
@@ -0,0 +1,327 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/SatGate-io/satgate-cli/internal/audit"
+	"github.com/SatGate-io/satgate-cli/internal/client"
+	"github.com/SatGate-io/satgate-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	remoteSince     string
+	remoteUntil     string
+	remoteActor     string
+	remoteAction    string
+	remoteToken     string
+	remoteLimit     int
+	remotePageSize  int
+	remoteCorrelate bool
+)
+
+var auditRemoteCmd = &cobra.Command{
+	Use:   "remote",
+	Short: "Show the server-side audit trail (dashboard, API and other admins)",
+	Long: `Fetch audit events recorded by the gateway or SatGate Cloud, including
+actions taken from the dashboard or by other administrators, and render
+them as a timeline.
+
+Each API call made by this CLI carries an X-Request-Id header that is also
+stored in the local audit log. With --correlate (the default) remote events
+are matched to local entries by that ID, and local actions in the same
+window (and matching --action and --token) that the server has no record
+of are listed at the end. That comparison is skipped with --actor, which
+the local log cannot attribute, and when --limit cut the events short.`,
+	Example: `  satgate audit remote --since 7d
+  satgate audit remote --action revoke --actor alice@example.com
+  satgate audit remote --token tok_abc123 --json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := client.New()
+		if err != nil {
+			return err
+		}
+
+		now := clock()
+		var since, until time.Time
+		if remoteSince != "" {
+			if since, err = parseTimeFlag(remoteSince, now); err != nil {
+				return fmt.Errorf("--since: %w", err)
+			}
+		}
+		if remoteUntil != "" {
+			if until, err = parseTimeFlag(remoteUntil, now); err != nil {
+				return fmt.Errorf("--until: %w", err)
+			}
+		}
+
+		q := url.Values{}
+		if !since.IsZero() {
+			q.Set("since", since.UTC().Format(time.RFC3339))
+		}
+		if !until.IsZero() {
+			q.Set("until", until.UTC().Format(time.RFC3339))
+		}
+		if remoteActor != "" {
+			q.Set("actor", remoteActor)
+		}
+		if remoteAction != "" {
+			q.Set("action", remoteAction)
+		}
+		if remoteToken != "" {
+			q.Set("token_id", remoteToken)
+		}
+
+		events, truncated, err := fetchRemoteAudit(c, q, remoteLimit, remotePageSize)
+		if err != nil {
+			return err
+		}
+
+		var local map[string]audit.Entry
+		var unmatched []audit.Entry
+		skipped := ""
+		if remoteCorrelate {
+			entries, err := readAuditLog()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "⚠️  Cannot read local audit log: %v\n", err)
+			}
+			local, unmatched = correlateAudit(events, entries, config.Get(), since, until)
+			switch {
+			case remoteActor != "":
+				skipped = "--actor filters by server-side identity, which the local log does not record"
+			case truncated:
+				skipped = fmt.Sprintf("only the first %d events were fetched (raise --limit)", remoteLimit)
+			}
+			if skipped != "" {
+				unmatched = nil
+			}
+		}
+
+		if flagJSON {
+			type correlated struct {
+				remoteAuditEvent
+				LocalSeq int64 `json:"local_seq,omitempty"`
+			}
+			out := struct {
+				Events         []correlated  `json:"events"`
+				Truncated      bool          `json:"truncated,omitempty"`
+				UnmatchedLocal []audit.Entry `json:"unmatched_local,omitempty"`
+				NotCompared    string        `json:"local_not_compared,omitempty"`
+			}{Truncated: truncated, UnmatchedLocal: unmatched, NotCompared: skipped}
+			for _, e := range events {
+				out.Events = append(out.Events, correlated{e, local[e.RequestID].Seq})
+			}
+			data, _ := json.MarshalIndent(out, "", "  ")
+			fmt.Println(string(data))
+			return nil
+		}
+
+		printAuditTimeline(events, local, unmatched, skipped)
+		return nil
+	},
+}
+
+func init() {
+	auditRemoteCmd.Flags().StringVar(&remoteSince, "since", "7d", "only events after this time (e.g. 24h, 7d, 2026-10-01)")
+	auditRemoteCmd.Flags().StringVar(&remoteUntil, "until", "", "only events before this time")
+	auditRemoteCmd.Flags().StringVar(&remoteActor, "actor", "", "filter by actor (user email or API key name)")
+	auditRemoteCmd.Flags().StringVar(&remoteAction, "action", "", "filter by action (mint, revoke, mode_change, ...)")
+	auditRemoteCmd.Flags().StringVar(&remoteToken, "token", "", "filter by token ID")
+	auditRemoteCmd.Flags().IntVar(&remoteLimit, "limit", 500, "maximum events to fetch across all pages (0 for all)")
+	auditRemoteCmd.Flags().IntVar(&remotePageSize, "page-size", 100, "events requested per page")
+	auditRemoteCmd.Flags().BoolVar(&remoteCorrelate, "correlate", true, "match events to the local audit log by request ID")
+	auditCmd.AddCommand(auditRemoteCmd)
+}
+
+// remoteAuditEvent is one server-side audit event
+type remoteAuditEvent struct {
+	ID        string                 `json:"id"`
+	Time      string                 `json:"time"`
+	Actor     string                 `json:"actor"`
+	Source    string                 `json:"source"` // dashboard, api, cli
+	Action    string                 `json:"action"`
+	TokenID   string                 `json:"token_id"`
+	TokenName string                 `json:"token_name"`
+	RequestID string                 `json:"request_id"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+}
+
+// auditEventsPath returns the audit events endpoint for the client's surface
+func auditEventsPath(c *client.Client) string {
+	if c.Surface() == "cloud" {
+		return "/cloud/audit/events"
+	}
+	return "/admin/audit/events"
+}
+
+// fetchRemoteAudit follows next_cursor until the server runs out of events
+// or limit is reached, and returns events oldest first. truncated reports
+// that limit cut off events the server still had.
+func fetchRemoteAudit(c *client.Client, q url.Values, limit, pageSize int) (events []remoteAuditEvent, truncated bool, err error) {
+	cursor := ""
+	for {
+		q.Set("limit", strconv.Itoa(pageSize))
+		if cursor != "" {
+			q.Set("cursor", cursor)
+		}
+		data, code, err := c.Get(auditEventsPath(c) + "?" + q.Encode())
+		if err != nil {
+			return nil, false, fmt.Errorf("cannot reach gateway at %s: %w", config.Get().Gateway, err)
+		}
+		if code == 404 {
+			return nil, false, fmt.Errorf("this gateway does not expose an audit events endpoint (HTTP 404)")
+		}
+		if code != 200 {
+			return nil, false, fmt.Errorf("API returned HTTP %d: %s", code, string(data))
+		}
+
+		var page struct {
+			Events     []remoteAuditEvent `json:"events"`
+			NextCursor string             `json:"next_cursor"`
+		}
+		if err := json.Unmarshal(data, &page); err != nil {
+			// Some gateways return a bare array without pagination
+			if err := json.Unmarshal(data, &page.Events); err != nil {
+				return nil, false, fmt.Errorf("unexpected audit events response: %w", err)
+			}
+		}
+		events = append(events, page.Events...)
+
+		more := page.NextCursor != "" && page.NextCursor != cursor && len(page.Events) > 0
+		if limit > 0 && len(events) >= limit {
+			truncated = len(events) > limit || more
+			events = events[:limit]
+			break
+		}
+		if !more {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	sort.SliceStable(events, func(i, j int) bool { return eventBefore(events[i].Time, events[j].Time) })
+	return events, truncated, nil
+}
+
+// eventBefore orders RFC 3339 times by instant, whatever their UTC offset.
+// Unparseable times compare as strings.
+func eventBefore(a, b string) bool {
+	ta, okA := parseTimestamp(a)
+	tb, okB := parseTimestamp(b)
+	if okA && okB {
+		return ta.Before(tb)
+	}
+	return a < b
+}
+
+// correlateAudit indexes local entries by request ID and returns those in
+// the window, for the same gateway and matching --action and --token, that
+// no remote event references
+func correlateAudit(events []remoteAuditEvent, entries []audit.Entry, cfg *config.Config, since, until time.Time) (map[string]audit.Entry, []audit.Entry) {
+	byRequest := map[string]audit.Entry{}
+	for _, e := range entries {
+		if e.RequestID != "" {
+			byRequest[e.RequestID] = e
+		}
+	}
+	matched := map[string]audit.Entry{}
+	for _, ev := range events {
+		if e, ok := byRequest[ev.RequestID]; ok && ev.RequestID != "" {
+			matched[ev.RequestID] = e
+		}
+	}
+
+	var unmatched []audit.Entry
+	for _, e := range entries {
+		if e.RequestID == "" || e.Gateway != cfg.Gateway || e.Error != "" {
+			continue
+		}
+		if _, ok := matched[e.RequestID]; ok {
+			continue
+		}
+		if remoteAction != "" && localAuditAction(e) != remoteAction {
+			continue
+		}
+		if remoteToken != "" && !slices.Contains(e.TokenIDs, remoteToken) {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339Nano, e.Time)
+		if err != nil || (!since.IsZero() && t.Before(since)) || (!until.IsZero() && !t.Before(until)) {
+			continue
+		}
+		unmatched = append(unmatched, e)
+	}
+	return matched, unmatched
+}
+
+// localAuditAction names a local entry's command the way the server names
+// actions: "satgate mint" is mint
+func localAuditAction(e audit.Entry) string {
+	fields := strings.Fields(e.Command)
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[len(fields)-1]
+}
+
+func printAuditTimeline(events []remoteAuditEvent, local map[string]audit.Entry, unmatched []audit.Entry, skipped string) {
+	fmt.Println("Audit Timeline")
+	fmt.Println("─────────────────────────────")
+	if len(events) == 0 {
+		fmt.Println("  No audit events in this period.")
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	day := ""
+	for _, e := range events {
+		t, ok := parseTimestamp(e.Time)
+		d, hhmmss := e.Time, ""
+		if ok {
+			t = t.Local()
+			d, hhmmss = t.Format("Mon 2006-01-02"), t.Format("15:04:05")
+		}
+		if d != day {
+			w.Flush()
+			fmt.Printf("\n%s\n", d)
+			day = d
+		}
+		actor := e.Actor
+		if e.Source != "" {
+			actor += " (" + e.Source + ")"
+		}
+		token := truncate(e.TokenID, 16)
+		if e.TokenName != "" {
+			token += " " + e.TokenName
+		}
+		mark := ""
+		if l, ok := local[e.RequestID]; ok && e.RequestID != "" {
+			mark = fmt.Sprintf("local #%d", l.Seq)
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\n", hhmmss, actor, e.Action, token, mark)
+	}
+	w.Flush()
+
+	if local != nil {
+		fmt.Printf("\n%d of %d events matched the local audit log.\n", len(local), len(events))
+	}
+	if skipped != "" {
+		fmt.Printf("\nLocal actions were not checked for missing server-side events: %s.\n", skipped)
+	}
+	if len(unmatched) > 0 {
+		fmt.Printf("\n⚠️  %d local action(s) in this window have no server-side event:\n", len(unmatched))
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, e := range unmatched {
+			fmt.Fprintf(w, "  #%d\t%s\t%s\t%s %s\t%s\n", e.Seq, truncate(e.Time, 19), e.Command, e.Method, e.Path, e.RequestID)
+		}
+		w.Flush()
+	}
+}
@@ -0,0 +1,354 @@
+package cmd
+
+import (
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/SatGate-io/satgate-cli/internal/client"
+	"github.com/SatGate-io/satgate-cli/internal/config"
+	"github.com/SatGate-io/satgate-cli/internal/mock"
+)
+
+// Recorded L402 challenge for /api/premium/search: a 10 sat invoice issued
+// a minute before goldenNow and a macaroon carrying its payment hash
+const (
+	testInvoice  = "lnbc100n1p4dvpsypp54w46h2at4w46h2at4w46h2at4w46h2at4w46h2at4w46h2at4w4sdqhwpex2mtfw4kjqum9v9exx6qxqzjc88888888888888888888888888888888888888888888888888888888888888888888888888888888888888888888888888888888h6h5l3"
+	testMacaroon = "AgEHc2F0Z2F0ZQJCAACrq6urq6urq6urq6urq6urq6urq6urq6urq6urq6urqxERERERERERERERERERERERERERERERERERERERERERAAISc2VydmljZXM9cHJlbWl1bTowAAIbcHJlbWl1bV9jYXBhYmlsaXRpZXM9c2VhcmNoAAIcZXhwaXJlcz0yMDI2LTEwLTE5VDEzOjAwOjAwWgAABiAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=="
+)
+
+// gatewayAPI is a recorded self-hosted gateway
+func gatewayAPI() api {
+	return api{
+		"GET /admin/ping":                              ok("@gateway/health.json"),
+		"GET /admin/tokens":                            ok("@gateway/tokens.json"),
+		"GET /admin/tokens/tok_9f2a41c07b14":           ok("@gateway/token_detail.json"),
+		"PATCH /admin/tokens/tok_9f2a41c07b14":         ok("@gateway/token_detail.json"),
+		"DELETE /admin/tokens/tok_9f2a41c07b14/revoke": ok("@gateway/revoke.json"),
+		"POST /admin/tokens/mint":                      status(201, "@gateway/mint.json"),
+		"GET /admin/spend":                             ok("@gateway/spend.json"),
+		"GET /admin/spend?group_by":                    ok("@gateway/spend_groups.json"),
+		"GET /admin/spend?since=2026-09-01":            ok("@gateway/spend_groups_previous.json"),
+		"GET /admin/routes":                            ok("@gateway/routes.json"),
+		"GET /admin/reports/threats":                   ok("@gateway/threats.json"),
+		"GET /admin/audit/events":                      ok("@gateway/audit_events.json"),
+		"GET /admin/payments":                          ok("@gateway/payments.json"),
+	}
+}
+
+// cloudAPI is a recorded SatGate Cloud tenant
+func cloudAPI() api {
+	return api{
+		"GET /healthz":                                          ok("@cloud/health.json"),
+		"GET /cloud/delegation-v2/tree":                         ok("@cloud/tree.json"),
+		"GET /cloud/delegation-v2/token/tok_c10a00000002":       ok("@cloud/token_detail.json"),
+		"PATCH /cloud/delegation-v2/token/tok_c10a00000002":     ok("@cloud/token_detail.json"),
+		"POST /cloud/delegation-v2/revoke/tok_c10a00000002":     ok(`{"id":"tok_c10a00000002","status":"revoked"}`),
+		"POST /cloud/delegation-v2/delegate":                    status(201, "@cloud/delegate.json"),
+		"GET /cloud/delegation-v2/cost-rollups":                 ok("@cloud/rollups.json"),
+		"GET /cloud/delegation-v2/cost-rollups?from=2026-09-01": ok("@cloud/rollups_previous.json"),
+		"GET /cloud/reports/threats":                            ok("@gateway/threats.json"),
+		"GET /cloud/audit/events":                               ok("@gateway/audit_events.json"),
+	}
+}
+
+// with returns a copy of a with extra or replaced entries
+func (a api) with(kv ...interface{}) api {
+	out := api{}
+	for k, v := range a {
+		out[k] = v
+	}
+	for i := 0; i+1 < len(kv); i += 2 {
+		out[kv[i].(string)] = kv[i+1].(reply)
+	}
+	return out
+}
+
+// bulkCSV is a --from-file batch; bulkResults is an earlier run of it in
+// which one row was minted and another minted without delivering its secret
+const (
+	// remoteLocalLog is a local audit log for the test server with a mint
+	// and a revoke the recorded remote events do not reference
+	remoteLocalLog = `{"seq":1,"time":"2026-10-17T09:12:03Z","user":"alice","gateway":"$GATEWAY","surface":"gateway","command":"satgate mint","request_id":"req_000000000000000000000001","method":"POST","path":"/admin/tokens/mint","status":201,"token_ids":["tok_9f2a41c07b14"]}
+{"seq":2,"time":"2026-10-18T08:30:00Z","user":"alice","gateway":"$GATEWAY","surface":"gateway","command":"satgate revoke","request_id":"req_000000000000000000000002","method":"DELETE","path":"/admin/tokens/tok_77b0c5d1e9f0/revoke","status":200,"token_ids":["tok_77b0c5d1e9f0"]}
+`
+
+	bulkCSV = `agent,budget,routes,labels
+triage,25,/api/openai/*,team=support
+summarizer,10,,"team=support,env=dev"
+`
+	bulkResults = `{"row":1,"agent":"triage","status":"minted","token_id":"tok_9f2a41c07b20","secret":["file:secrets/triage.macaroon"],"time":"2026-10-19T11:00:00Z"}
+{"row":2,"agent":"summarizer","status":"minted-undelivered","token_id":"tok_9f2a41c07b21","error":"secret not delivered","time":"2026-10-19T11:00:01Z"}
+`
+)
+
+func TestGatewayCommands(t *testing.T) {
+	g := gatewayAPI()
+	challenge := reply{
+		status: 402,
+		body:   `{"error":"payment required"}`,
+		header: map[string]string{"WWW-Authenticate": `L402 macaroon="` + testMacaroon + `", invoice="` + testInvoice + `"`},
+	}
+
+	runGolden(t, []cliCase{
+		{name: "gateway_status", args: []string{"status"}, api: g},
+		{name: "gateway_status_json", args: []string{"status", "--json"}, api: g},
+		{name: "gateway_ping", args: []string{"ping"}, api: g},
+		{name: "gateway_version", args: []string{"version"}, api: g},
+
+		{name: "gateway_tokens", args: []string{"tokens"}, api: g},
+		{name: "gateway_tokens_json", args: []string{"tokens", "--json"}, api: g},
+		{name: "gateway_tokens_array", args: []string{"tokens"}, api: g.with("GET /admin/tokens", ok("@gateway/tokens_array.json"))},
+		{name: "gateway_tokens_tree", args: []string{"tokens", "--tree"}, api: g},
+		{name: "gateway_tokens_tree_mixed", args: []string{"tokens", "--tree"}, api: g.with("GET /admin/tokens", ok("@gateway/tokens_mixed.json"))},
+		{name: "gateway_tokens_tree_cycle_tail", args: []string{"tokens", "--tree"}, api: g.with("GET /admin/tokens", ok("@gateway/tokens_cycle_tail.json"))},
+		{name: "gateway_tokens_mermaid", args: []string{"tokens", "--format", "mermaid"}, api: g},
+		{name: "gateway_tokens_selector", args: []string{"tokens", "-l", "team=support"}, api: g},
+		{name: "gateway_tokens_selector_json", args: []string{"tokens", "-l", "team=support", "--json"}, api: g},
+		{name: "gateway_tokens_root_json", args: []string{"tokens", "--root", "tok_9f2a41c07b13", "--json"}, api: g},
+		{name: "gateway_tokens_raw", args: []string{"tokens", "--raw"}, api: g},
+		{name: "gateway_spend_selector_agent", args: []string{"spend", "-l", "env=prod", "--agent", "cs-bot"}, api: g},
+		{name: "gateway_tokens_search", args: []string{"tokens", "search", "status:active", "spent>100"}, api: g},
+		{name: "gateway_token_detail", args: []string{"token", "tok_9f2a41c07b14"}, api: g},
+		{name: "gateway_token_detail_json", args: []string{"token", "tok_9f2a41c07b14", "--json"}, api: g},
+		{name: "gateway_token_detail_sats", args: []string{"token", "tok_3c81d0e2aa01", "--days", "0", "-v"}, api: g.with("GET /admin/tokens/tok_3c81d0e2aa01", ok(`{
+			"id": "tok_3c81d0e2aa01", "name": "research-bot", "status": "active", "spent": 12000, "budget": 5000000, "currency": "SAT",
+			"caveats": [{"type": "budget", "op": "<=", "value": 5000000}]}`))},
+
+		{name: "gateway_spend", args: []string{"spend"}, api: g},
+		{name: "gateway_spend_json", args: []string{"spend", "--json"}, api: g},
+		{name: "gateway_spend_raw", args: []string{"spend"}, api: g.with("GET /admin/spend", ok(`{"window":"30d","note":"no agents yet"}`))},
+
+		{name: "gateway_mint", args: []string{"mint", "--agent", "new-bot", "--budget", "50", "--routes", "/api/openai/*", "--expiry", "30d", "--yes"}, api: g},
+		{name: "gateway_mint_dry_run", args: []string{"mint", "--agent", "new-bot", "--budget", "50", "--dry-run"}, api: g},
+		{name: "gateway_mint_json", args: []string{"mint", "--agent", "new-bot", "--budget", "50", "--yes", "--json"}, api: g},
+		{name: "gateway_revoke", args: []string{"revoke", "tok_9f2a41c07b14", "--yes"}, api: g},
+		{name: "gateway_revoke_prefix_dry_run", args: []string{"revoke", "tok_3c81", "--dry-run"}, api: g},
+		{name: "gateway_label", args: []string{"label", "tok_9f2a41c07b14", "owner=alice", "env-", "--yes"}, api: g},
+
+		{name: "gateway_report_threats", args: []string{"report", "threats", "--since", "24h"}, api: g},
+		{name: "gateway_report_threats_by_route", args: []string{"report", "threats", "--group-by", "route"}, api: g},
+		{name: "gateway_report_threats_json_filtered", args: []string{"report", "threats", "--agent", "cs-bot", "--since", "24h", "--json"}, api: g},
+		{name: "gateway_report_threats_shared_name", args: []string{"report", "threats", "--since", "24h"}, api: g.with("GET /admin/reports/threats", ok(`{"total_blocked": 40, "recent_threats": [
+			{"time": "2026-10-19T09:30:00Z", "type": "route_denied", "agent": "cs-bot", "token_id": "tok_9f2a41c07b14", "route": "/api/admin/users", "action": "blocked"},
+			{"time": "2026-10-19T09:00:00Z", "type": "invalid_token", "agent": "cs-bot", "token_id": "tok_5e11aa2b3c4d", "route": "/api/admin/users", "action": "blocked"},
+			{"time": "yesterday", "type": "forged_macaroon", "agent": "cs-bot", "token_id": "tok_5e11aa2b3c4d", "route": "/api/admin/keys", "action": "blocked"}
+		]}`))},
+		{name: "gateway_report_threats_json_revoke", args: []string{"report", "threats", "--json", "--revoke-offenders", "--risk-threshold", "5", "--dry-run"}, api: g},
+		{name: "gateway_report_spend", args: []string{"report", "spend", "--month", "2026-10", "--group-by", "agent"}, api: g},
+		{name: "gateway_report_spend_csv", args: []string{"report", "spend", "--month", "2026-10", "--group-by", "agent", "--format", "csv"}, api: g},
+		{name: "gateway_report_spend_markdown", args: []string{"report", "spend", "--month", "2026-10", "--group-by", "agent", "--format", "markdown"}, api: g},
+		{name: "gateway_report_compliance", args: []string{"report", "compliance"}, api: g},
+		{name: "gateway_revenue", args: []string{"revenue", "--since", "7d"}, api: g},
+		{name: "gateway_revenue_settled_window", args: []string{"revenue", "--since", "2026-10-12", "--until", "2026-10-13", "--format", "csv"}, api: g.with("GET /admin/payments", ok(`{"payments": [
+			{"payment_hash": "bb01", "route": "/api/premium/search", "client": "02ab34cd", "amount_sats": 10, "status": "settled", "created_at": "2026-10-11T23:59:00Z", "settled_at": "2026-10-12T00:00:30Z"},
+			{"payment_hash": "bb02", "route": "/api/premium/search", "client": "02ab34cd", "amount_sats": 20, "status": "settled", "created_at": "2026-10-12T23:59:50Z", "settled_at": "2026-10-13T00:00:10Z"},
+			{"payment_hash": "bb03", "route": "/api/premium/search", "client": "02ab34cd", "amount_sats": 30, "status": "expired", "created_at": "2026-10-12T08:00:00Z"}
+		]}`))},
+		{name: "gateway_revenue_settled_window_mock", args: []string{"revenue", "--since", "2026-10-12", "--until", "2026-10-13", "--format", "csv"}, fixture: `payments:
+  - {payment_hash: bb01, route: /api/premium/search, client: 02ab34cd, amount_sats: 10, status: settled, created_at: "2026-10-11T23:59:00Z", settled_at: "2026-10-12T00:00:30Z"}
+  - {payment_hash: bb02, route: /api/premium/search, client: 02ab34cd, amount_sats: 20, status: settled, created_at: "2026-10-12T23:59:50Z", settled_at: "2026-10-13T00:00:10Z"}
+  - {payment_hash: bb03, route: /api/premium/search, client: 02ab34cd, amount_sats: 30, status: expired, created_at: "2026-10-12T08:00:00Z"}
+`},
+		{name: "gateway_revenue_csv", args: []string{"revenue", "--since", "7d", "--format", "csv"}, api: g},
+		{name: "gateway_mode", args: []string{"mode"}, api: g},
+		{name: "gateway_audit_remote", args: []string{"audit", "remote", "--since", "30d"}, api: g},
+		{name: "gateway_audit_remote_action", args: []string{"audit", "remote", "--since", "30d", "--action", "revoke"}, api: g,
+			files: map[string]string{".satgate/audit/audit.jsonl": remoteLocalLog}},
+		{name: "gateway_audit_remote_truncated", args: []string{"audit", "remote", "--since", "30d", "--limit", "1"}, api: g,
+			files: map[string]string{".satgate/audit/audit.jsonl": remoteLocalLog}},
+		{name: "gateway_audit_remote_offsets", args: []string{"audit", "remote", "--since", "30d", "--correlate=false"}, api: g.with("GET /admin/audit/events", ok(`{"events": [
+			{"id": "evt_02", "time": "2026-10-18T09:00:00Z", "actor": "bob@example.com", "action": "revoke", "token_id": "tok_77b0c5d1e9f0"},
+			{"id": "evt_01", "time": "2026-10-18T10:00:00+02:00", "actor": "alice@example.com", "action": "mint", "token_id": "tok_9f2a41c07b14"}
+		]}`))},
+
+		{name: "gateway_probe", args: []string{"probe", "/api/premium/search"}, api: g.with("GET /api/premium/search", challenge)},
+		{name: "gateway_tokens_currency_gateway_rates", args: []string{"tokens", "--currency", "USD", "--rates", "gateway"}, api: g.with("GET /admin/rates", ok(`{"rates": {"BTC-USD": 65000}}`))},
+		{name: "gateway_mint_from_file", args: []string{"mint", "--from-file", "agents.csv", "--output-secret", "file:secrets/{{.Agent}}.macaroon", "--concurrency", "1", "--yes"}, api: g,
+			files: map[string]string{"agents.csv": bulkCSV}, show: []string{"agents.results.jsonl", "secrets/triage.macaroon"}, inHome: true},
+		{name: "gateway_mint_from_file_resume", args: []string{"mint", "--from-file", "agents.csv", "--output-secret", "file:secrets/{{.Agent}}.macaroon", "--yes"}, api: g,
+			files: map[string]string{"agents.csv": bulkCSV, "agents.results.jsonl": bulkResults}, inHome: true},
+		{name: "gateway_mint_from_file_undelivered", args: []string{"mint", "--from-file", "agents.csv", "--output-secret", "exec:exit 3", "--concurrency", "1", "--yes"}, api: g,
+			files: map[string]string{"agents.csv": bulkCSV}, show: []string{"agents.results.jsonl"}, inHome: true},
+		{name: "gateway_mint_from_file_secret_exists", args: []string{"mint", "--from-file", "agents.csv", "--output-secret", "file:secrets/{{.Agent}}.macaroon", "--yes"}, api: g,
+			files: map[string]string{"agents.csv": bulkCSV, "secrets/triage.macaroon": "AgEold\n"}, show: []string{"secrets/triage.macaroon"}, inHome: true},
+		{name: "gateway_mint_secret_exists", args: []string{"mint", "--agent", "ci-bot", "--budget", "5", "--output-secret", "file:ci.macaroon", "--yes"}, api: g,
+			files: map[string]string{"ci.macaroon": "AgEold\n"}, show: []string{"ci.macaroon"}, inHome: true},
+		{name: "gateway_mint_secret_force", args: []string{"mint", "--agent", "ci-bot", "--budget", "5", "--output-secret", "file:ci.macaroon", "--force", "--yes"}, api: g,
+			files: map[string]string{"ci.macaroon": "AgEold\n"}, show: []string{"ci.macaroon"}, inHome: true},
+
+		{name: "gateway_ping_verbose", args: []string{"ping", "--verbose"}, api: g},
+		{name: "gateway_mint_debug", args: []string{"mint", "--agent", "ci-bot", "--budget", "50", "--yes", "--debug"}, api: g},
+		{name: "gateway_revoke_print_curl", args: []string{"revoke", "tok_9f2a41c07b14", "--yes", "--print-curl"}, api: g},
+		{name: "gateway_try_allowed", args: []string{"try", "/api/openai/v1/models", "--macaroon", testMacaroon, "--token", "tok_9f2a41c07b14", "--settle", "0s"},
+			api: g.with("GET /api/openai/v1/models", reply{status: 200, body: `{"data":[]}`, header: map[string]string{"X-SatGate-Charge": "0.02", "X-SatGate-Decision": "allow"}})},
+		{name: "gateway_try_denied", args: []string{"try", "/api/admin/users", "--macaroon", testMacaroon, "--settle", "0s"},
+			api: g.with("GET /api/admin/users", reply{status: 403, body: `{"error":"forbidden","reason":"route_denied","caveat":"routes=/api/openai/*"}`})},
+	})
+}
+
+func TestCloudCommands(t *testing.T) {
+	c := cloudAPI()
+	runGolden(t, []cliCase{
+		{name: "cloud_status", surface: "cloud", args: []string{"status"}, api: c},
+		{name: "cloud_tokens", surface: "cloud", args: []string{"tokens"}, api: c},
+		{name: "cloud_tokens_json", surface: "cloud", args: []string{"tokens", "--json"}, api: c},
+		{name: "cloud_tokens_raw", surface: "cloud", args: []string{"tokens", "--raw"}, api: c},
+		{name: "cloud_tokens_tree", surface: "cloud", args: []string{"tokens", "--tree"}, api: c},
+		{name: "cloud_token_detail", surface: "cloud", args: []string{"token", "tok_c10a00000002"}, api: c},
+		{name: "cloud_spend", surface: "cloud", args: []string{"spend"}, api: c},
+		{name: "cloud_tokens_currency_rates_file", surface: "cloud", args: []string{"tokens", "--currency", "EUR", "--rates", "file:$HOME/rates.yaml"}, api: c,
+			files: map[string]string{"rates.yaml": "source: treasury\nas_of: 2026-10-19T09:00:00Z\nrates:\n  EUR-USD: 1.08\n"}},
+		{name: "cloud_spend_json", surface: "cloud", args: []string{"spend", "--json"}, api: c},
+		{name: "cloud_mint", surface: "cloud", args: []string{"mint", "--agent", "new-agent", "--budget", "25", "--parent", "tok_c10a00000002", "--routes", "/api/openai/*", "--yes"}, api: c},
+		{name: "cloud_revoke", surface: "cloud", args: []string{"revoke", "tok_c10a00000002", "--yes"}, api: c},
+		{name: "cloud_label", surface: "cloud", args: []string{"label", "tok_c10a00000002", "cost-center=cx-emea", "--overwrite", "--yes"}, api: c},
+		{name: "cloud_report_threats", surface: "cloud", args: []string{"report", "threats"}, api: c},
+		{name: "cloud_report_spend", surface: "cloud", args: []string{"report", "spend", "--month", "2026-10"}, api: c},
+		{name: "cloud_audit_remote", surface: "cloud", args: []string{"audit", "remote", "--since", "30d"}, api: c},
+		{name: "cloud_mode_unsupported", surface: "cloud", args: []string{"mode"}, api: c},
+		{name: "cloud_revenue_unsupported", surface: "cloud", args: []string{"revenue"}, api: c},
+		// The recorded tenant answers; the test server only has 404s
+		{name: "cloud_replay_tree", surface: "cloud", args: []string{"tokens", "--tree", "--replay", "testdata/cassettes/cloud_tree.json"}, api: api{}},
+		{name: "cloud_replay_mint_secret", surface: "cloud", args: []string{"mint", "--agent", "ci", "--budget", "5", "--output-secret", "file:ci.macaroon", "--yes", "--replay", "testdata/cassettes/cloud_tree.json"}, api: api{}, show: []string{"ci.macaroon"}},
+	})
+}
+
+// TestLocalCommands covers commands that work on files under ~/.satgate
+// or run a server rather than calling the API
+func TestLocalCommands(t *testing.T) {
+	g := gatewayAPI()
+	auditLog := map[string]string{".satgate/audit/audit.jsonl": "@home/audit.jsonl"}
+	templates := map[string]string{".satgate/templates/support-bot.yaml": "@home/support-bot.yaml"}
+
+	runGolden(t, []cliCase{
+		{name: "local_audit_list", args: []string{"audit", "list"}, api: g, files: auditLog},
+		{name: "local_audit_list_filtered", args: []string{"audit", "list", "--command", "mint", "--token", "tok_77e0b1a2c3d4"}, api: g, files: auditLog},
+		{name: "local_audit_list_json", args: []string{"audit", "list", "--limit", "1", "--json"}, api: g, files: auditLog},
+		{name: "local_audit_verify", args: []string{"audit", "verify"}, api: g, files: auditLog},
+		{name: "local_audit_verify_tampered", args: []string{"audit", "verify"}, api: g,
+			files: map[string]string{".satgate/audit/audit.jsonl": "@home/audit_tampered.jsonl"}},
+		{name: "local_audit_export_csv", args: []string{"audit", "export", "--format", "csv"}, api: g, files: auditLog},
+		{name: "local_audit_export_file", args: []string{"audit", "export", "--since", "2026-10-19", "-o", "export.jsonl"}, api: g, files: auditLog,
+			show: []string{"export.jsonl"}, inHome: true},
+
+		{name: "local_template_list", args: []string{"template", "list"}, api: g, files: templates},
+		{name: "local_template_show", args: []string{"template", "show", "support-bot"}, api: g, files: templates},
+		{name: "local_template_create", args: []string{"template", "create", "ci", "--budget", "5", "--expiry", "7d", "--routes", "/api/openai/*", "--label", "env=ci"}, api: g,
+			show: []string{".satgate/templates/ci.yaml"}},
+		{name: "local_template_create_exists", args: []string{"template", "create", "support-bot", "--budget", "5"}, api: g, files: templates},
+		{name: "local_template_mint", args: []string{"mint", "--template", "support-bot", "--agent", "triage", "--var", "team=support", "--dry-run"}, api: g, files: templates},
+		{name: "local_template_mint_bad_var", args: []string{"mint", "--template", "support-bot", "--agent", "triage", "--var", "team-name=support", "--dry-run"}, api: g, files: templates},
+
+		{name: "local_mock_seed", args: []string{"mock", "seed"}, api: g},
+		{name: "local_mock_serve", args: []string{"mock", "serve", "--seed", "example", "--listen", "127.0.0.1:0", "--quiet"}, api: g, stopped: true},
+		{name: "local_mock_serve_bad_seed", args: []string{"mock", "serve", "--seed", "$HOME/missing.yaml"}, api: g},
+	})
+}
+
+// TestMockCommands runs each surface against mock.Server seeded with the
+// example fixture rather than recorded responses, so the CLI and the mock
+// are checked against each other
+func TestMockCommands(t *testing.T) {
+	example := string(mock.Example)
+	runGolden(t, []cliCase{
+		{name: "mock_gateway_status", args: []string{"status"}, fixture: example},
+		{name: "mock_gateway_tokens", args: []string{"tokens"}, fixture: example},
+		{name: "mock_gateway_tokens_json", args: []string{"tokens", "--json"}, fixture: example},
+		{name: "mock_gateway_tokens_currency", args: []string{"tokens", "--currency", "USD", "--rates", "gateway"}, fixture: example},
+		{name: "mock_gateway_token", args: []string{"token", "tok_support"}, fixture: example},
+		{name: "mock_gateway_spend", args: []string{"spend"}, fixture: example},
+		{name: "mock_gateway_search", args: []string{"tokens", "search", "spent>100"}, fixture: example},
+		{name: "mock_gateway_report_threats", args: []string{"report", "threats", "--since", "7d"}, fixture: example},
+		{name: "mock_gateway_revenue", args: []string{"revenue", "--since", "7d"}, fixture: example},
+		{name: "mock_gateway_audit_remote", args: []string{"audit", "remote", "--since", "90d", "--correlate=false"}, fixture: example},
+		{name: "mock_gateway_mint", args: []string{"mint", "--agent", "ci-bot", "--budget", "5", "--expiry", "7d", "--yes"}, fixture: example},
+		{name: "mock_gateway_revoke", args: []string{"revoke", "tok_research003", "--yes"}, fixture: example},
+
+		{name: "mock_cloud_tokens", surface: "cloud", args: []string{"tokens"}, fixture: example},
+		{name: "mock_cloud_token", surface: "cloud", args: []string{"token", "tok_support0002"}, fixture: example},
+		{name: "mock_cloud_spend", surface: "cloud", args: []string{"spend"}, fixture: example},
+		{name: "mock_cloud_report_threats", surface: "cloud", args: []string{"report", "threats", "--since", "7d"}, fixture: example},
+		{name: "mock_cloud_mint", surface: "cloud", args: []string{"mint", "--agent", "triage", "--budget", "20", "--routes", "/api/openai/*", "--parent", "tok_support", "--yes"}, fixture: example},
+		{name: "mock_cloud_revoke", surface: "cloud", args: []string{"revoke", "tok_support0002", "--yes"}, fixture: example},
+	})
+}
+
+// TestExporterMetrics scrapes the recorded gateway once and compares the
+// metrics page; the exporter command itself only serves it
+func TestExporterMetrics(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		surface string
+		api     api
+	}{
+		{"exporter_gateway", "gateway", gatewayAPI()},
+		{"exporter_cloud", "cloud", cloudAPI()},
+		{"exporter_gateway_down", "gateway", api{}},
+		{"exporter_cloud_repeated_rollups", "cloud", cloudAPI().with("GET /cloud/delegation-v2/cost-rollups", ok(`{"rollups": [
+			{"costCenter": "eng", "department": "platform", "totalAllocated": 1000, "totalConsumed": 100},
+			{"costCenter": "eng", "department": "platform", "totalAllocated": 2000, "totalConsumed": 250},
+			{"totalAllocated": 500, "totalConsumed": 50},
+			{"costCenter": "", "department": "", "totalAllocated": 500, "totalConsumed": 25}
+		]}`))},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := serveAPI(t, tc.api, tc.surface)
+			clock = func() time.Time { return goldenNow }
+			defer func() { clock = time.Now }()
+			config.Load("")
+			c, err := client.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			e := &exporter{client: c}
+			e.scrape()
+
+			rec := httptest.NewRecorder()
+			e.serveMetrics(rec, httptest.NewRequest("GET", "/metrics", nil))
+			got := timingMetric.ReplaceAllString(rec.Body.String(), "${1} 0.001")
+			checkGolden(t, tc.name, normalize(got, srv.URL))
+		})
+	}
+}
+
+// timingMetric matches the gauges that measure this run's latency
+var timingMetric = regexp.MustCompile(`(?m)^(satgate_(gateway_latency|scrape_duration)_seconds) \S+$`)
+
+func TestErrorPaths(t *testing.T) {
+	g, c := gatewayAPI(), cloudAPI()
+	unauthorized := status(401, `{"error":"invalid admin token"}`)
+	notFound := status(404, `{"error":"token not found"}`)
+	serverError := status(500, "internal server error")
+	malformed := ok(`{"tokens": [{"id": "tok_1",`)
+
+	runGolden(t, []cliCase{
+		{name: "error_tokens_401", args: []string{"tokens"}, api: g.with("GET /admin/tokens", unauthorized)},
+		{name: "error_tokens_500", args: []string{"tokens"}, api: g.with("GET /admin/tokens", serverError)},
+		{name: "error_tokens_malformed", args: []string{"tokens"}, api: g.with("GET /admin/tokens", malformed)},
+		{name: "error_token_404", args: []string{"token", "tok_missing"}, api: g.with("GET /admin/tokens/tok_missing", notFound)},
+		{name: "error_token_ambiguous", args: []string{"token", "tok_9f2a"}, api: g},
+		{name: "error_spend_401", args: []string{"spend"}, api: g.with("GET /admin/spend", unauthorized)},
+		{name: "error_spend_500", args: []string{"spend"}, api: g.with("GET /admin/spend", serverError)},
+		{name: "error_spend_malformed", args: []string{"spend"}, api: g.with("GET /admin/spend", ok(`{"agents": [`))},
+		{name: "error_status_500", args: []string{"status"}, api: g.with("GET /admin/ping", serverError)},
+		{name: "error_ping_500", args: []string{"ping"}, api: g.with("GET /admin/ping", serverError)},
+		{name: "error_mint_400", args: []string{"mint", "--agent", "x", "--budget", "5", "--yes"}, api: g.with("POST /admin/tokens/mint", status(400, `{"error":"budget exceeds parent"}`))},
+		{name: "error_revoke_404", args: []string{"revoke", "tok_missing", "--yes"}, api: g},
+		{name: "error_report_threats_500", args: []string{"report", "threats"}, api: g.with("GET /admin/reports/threats", serverError)},
+		{name: "error_report_threats_malformed", args: []string{"report", "threats"}, api: g.with("GET /admin/reports/threats", ok("<html>maintenance</html>"))},
+		{name: "error_mode_401", args: []string{"mode"}, api: g.with("GET /admin/routes", unauthorized)},
+		{name: "error_label_conflict", surface: "cloud", args: []string{"label", "tok_c10a00000002", "cost-center=cx-emea", "--yes"}, api: c},
+		{name: "error_revenue_404", args: []string{"revenue"}, api: g.with("GET /admin/payments", status(404, "404 page not found"))},
+		{name: "error_cloud_tree_401", surface: "cloud", args: []string{"tokens"}, api: c.with("GET /cloud/delegation-v2/tree", status(401, `{"error":"session expired"}`))},
+		{name: "error_cloud_rollups_malformed", surface: "cloud", args: []string{"spend"}, api: c.with("GET /cloud/delegation-v2/cost-rollups", ok(`{"rollups": "soon"}`))},
+		{name: "error_report_spend_all_time", args: []string{"report", "spend", "--month", "2026-10"}, api: g.with("GET /admin/spend?group_by", ok("@gateway/spend.json"))},
+		{name: "error_cloud_tokens_currency", surface: "cloud", args: []string{"tokens", "--currency", "EUR"}, api: c},
+		{name: "error_cloud_report_spend_by_agent", surface: "cloud", args: []string{"report", "spend", "--month", "2026-10", "--group-by", "agent"}, api: c},
+		{name: "error_tokens_root_filtered", args: []string{"tokens", "--root", "tok_9f2a41c07b13", "-l", "team=support"}, api: g},
+		{name: "error_tokens_raw_selector", args: []string{"tokens", "--raw", "-l", "team=support"}, api: g},
+		{name: "error_spend_selector_period", args: []string{"spend", "-l", "team=support", "--period", "7d"}, api: g},
+		{name: "error_unknown_flag", args: []string{"tokens", "--bogus"}, api: g},
+	})
+}
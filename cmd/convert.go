@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/SatGate-io/satgate-cli/internal/client"
+	"github.com/SatGate-io/satgate-cli/internal/config"
+	"github.com/SatGate-io/satgate-cli/internal/money"
+	"github.com/SatGate-io/satgate-cli/internal/rates"
+	"github.com/spf13/pflag"
+)
+
+var (
+	displayCurrency string
+	rateSource      string
+)
+
+// addCurrencyFlags registers --currency and --rates on a display command
+func addCurrencyFlags(f *pflag.FlagSet) {
+	f.StringVar(&displayCurrency, "currency", "", "show amounts in this currency: USD, EUR, SAT or MSAT")
+	f.StringVar(&rateSource, "rates", "", "rate source for --currency: "+rates.Sources+" (default: rates in config, else ~/.satgate/rates.yaml, else gateway; cloud needs a file or URL)")
+}
+
+// converter shows amounts in --currency. The rate table is loaded on the
+// first conversion that needs one, and every rate used is remembered so
+// the output can be annotated with it.
+type converter struct {
+	to       money.Currency
+	provider rates.Provider
+	table    *rates.Table
+	used     map[string]rates.Quote
+}
+
+// newConverter returns nil when no --currency was asked for
+func newConverter(c *client.Client) (*converter, error) {
+	if displayCurrency == "" {
+		return nil, nil
+	}
+	to, err := money.Lookup(displayCurrency)
+	if err != nil {
+		return nil, fmt.Errorf("--currency: %w", err)
+	}
+	// Cloud has no rates endpoint; only the gateway prices in sats
+	var get rates.Getter = c.Get
+	if c.Surface() == "cloud" {
+		get = nil
+	}
+	provider, err := rates.Parse(firstNonEmpty(rateSource, config.Get().Rates), get, clock)
+	if err != nil {
+		return nil, fmt.Errorf("--rates: %w", err)
+	}
+	return &converter{to: to, provider: provider, used: map[string]rates.Quote{}}, nil
+}
+
+// convert returns v (display units of from) in the target currency,
+// rounded to its minor unit
+func (cv *converter) convert(v float64, from string) (float64, error) {
+	from = currencyCode(from)
+	if from == cv.to.Code {
+		return v, nil
+	}
+	if cv.table == nil {
+		table, err := cv.provider.Load()
+		if err != nil {
+			return 0, err
+		}
+		cv.table = table
+	}
+	q, err := cv.table.Rate(from, cv.to.Code)
+	if err != nil {
+		return 0, fmt.Errorf("--currency %s: %w", cv.to.Code, err)
+	}
+	cv.used[q.From+"-"+q.To] = q
+	return money.FromFloat(v*q.Rate, cv.to).Float(), nil
+}
+
+// tokens converts each token's spend and budget
+func (cv *converter) tokens(tokens []tokenInfo) ([]tokenInfo, error) {
+	if cv == nil {
+		return tokens, nil
+	}
+	out := make([]tokenInfo, len(tokens))
+	for i, t := range tokens {
+		var err error
+		if t.Spent, err = cv.convert(t.Spent, t.Currency); err != nil {
+			return nil, err
+		}
+		if t.Budget, err = cv.convert(t.Budget, t.Currency); err != nil {
+			return nil, err
+		}
+		t.Currency = cv.to.Code
+		out[i] = t
+	}
+	return out, nil
+}
+
+// quotes returns the rates used, in a stable order
+func (cv *converter) quotes() []rates.Quote {
+	if cv == nil {
+		return nil
+	}
+	keys := make([]string, 0, len(cv.used))
+	for k := range cv.used {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	out := make([]rates.Quote, len(keys))
+	for i, k := range keys {
+		out[i] = cv.used[k]
+	}
+	return out
+}
+
+// meta describes the conversion for JSON output
+func (cv *converter) meta() map[string]interface{} {
+	if cv == nil {
+		return nil
+	}
+	return map[string]interface{}{
+		"currency": cv.to.Code,
+		"rates":    cv.quotes(),
+	}
+}
+
+// note writes which rates were used, e.g.
+// "Rates: 1 SAT = 0.00065 USD (static ~/.satgate/rates.yaml, as of 2026-10-19 12:00 UTC)"
+func (cv *converter) note(w io.Writer) {
+	if s := describeQuotes(cv.quotes()); s != "" {
+		fmt.Fprintf(w, "\nRates: %s\n", s)
+	}
+}
+
+// describeQuotes lists the rates followed by their source and timestamp.
+// Quotes from one converter share a table, so the first one's source
+// stands for all.
+func describeQuotes(quotes []rates.Quote) string {
+	if len(quotes) == 0 {
+		return ""
+	}
+	parts := make([]string, len(quotes))
+	for i, q := range quotes {
+		parts[i] = fmt.Sprintf("1 %s = %s %s", q.From, formatRate(q.Rate), q.To)
+	}
+	s := strings.Join(parts, ", ") + " (" + quotes[0].Source
+	if !quotes[0].AsOf.IsZero() {
+		s += ", as of " + quotes[0].AsOf.UTC().Format("2006-01-02 15:04 MST")
+	}
+	return s + ")"
+}
+
+// formatRate keeps enough significant digits for sat-sized rates
+func formatRate(r float64) string {
+	if r >= 1 {
+		return fmt.Sprintf("%.2f", r)
+	}
+	return fmt.Sprintf("%.4g", r)
+}
@@ -0,0 +1,331 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/SatGate-io/satgate-cli/internal/client"
+	"github.com/SatGate-io/satgate-cli/internal/config"
+	"github.com/SatGate-io/satgate-cli/internal/telemetry"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exporterListen   string
+	exporterInterval time.Duration
+)
+
+var exporterCmd = &cobra.Command{
+	Use:   "exporter",
+	Short: "Serve gateway metrics on /metrics in Prometheus text format",
+	Long: `Run a Prometheus exporter that periodically scrapes the same endpoints used by
+status, tokens, spend, report threats and mode, and serves the results on /metrics.
+
+Exposed metrics include gateway up/latency, per-token spend, budget and
+utilization, token counts by status, blocked requests by category and
+routes by policy mode. Token spend and budget are in each token's own
+currency, given by the currency label.
+
+With --trace, the spans of each scrape are exported when it finishes.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := config.Get()
+		c, err := client.New()
+		if err != nil {
+			return err
+		}
+		if exporterInterval < time.Second {
+			return fmt.Errorf("--interval must be at least 1s")
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		e := &exporter{client: c}
+		e.scrape()
+		go func() {
+			ticker := time.NewTicker(exporterInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					e.scrape()
+				}
+			}
+		}()
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("/metrics", e.serveMetrics)
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/" {
+				http.NotFound(w, r)
+				return
+			}
+			fmt.Fprintln(w, `<html><body><h1>SatGate Exporter</h1><a href="/metrics">Metrics</a></body></html>`)
+		})
+
+		srv := &http.Server{Addr: exporterListen, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+		go func() {
+			<-ctx.Done()
+			shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			srv.Shutdown(shutdown)
+		}()
+
+		fmt.Fprintf(os.Stderr, "⚡ Exporting metrics for %s (%s) on %s/metrics every %s\n",
+			cfg.Gateway, cfg.Surface, exporterListen, exporterInterval)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	},
+}
+
+func init() {
+	exporterCmd.Flags().StringVar(&exporterListen, "listen", ":9469", "address to serve /metrics on")
+	exporterCmd.Flags().DurationVar(&exporterInterval, "interval", 30*time.Second, "how often to scrape the gateway")
+	rootCmd.AddCommand(exporterCmd)
+}
+
+// exporter scrapes the admin API on an interval and caches the rendered
+// metrics so Prometheus scrapes never block on the gateway.
+type exporter struct {
+	client *client.Client
+
+	mu      sync.RWMutex
+	payload []byte
+}
+
+func (e *exporter) serveMetrics(w http.ResponseWriter, r *http.Request) {
+	e.mu.RLock()
+	payload := e.payload
+	e.mu.RUnlock()
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write(payload)
+}
+
+// scrape queries every endpoint and swaps in the freshly rendered payload.
+// Individual endpoint failures are reported via satgate_scrape_success
+// rather than aborting the whole scrape.
+func (e *exporter) scrape() {
+	start := time.Now()
+	m := newMetricSet()
+	c := e.client
+
+	// Health and latency
+	healthStart := time.Now()
+	_, code, err := c.Get(healthPath(c))
+	latency := time.Since(healthStart).Seconds()
+	up := 0.0
+	if err == nil && code == 200 {
+		up = 1
+	}
+	m.add("satgate_up", "gauge", "Whether the gateway health endpoint returned HTTP 200.", up)
+	m.add("satgate_gateway_latency_seconds", "gauge", "Latency of the gateway health check.", latency)
+
+	success := map[string]bool{
+		"health": up == 1,
+	}
+
+	// Tokens
+	if data, ok := e.fetch(tokensPath(c)); ok {
+		success["tokens"] = true
+		tokens := parseTokens(data)
+
+		byStatus := map[string]int{}
+		for _, t := range tokens {
+			status := t.Status
+			if status == "" {
+				status = "unknown"
+			}
+			byStatus[status]++
+		}
+		for _, status := range sortedKeys(byStatus) {
+			m.add("satgate_tokens", "gauge", "Number of tokens by status.", float64(byStatus[status]), "status", status)
+		}
+
+		for _, t := range tokens {
+			m.add("satgate_token_spent", "gauge", "Amount spent by a token, in its currency.", t.Spent, "id", t.ID, "name", t.Name, "currency", currencyCode(t.Currency))
+		}
+		for _, t := range tokens {
+			m.add("satgate_token_budget", "gauge", "Budget ceiling of a token in its currency (0 = unlimited).", t.Budget, "id", t.ID, "name", t.Name, "currency", currencyCode(t.Currency))
+		}
+		for _, t := range tokens {
+			if t.Budget > 0 {
+				m.add("satgate_token_budget_utilization_ratio", "gauge", "Fraction of the token budget spent.", t.Spent/t.Budget, "id", t.ID, "name", t.Name)
+			}
+		}
+	} else {
+		success["tokens"] = false
+	}
+
+	// Spend
+	spendPath := "/admin/spend"
+	if c.Surface() == "cloud" {
+		spendPath = "/cloud/delegation-v2/cost-rollups"
+	}
+	if data, ok := e.fetch(spendPath); ok {
+		success["spend"] = true
+		var rollups spendRollups
+		var summary spendSummary
+		if err := json.Unmarshal(data, &rollups); err == nil && len(rollups.Rollups) > 0 {
+			// Rollups can repeat a cost center and department (or leave
+			// them blank); Prometheus rejects duplicate series, so sum them
+			type group struct{ costCenter, department string }
+			consumed, allocated := map[group]float64{}, map[group]float64{}
+			var groups []group
+			for _, r := range rollups.Rollups {
+				g := group{r.CostCenter, r.Department}
+				if _, ok := consumed[g]; !ok {
+					groups = append(groups, g)
+				}
+				consumed[g] += r.TotalConsumed / 100
+				allocated[g] += r.TotalAllocated / 100
+			}
+			sort.Slice(groups, func(i, j int) bool {
+				if groups[i].costCenter != groups[j].costCenter {
+					return groups[i].costCenter < groups[j].costCenter
+				}
+				return groups[i].department < groups[j].department
+			})
+			for _, g := range groups {
+				m.add("satgate_spend_consumed_dollars", "gauge", "Spend consumed.", consumed[g], "cost_center", g.costCenter, "department", g.department)
+			}
+			for _, g := range groups {
+				m.add("satgate_spend_allocated_dollars", "gauge", "Budget allocated.", allocated[g], "cost_center", g.costCenter, "department", g.department)
+			}
+		} else if err := json.Unmarshal(data, &summary); err == nil {
+			m.add("satgate_spend_consumed_dollars", "gauge", "Spend consumed.", summary.TotalConsumed)
+			m.add("satgate_spend_allocated_dollars", "gauge", "Budget allocated.", summary.TotalAllocated)
+		}
+	} else {
+		success["spend"] = false
+	}
+
+	// Threats
+	if data, ok := e.fetch(threatsPath(c)); ok {
+		success["threats"] = true
+		var report threatReport
+		json.Unmarshal(data, &report)
+		m.add("satgate_blocked_requests", "gauge", "Requests blocked in the gateway's reporting window.", float64(report.TotalBlocked))
+		for _, cat := range report.Categories {
+			m.add("satgate_blocked_requests_by_category", "gauge", "Requests blocked by threat category.", float64(cat.Count), "category", cat.Name)
+		}
+	} else {
+		success["threats"] = false
+	}
+
+	// Routes
+	if data, ok := e.fetch("/admin/routes"); ok {
+		success["routes"] = true
+		byMode := map[string]int{}
+		for _, r := range parseRoutes(data) {
+			byMode[canonicalMode(r.Policy)]++
+		}
+		for _, mode := range sortedKeys(byMode) {
+			m.add("satgate_routes", "gauge", "Number of routes by policy mode.", float64(byMode[mode]), "mode", mode)
+		}
+	} else {
+		success["routes"] = false
+	}
+
+	for _, endpoint := range sortedKeys(success) {
+		v := 0.0
+		if success[endpoint] {
+			v = 1
+		}
+		m.add("satgate_scrape_success", "gauge", "Whether the last scrape of an endpoint succeeded.", v, "endpoint", endpoint)
+	}
+	m.add("satgate_scrape_duration_seconds", "gauge", "Duration of the last full scrape.", time.Since(start).Seconds())
+	m.add("satgate_last_scrape_timestamp_seconds", "gauge", "Unix time of the last scrape.", float64(clock().Unix()))
+
+	e.mu.Lock()
+	e.payload = m.bytes()
+	e.mu.Unlock()
+
+	if err := telemetry.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  %v\n", err)
+	}
+}
+
+// fetch GETs a path and returns the body if the gateway answered 200
+func (e *exporter) fetch(path string) ([]byte, bool) {
+	data, code, err := e.client.Get(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "scrape %s: %v\n", path, err)
+		return nil, false
+	}
+	if code != 200 {
+		fmt.Fprintf(os.Stderr, "scrape %s: HTTP %d\n", path, code)
+		return nil, false
+	}
+	return data, true
+}
+
+// metricSet renders samples in the Prometheus text exposition format.
+// Samples of one family must be added consecutively.
+type metricSet struct {
+	buf  bytes.Buffer
+	seen map[string]bool
+}
+
+func newMetricSet() *metricSet {
+	return &metricSet{seen: map[string]bool{}}
+}
+
+// add writes one sample. labels are alternating name/value pairs; empty
+// values are omitted.
+func (m *metricSet) add(name, typ, help string, value float64, labels ...string) {
+	if !m.seen[name] {
+		m.seen[name] = true
+		fmt.Fprintf(&m.buf, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+	}
+	m.buf.WriteString(name)
+	var pairs []string
+	for i := 0; i+1 < len(labels); i += 2 {
+		if labels[i+1] == "" {
+			continue
+		}
+		pairs = append(pairs, fmt.Sprintf("%s=%q", labels[i], escapeLabel(labels[i+1])))
+	}
+	if len(pairs) > 0 {
+		m.buf.WriteString("{" + strings.Join(pairs, ",") + "}")
+	}
+	m.buf.WriteString(" " + strconv.FormatFloat(value, 'g', -1, 64) + "\n")
+}
+
+func (m *metricSet) bytes() []byte {
+	return m.buf.Bytes()
+}
+
+// escapeLabel strips characters %q would escape differently from Prometheus
+func escapeLabel(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 {
+			return ' '
+		}
+		return r
+	}, s)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,332 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SatGate-io/satgate-cli/internal/mock"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Run with -update to rewrite the golden files after an intended output
+// change, then review the diff.
+var update = flag.Bool("update", false, "rewrite testdata/golden files")
+
+// goldenNow is the pinned clock for every case
+var goldenNow = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+// testdata is the absolute testdata directory, which cases running in
+// $HOME still read recorded responses from
+var testdata string
+
+// TestMain pins the local time zone before any test server starts, so
+// times print the same everywhere
+func TestMain(m *testing.M) {
+	flag.Parse()
+	time.Local = time.UTC
+	testdata, _ = filepath.Abs("testdata")
+	os.Exit(m.Run())
+}
+
+// reply is a recorded API response. A body starting with @ is read from
+// testdata/api.
+type reply struct {
+	status int
+	body   string
+	header map[string]string
+}
+
+// api maps "METHOD /path" to a reply. A key may add "?text" to match only
+// requests whose raw query contains text; those win over the plain path,
+// and the longest text wins among them. Requests without an entry get a 404.
+type api map[string]reply
+
+func ok(body string) reply { return reply{status: 200, body: body} }
+
+func status(code int, body string) reply { return reply{status: code, body: body} }
+
+type cliCase struct {
+	name    string   // golden file, testdata/golden/<name>.golden
+	surface string   // gateway (default) or cloud
+	args    []string // $HOME is the case's temporary home directory
+	api     api
+	fixture string // a mock.Server fixture (YAML, or @file in testdata) served instead of api
+
+	files   map[string]string // written under $HOME first, with $GATEWAY as the test server; a value starting with @ is read from testdata
+	show    []string          // files under $HOME appended to the transcript afterwards
+	inHome  bool              // run in $HOME, so file arguments and output paths are relative
+	stopped bool              // run with a cancelled context, so servers exit once started
+}
+
+// runGolden runs each case against its recorded API and compares the
+// output with the golden file
+func runGolden(t *testing.T, cases []cliCase) {
+	t.Helper()
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			checkGolden(t, tc.name, runCLI(t, tc))
+		})
+	}
+}
+
+// checkGolden compares got with testdata/golden/<name>.golden, or rewrites
+// the file under -update
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join(testdata, "golden", name+".golden")
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("missing golden file (run go test ./cmd -update): %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("output differs from %s (run go test ./cmd -update to accept)\n--- got\n%s\n--- want\n%s", path, got, want)
+	}
+}
+
+// runCLI executes satgate with tc.args against a test server and returns
+// stdout, stderr, the returned error and any tc.show files as one
+// transcript
+func runCLI(t *testing.T, tc cliCase) string {
+	t.Helper()
+	var srv *httptest.Server
+	if tc.fixture != "" {
+		srv = serveMock(t, tc.fixture, tc.surface)
+	} else {
+		srv = serveAPI(t, tc.api, tc.surface)
+	}
+	home := os.Getenv("HOME")
+	for name, content := range tc.files {
+		writeHomeFile(t, home, name, strings.ReplaceAll(content, "$GATEWAY", srv.URL))
+	}
+	args := make([]string, len(tc.args))
+	for i, a := range tc.args {
+		args[i] = strings.ReplaceAll(a, "$HOME", home)
+	}
+	if tc.inHome {
+		t.Chdir(home)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	if tc.stopped {
+		cancel()
+	}
+	defer cancel()
+
+	clock = func() time.Time { return goldenNow }
+	defer func() { clock = time.Now }()
+	resetCommands(rootCmd)
+
+	stdout, stderr := captureOutput(t, func() error {
+		rootCmd.SetArgs(args)
+		rootCmd.SetContext(ctx)
+		return Execute()
+	})
+
+	var b strings.Builder
+	b.WriteString("$ satgate " + strings.Join(tc.args, " ") + "\n")
+	b.WriteString(stdout.out)
+	if stderr.out != "" {
+		b.WriteString("--- stderr\n" + stderr.out)
+	}
+	if stdout.err != nil {
+		b.WriteString("--- error\n" + stdout.err.Error() + "\n")
+	}
+	for _, name := range tc.show {
+		data, err := os.ReadFile(filepath.Join(home, name))
+		if err != nil {
+			b.WriteString("--- $HOME/" + name + ": missing\n")
+			continue
+		}
+		b.WriteString("--- $HOME/" + name + "\n" + string(data))
+	}
+	return normalize(strings.ReplaceAll(b.String(), home, "$HOME"), srv.URL)
+}
+
+// serveAPI starts a test server for a and points the CLI's environment at
+// it
+func serveAPI(t *testing.T, a api, surface string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(a.handler(t))
+	t.Cleanup(srv.Close)
+	useServer(t, srv.URL, surface)
+	return srv
+}
+
+// serveMock starts a mock.Server seeded with fixture, on the pinned clock
+// and accepting the credentials serveAPI sets
+func serveMock(t *testing.T, fixture, surface string) *httptest.Server {
+	t.Helper()
+	data := []byte(fixture)
+	if strings.HasPrefix(fixture, "@") {
+		var err error
+		if data, err = os.ReadFile(filepath.Join(testdata, fixture[1:])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fx, err := mock.Parse(data)
+	if err != nil {
+		t.Fatalf("parsing mock fixture: %v", err)
+	}
+	fx.AdminToken, fx.BearerToken, fx.SessionToken = "sgk_test", "sg_test", ""
+	m := mock.New(fx)
+	m.Now = func() time.Time { return goldenNow }
+	srv := httptest.NewServer(m)
+	t.Cleanup(srv.Close)
+	useServer(t, srv.URL, surface)
+	return srv
+}
+
+// useServer points the CLI's environment at url, with a fresh HOME and
+// credentials for the surface
+func useServer(t *testing.T, url, surface string) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	for _, k := range []string{"SATGATE_SURFACE", "SATGATE_ADMIN_TOKEN", "SATGATE_BEARER_TOKEN", "SATGATE_TENANT",
+		"SATGATE_SESSION_TOKEN", "SATGATE_FORMAT", "SATGATE_POLICY_FILE", "SATGATE_TEMPLATES_DIR", "SATGATE_RATES", "SATGATE_MACAROON"} {
+		t.Setenv(k, "")
+	}
+	t.Setenv("SATGATE_GATEWAY", url)
+	if surface == "cloud" {
+		t.Setenv("SATGATE_SURFACE", "cloud")
+		t.Setenv("SATGATE_BEARER_TOKEN", "sg_test")
+		t.Setenv("SATGATE_TENANT", "acme")
+	} else {
+		t.Setenv("SATGATE_ADMIN_TOKEN", "sgk_test")
+	}
+}
+
+// writeHomeFile writes content, or the testdata file it names with a
+// leading @, to name under home
+func writeHomeFile(t *testing.T, home, name, content string) {
+	t.Helper()
+	if strings.HasPrefix(content, "@") {
+		data, err := os.ReadFile(filepath.Join(testdata, content[1:]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		content = string(data)
+	}
+	path := filepath.Join(home, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+var (
+	latency    = regexp.MustCompile(`\(\d+(\.\d+)?(ns|µs|ms|s)([,)])`)
+	loopback   = regexp.MustCompile(`127\.0\.0\.1:[1-9]\d*`)
+	requestID  = regexp.MustCompile(`req_[0-9a-f]{24}`)
+	dateHeader = regexp.MustCompile(`Date: .* GMT`)
+)
+
+// normalize replaces the test server's address, request latencies and IDs,
+// response dates and other servers' ports, the only parts of the output
+// that vary between runs
+func normalize(s, url string) string {
+	s = strings.ReplaceAll(s, url, "http://gateway.test")
+	s = strings.ReplaceAll(s, strings.TrimPrefix(url, "http://"), "gateway.test")
+	s = requestID.ReplaceAllString(s, "req_000000000000000000000001")
+	s = dateHeader.ReplaceAllString(s, "Date: Mon, 19 Oct 2026 12:00:00 GMT")
+	s = loopback.ReplaceAllString(s, "127.0.0.1:8090")
+	return latency.ReplaceAllString(s, "(1ms${3}")
+}
+
+type captured struct {
+	out string
+	err error
+}
+
+// captureOutput redirects os.Stdout and os.Stderr while fn runs; commands
+// print to them directly
+func captureOutput(t *testing.T, fn func() error) (captured, captured) {
+	t.Helper()
+	oldOut, oldErr := os.Stdout, os.Stderr
+	outR, outW, _ := os.Pipe()
+	errR, errW, _ := os.Pipe()
+	os.Stdout, os.Stderr = outW, errW
+
+	var outBuf, errBuf bytes.Buffer
+	done := make(chan struct{}, 2)
+	go func() { io.Copy(&outBuf, outR); done <- struct{}{} }()
+	go func() { io.Copy(&errBuf, errR); done <- struct{}{} }()
+
+	err := fn()
+	outW.Close()
+	errW.Close()
+	<-done
+	<-done
+	os.Stdout, os.Stderr = oldOut, oldErr
+	return captured{out: outBuf.String(), err: err}, captured{out: errBuf.String()}
+}
+
+// resetCommands restores every flag to its default and clears the
+// per-run silencing commands set, so cases do not leak into each other
+func resetCommands(c *cobra.Command) {
+	c.SilenceUsage = false
+	c.SilenceErrors = false
+	c.SetContext(nil) // cobra hands the root's context down to unset subcommands
+	reset := func(f *pflag.Flag) {
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			sv.Replace(nil)
+		} else {
+			f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	}
+	c.Flags().VisitAll(reset)
+	c.PersistentFlags().VisitAll(reset)
+	for _, sub := range c.Commands() {
+		resetCommands(sub)
+	}
+}
+
+func (a api) handler(t *testing.T) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Method + " " + r.URL.Path
+		rep, ok := a[key]
+		best := -1
+		for k, v := range a {
+			if base, query, found := strings.Cut(k, "?"); found && base == key && strings.Contains(r.URL.RawQuery, query) && len(query) > best {
+				rep, ok, best = v, true, len(query)
+			}
+		}
+		if !ok {
+			rep = status(404, `{"error":"not found"}`)
+		}
+		body := rep.body
+		if strings.HasPrefix(body, "@") {
+			data, err := os.ReadFile(filepath.Join(testdata, "api", body[1:]))
+			if err != nil {
+				t.Errorf("reading recorded response: %v", err)
+			}
+			body = string(data)
+		}
+		for k, v := range rep.header {
+			w.Header().Set(k, v)
+		}
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", "application/json")
+		}
+		w.WriteHeader(rep.status)
+		io.WriteString(w, body)
+	})
+}
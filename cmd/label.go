@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/SatGate-io/satgate-cli/internal/client"
+	"github.com/SatGate-io/satgate-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var labelOverwrite bool
+
+var labelCmd = &cobra.Command{
+	Use:   "label <token-id> [key=value ...] [key- ...]",
+	Short: "Show, add or remove labels on an existing token",
+	Long: `Show, add or remove labels on an existing token.
+
+With only a token ID the current labels are printed. key=value sets a
+label; key- removes it. Changing the value of an existing label requires
+--overwrite.
+
+On the cloud surface the cost-center and department labels are stored in
+the token's costCenter and department fields, which spend rollups group by.`,
+	Example: `  satgate label tok_abc1
+  satgate label tok_abc1 team=support env=prod
+  satgate label tok_abc1 env=staging --overwrite
+  satgate label tok_abc1 owner-`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := config.Get()
+		c, err := client.New()
+		if err != nil {
+			return err
+		}
+
+		id, err := resolveTokenID(c, args[0])
+		if err != nil {
+			return err
+		}
+
+		data, code, err := c.Get(tokenDetailPath(c, id.ID))
+		if err != nil {
+			return fmt.Errorf("cannot reach gateway at %s: %w", cfg.Gateway, err)
+		}
+		if code == 404 {
+			return fmt.Errorf("token %s not found", id.ID)
+		}
+		if code != 200 {
+			return fmt.Errorf("API returned HTTP %d: %s", code, string(data))
+		}
+		current := parseTokenDetail(data).Labels
+
+		if len(args) == 1 {
+			if flagJSON {
+				if current == nil {
+					current = map[string]string{}
+				}
+				out, _ := json.MarshalIndent(current, "", "  ")
+				fmt.Println(string(out))
+				return nil
+			}
+			if len(current) == 0 {
+				fmt.Fprintln(os.Stderr, "(no labels)")
+			}
+			for _, k := range sortedKeys(current) {
+				fmt.Printf("%s=%s\n", k, current[k])
+			}
+			return nil
+		}
+
+		changes, err := labelChanges(current, args[1:], labelOverwrite)
+		if err != nil {
+			return err
+		}
+		if len(changes) == 0 {
+			fmt.Fprintln(os.Stderr, "No label changes.")
+			return nil
+		}
+
+		printTarget(cfg, id)
+		body := labelPatch(c.Surface(), changes)
+
+		if flagDry {
+			out, _ := json.MarshalIndent(body, "", "  ")
+			fmt.Fprintf(os.Stderr, "[DRY RUN] Would update labels on %s:\n%s\n", id, string(out))
+			return nil
+		}
+
+		data, code, err = c.Patch(tokenDetailPath(c, id.ID), body)
+		if err != nil {
+			return fmt.Errorf("cannot reach gateway at %s: %w", cfg.Gateway, err)
+		}
+		if code != 200 && code != 204 {
+			return fmt.Errorf("API returned HTTP %d: %s", code, string(data))
+		}
+
+		if flagJSON {
+			fmt.Println(string(data))
+			return nil
+		}
+
+		updated := map[string]string{}
+		for k, v := range current {
+			updated[k] = v
+		}
+		for k, v := range changes {
+			if v == nil {
+				delete(updated, k)
+			} else {
+				updated[k] = *v
+			}
+		}
+		fmt.Fprintf(os.Stderr, "✓ Labels on %s: %s\n", id, formatLabels(updated))
+		return nil
+	},
+}
+
+func init() {
+	labelCmd.Flags().BoolVar(&labelOverwrite, "overwrite", false, "allow changing the value of an existing label")
+	rootCmd.AddCommand(labelCmd)
+}
+
+// labelChanges turns key=value and key- arguments into a patch, where a nil
+// value removes the label. Arguments that would not change anything are
+// dropped.
+func labelChanges(current map[string]string, args []string, overwrite bool) (map[string]*string, error) {
+	changes := map[string]*string{}
+	for _, arg := range args {
+		if key, ok := strings.CutSuffix(arg, "-"); ok && !strings.Contains(arg, "=") {
+			if !labelKeyPattern.MatchString(key) {
+				return nil, fmt.Errorf("invalid label key %q", key)
+			}
+			if _, exists := current[key]; exists {
+				changes[key] = nil
+			}
+			continue
+		}
+
+		set, err := parseLabels([]string{arg})
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range set {
+			old, exists := current[k]
+			if exists && old == v {
+				continue
+			}
+			if exists && !overwrite {
+				return nil, fmt.Errorf("label %q is already set to %q; use --overwrite to change it", k, old)
+			}
+			changes[k] = &v
+		}
+	}
+	return changes, nil
+}
+
+// labelPatch builds the PATCH body for the client's surface. Removed
+// labels are sent as null, merge-patch style.
+func labelPatch(surface string, changes map[string]*string) map[string]interface{} {
+	labels := map[string]interface{}{}
+	body := map[string]interface{}{}
+	for k, v := range changes {
+		var value interface{}
+		if v != nil {
+			value = *v
+		}
+		switch {
+		case surface == "cloud" && k == labelCostCenter:
+			body["costCenter"] = value
+		case surface == "cloud" && k == labelDepartment:
+			body["department"] = value
+		default:
+			labels[k] = value
+		}
+	}
+	if len(labels) > 0 {
+		body["labels"] = labels
+	}
+	return body
+}
@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Cloud tokens carry cost center and department as dedicated fields; they
+// appear as these labels so selectors work the same on both surfaces.
+const (
+	labelCostCenter = "cost-center"
+	labelDepartment = "department"
+)
+
+var labelKeyPattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9._/-]{0,62})$`)
+
+// parseLabels parses repeated key=value flags, each of which may also hold
+// a comma-separated list
+func parseLabels(values []string) (map[string]string, error) {
+	labels := map[string]string{}
+	for _, v := range values {
+		for _, pair := range strings.Split(v, ",") {
+			pair = strings.TrimSpace(pair)
+			if pair == "" {
+				continue
+			}
+			key, value, ok := strings.Cut(pair, "=")
+			if !ok {
+				return nil, fmt.Errorf("invalid label %q (use key=value)", pair)
+			}
+			if !labelKeyPattern.MatchString(key) {
+				return nil, fmt.Errorf("invalid label key %q (letters, digits, '.', '_', '-', '/'; at most 63 chars)", key)
+			}
+			labels[key] = value
+		}
+	}
+	return labels, nil
+}
+
+// formatLabels renders labels as sorted key=value pairs
+func formatLabels(labels map[string]string) string {
+	keys := sortedKeys(labels)
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k + "=" + labels[k]
+	}
+	return strings.Join(pairs, ",")
+}
+
+// labelSelector is a comma-separated list of requirements that must all
+// hold, kubectl style: key=value, key!=value, key (exists), !key (absent)
+type labelSelector []labelRequirement
+
+type labelRequirement struct {
+	Key   string
+	Op    string // "=", "!=", "exists" or "!exists"
+	Value string
+}
+
+func parseSelector(s string) (labelSelector, error) {
+	var sel labelSelector
+	for _, term := range strings.Split(s, ",") {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+		r, err := parseRequirement(term)
+		if err != nil {
+			return nil, err
+		}
+		sel = append(sel, r)
+	}
+	if len(sel) == 0 {
+		return nil, fmt.Errorf("empty label selector")
+	}
+	return sel, nil
+}
+
+func parseRequirement(term string) (labelRequirement, error) {
+	var r labelRequirement
+	switch {
+	case strings.Contains(term, "!="):
+		r.Key, r.Value, _ = strings.Cut(term, "!=")
+		r.Op = "!="
+	case strings.Contains(term, "="):
+		r.Key, r.Value, _ = strings.Cut(term, "=")
+		r.Value = strings.TrimPrefix(r.Value, "=") // accept ==
+		r.Op = "="
+	case strings.HasPrefix(term, "!"):
+		r.Key, r.Op = term[1:], "!exists"
+	default:
+		r.Key, r.Op = term, "exists"
+	}
+	r.Key, r.Value = strings.TrimSpace(r.Key), strings.TrimSpace(r.Value)
+	if !labelKeyPattern.MatchString(r.Key) {
+		return r, fmt.Errorf("invalid label selector %q", term)
+	}
+	return r, nil
+}
+
+func (r labelRequirement) matches(labels map[string]string) bool {
+	v, ok := labels[r.Key]
+	switch r.Op {
+	case "=":
+		return ok && v == r.Value
+	case "!=":
+		return !ok || v != r.Value
+	case "!exists":
+		return !ok
+	}
+	return ok
+}
+
+func (s labelSelector) matches(labels map[string]string) bool {
+	for _, r := range s {
+		if !r.matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s labelSelector) String() string {
+	terms := make([]string, len(s))
+	for i, r := range s {
+		switch r.Op {
+		case "exists":
+			terms[i] = r.Key
+		case "!exists":
+			terms[i] = "!" + r.Key
+		default:
+			terms[i] = r.Key + r.Op + r.Value
+		}
+	}
+	return strings.Join(terms, ",")
+}
+
+// selectTokens returns the tokens whose labels match. A nil selector
+// matches everything.
+func (s labelSelector) selectTokens(tokens []tokenInfo) []tokenInfo {
+	if s == nil {
+		return tokens
+	}
+	var out []tokenInfo
+	for _, t := range tokens {
+		if s.matches(t.Labels) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// matchesGroup reports whether a spend row keyed by a cost center or
+// department could hold matching tokens, judging only the requirements on
+// that key. Requirements on other labels are left to the server.
+func (s labelSelector) matchesGroup(labelKey, value string) bool {
+	for _, r := range s {
+		if r.Key == labelKey && !r.matches(map[string]string{labelKey: value}) {
+			return false
+		}
+	}
+	return true
+}
+
+// optionalSelector parses a -l flag value, returning nil when it is unset
+func optionalSelector(s string) (labelSelector, error) {
+	if s == "" {
+		return nil, nil
+	}
+	sel, err := parseSelector(s)
+	if err != nil {
+		return nil, fmt.Errorf("-l: %w", err)
+	}
+	return sel, nil
+}
+
+// splitCloudLabels moves the labels the cloud stores as dedicated fields
+// out of a label set, returning them as request fields
+func splitCloudLabels(labels map[string]string) (map[string]string, map[string]interface{}) {
+	rest := map[string]string{}
+	fields := map[string]interface{}{}
+	for k, v := range labels {
+		switch k {
+		case labelCostCenter:
+			fields["costCenter"] = v
+		case labelDepartment:
+			fields["department"] = v
+		default:
+			rest[k] = v
+		}
+	}
+	return rest, fields
+}
+
+// labelColumn is the labels shown in tables, truncated to stay readable
+func labelColumn(labels map[string]string) string {
+	return truncate(formatLabels(labels), 40)
+}
@@ -3,23 +3,40 @@ package cmd
 import (
 	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
-	"strconv"
 	"strings"
+	"time"
 
+	"github.com/SatGate-io/satgate-cli/internal/audit"
 	"github.com/SatGate-io/satgate-cli/internal/client"
 	"github.com/SatGate-io/satgate-cli/internal/config"
+	"github.com/SatGate-io/satgate-cli/internal/money"
+	"github.com/SatGate-io/satgate-cli/internal/policy"
+	"github.com/SatGate-io/satgate-cli/internal/secrets"
 	"github.com/spf13/cobra"
 )
 
 var (
-	mintAgent    string
-	mintBudget   float64
-	mintCurrency string
-	mintExpiry   string
-	mintRoutes   string
-	mintParent   string
+	mintAgent          string
+	mintBudget         string
+	mintCurrency       string
+	mintExpiry         string
+	mintRoutes         string
+	mintParent         string
+	mintLabels         []string
+	mintTemplate       string
+	mintVars           []string
+	mintSecretSinks    []string
+	mintSecretKey      string
+	mintSecretName     string
+	mintForce          bool
+	mintFromFile       string
+	mintConcurrency    int
+	mintResults        string
+	mintOverridePolicy bool
+	mintOverrideReason string
 )
 
 var mintCmd = &cobra.Command{
@@ -28,25 +45,96 @@ var mintCmd = &cobra.Command{
 	Long: `Mint a new macaroon capability token with budget, expiry, and route restrictions.
 
 Interactive mode (no flags): prompts for all fields.
-Non-interactive: provide --agent, --budget, --expiry flags.`,
+Non-interactive: provide --agent, --budget, --expiry flags.
+With --template: start from a named template (see 'satgate template');
+flags given explicitly override the template's values.
+
+If an org policy file exists (policy_file in config, default
+~/.satgate/policy.yaml), the mint is checked against it before anything is
+sent. Violations block the mint unless --override-policy is given with an
+--override-reason, which is recorded in the local audit log.
+
+The macaroon is printed once unless --output-secret sends it elsewhere
+(repeatable), in which case it is not printed at all:
+
+  file:PATH       the macaroon alone, mode 0600
+  env:PATH        KEY=macaroon in a dotenv file (--secret-key, default SATGATE_MACAROON)
+  k8s:PATH        a Kubernetes Secret manifest (--secret-name, --secret-key)
+  vault:PATH|URL  a KV write using VAULT_ADDR and VAULT_TOKEN (KV v2 if the path has /data/)
+  exec:COMMAND    the macaroon on the command's stdin, with SATGATE_TOKEN_ID set
+
+file: and k8s: refuse to replace an existing file, which may hold another
+token's secret, unless --force is given.
+
+--from-file mints one token per row of a CSV, JSON or YAML file with
+columns agent, budget, currency, expiry, routes, parent, labels and
+output_secret; blank fields fall back to the flags. All rows are checked
+and shown before a single confirmation. Every row needs a secret
+destination, and --output-secret may use {{.Agent}} to give each its own.
+Progress is appended to a results file (default <file>.results.jsonl)
+recording each row's token ID and where its secret went; re-running the
+same command skips rows already minted, matched by agent and parent.
+A row whose secret reached no destination is recorded as
+minted-undelivered, its macaroon is not printed, and it is reported on
+every run until it is revoked and its line deleted.`,
+	Example: `  satgate mint --agent support-bot --budget 50 --expiry 30d
+  satgate mint --agent ci --budget 5 --output-secret env:.env --secret-key OPENAI_PROXY_TOKEN
+  satgate mint --agent cs-bot --output-secret k8s:cs-bot-secret.yaml --output-secret vault:secret/data/satgate/cs-bot
+  satgate mint --template support-bot --agent triage --var team=support
+  satgate mint --from-file agents.csv --expiry 90d --output-secret 'file:secrets/{{.Agent}}.macaroon'
+  satgate mint --template support-bot --agent triage --var team=support --budget 100`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cfg := config.Get()
-		printTarget(cfg)
+		c, err := client.New()
+		if err != nil {
+			return err
+		}
+		if mintFromFile != "" {
+			return runBulkMint(cmd, c)
+		}
+
+		labels, err := parseLabels(mintLabels)
+		if err != nil {
+			return fmt.Errorf("--label: %w", err)
+		}
+		if mintTemplate != "" {
+			if labels, err = applyMintTemplate(cmd, labels); err != nil {
+				return err
+			}
+		}
+
+		sinks, err := parseSecretSinks()
+		if err != nil {
+			return err
+		}
+
+		// Resolve a short --parent prefix up front so the full ID is shown
+		// with the target
+		var parent []resolvedID
+		if mintParent != "" {
+			id, err := resolveTokenID(c, mintParent)
+			if err != nil {
+				return fmt.Errorf("--parent: %w", err)
+			}
+			mintParent = id.ID
+			parent = append(parent, id)
+		}
+		printTarget(cfg, parent...)
+		if mintTemplate != "" {
+			fmt.Fprintf(os.Stderr, "   Template: %s\n", mintTemplate)
+		}
 
 		// Interactive mode if no agent flag provided
-		if mintAgent == "" {
+		if mintAgent == "" && mintTemplate == "" {
 			reader := bufio.NewReader(os.Stdin)
 
 			fmt.Print("  Agent name: ")
 			mintAgent, _ = reader.ReadString('\n')
 			mintAgent = strings.TrimSpace(mintAgent)
 
-			fmt.Print("  Budget (USD, 0 for unlimited): ")
-			budgetStr, _ := reader.ReadString('\n')
-			budgetStr = strings.TrimSpace(budgetStr)
-			if budgetStr != "" && budgetStr != "0" {
-				mintBudget, _ = strconv.ParseFloat(budgetStr, 64)
-			}
+			fmt.Printf("  Budget (%s, 0 for unlimited): ", strings.ToUpper(mintCurrency))
+			mintBudget, _ = reader.ReadString('\n')
+			mintBudget = strings.TrimSpace(mintBudget)
 
 			fmt.Print("  Expiry (e.g. 30d, 24h, or blank for none): ")
 			mintExpiry, _ = reader.ReadString('\n')
@@ -61,72 +149,50 @@ Non-interactive: provide --agent, --budget, --expiry flags.`,
 			return fmt.Errorf("agent name is required")
 		}
 
-		c, err := client.New()
+		budget, err := parseBudget(mintBudget, mintCurrency)
 		if err != nil {
-			return err
+			return fmt.Errorf("--budget: %w", err)
 		}
-
-		// Build request
-		req := map[string]interface{}{
-			"name": mintAgent,
+		if cur, _ := money.Lookup(mintCurrency); cmd.Flags().Changed("currency") && cur != budget.Currency {
+			return fmt.Errorf("--budget %s conflicts with --currency %s", mintBudget, mintCurrency)
 		}
 
-		if c.Surface() == "cloud" {
-			// Cloud uses credits (cents) and DelegateRequest format
-			if mintBudget > 0 {
-				req["budget_limit_credits"] = int64(mintBudget * 100) // dollars → credits
-			}
-			scope := map[string]interface{}{}
-			if mintRoutes != "" && mintRoutes != "*" {
-				routes := strings.Split(mintRoutes, ",")
-				for i := range routes {
-					routes[i] = strings.TrimSpace(routes[i])
-				}
-				scope["routes"] = routes
-			} else {
-				scope["routes"] = []string{"*"}
-			}
-			req["scope"] = scope
-			// Parent ID: default to root if not specified
-			if mintParent != "" {
-				req["parent_id"] = mintParent
-			}
-		} else {
-			// Gateway admin API format
-			if mintBudget > 0 {
-				req["budget"] = mintBudget
-				if mintCurrency != "" {
-					req["currency"] = mintCurrency
-				} else {
-					req["currency"] = "USD"
-				}
-			}
-			if mintRoutes != "" && mintRoutes != "*" {
-				routes := strings.Split(mintRoutes, ",")
-				for i := range routes {
-					routes[i] = strings.TrimSpace(routes[i])
-				}
-				req["routes"] = routes
-			}
+		spec := mintSpec{
+			Agent:  mintAgent,
+			Budget: budget,
+			Expiry: mintExpiry,
+			Routes: splitRoutes(mintRoutes),
+			Parent: mintParent,
+			Labels: labels,
 		}
-		if mintExpiry != "" {
-			req["expiry"] = mintExpiry
+		if err := enforcePolicy(spec); err != nil {
+			return err
+		}
+		req, err := spec.request(c.Surface())
+		if err != nil {
+			return err
 		}
 
 		if flagDry {
 			out, _ := json.MarshalIndent(req, "", "  ")
 			fmt.Printf("[DRY RUN] Would mint token:\n%s\n", string(out))
+			for _, sink := range sinks {
+				fmt.Printf("[DRY RUN] Would write macaroon to %s\n", sink)
+			}
 			return nil
 		}
 
 		// Confirm
 		fmt.Fprintf(os.Stderr, "\n  Minting token for agent %q", mintAgent)
-		if mintBudget > 0 {
-			fmt.Fprintf(os.Stderr, " (budget: $%.2f)", mintBudget)
+		if !budget.IsZero() {
+			fmt.Fprintf(os.Stderr, " (budget: %s)", budget)
 		}
 		if mintExpiry != "" {
 			fmt.Fprintf(os.Stderr, " (expires: %s)", mintExpiry)
 		}
+		if len(labels) > 0 {
+			fmt.Fprintf(os.Stderr, " (labels: %s)", formatLabels(labels))
+		}
 		fmt.Fprintln(os.Stderr)
 
 		if !confirmAction("⚠️  Proceed?") {
@@ -146,22 +212,34 @@ Non-interactive: provide --agent, --budget, --expiry flags.`,
 			return fmt.Errorf("API returned HTTP %d: %s", code, string(data))
 		}
 
-		if flagJSON {
-			fmt.Println(string(data))
-			return nil
-		}
-
 		var resp map[string]interface{}
 		json.Unmarshal(data, &resp)
 
-		fmt.Println("\n✓ Token minted successfully")
-		fmt.Println("─────────────────────────────")
-
 		// Cloud response wraps token in {"token": {...}, "macaroon_token": "..."}
 		tokenData := resp
 		if nested, ok := resp["token"].(map[string]interface{}); ok {
 			tokenData = nested
 		}
+		tokenID, _ := tokenData["id"].(string)
+		secretField, secret := mintSecret(resp)
+
+		delivered := deliverSecret(sinks, secrets.Secret{Value: secret, TokenID: tokenID, Agent: mintAgent})
+		if len(sinks) > 0 && len(delivered) == 0 && secret != "" {
+			// A failed write must not lose a token that cannot be shown again
+			fmt.Fprintln(os.Stderr, "⚠️  No --output-secret destination succeeded; printing the macaroon so it is not lost.")
+		}
+		if len(delivered) > 0 {
+			resp[secretField] = "[written to --output-secret]"
+			data, _ = json.Marshal(resp)
+		}
+
+		if flagJSON {
+			fmt.Println(string(data))
+			return nil
+		}
+
+		fmt.Println("\n✓ Token minted successfully")
+		fmt.Println("─────────────────────────────")
 
 		if id, ok := tokenData["id"]; ok {
 			fmt.Printf("  ID:       %v\n", id)
@@ -170,8 +248,8 @@ Non-interactive: provide --agent, --budget, --expiry flags.`,
 		if status, ok := tokenData["status"]; ok {
 			fmt.Printf("  Status:   %v\n", status)
 		}
-		if mintBudget > 0 {
-			fmt.Printf("  Budget:   $%.2f\n", mintBudget)
+		if !budget.IsZero() {
+			fmt.Printf("  Budget:   %s\n", budget)
 		}
 		if scope, ok := tokenData["scope"].(map[string]interface{}); ok {
 			if routes, ok := scope["routes"].([]interface{}); ok {
@@ -185,17 +263,22 @@ Non-interactive: provide --agent, --budget, --expiry flags.`,
 		if exp, ok := tokenData["expires_at"]; ok {
 			fmt.Printf("  Expires:  %v\n", exp)
 		}
-		if mac, ok := resp["macaroon_token"]; ok && mac != "" {
-			fmt.Printf("  Macaroon: %v\n", mac)
-		} else if mac, ok := resp["macaroon"]; ok && mac != "" {
-			fmt.Printf("  Macaroon: %v\n", mac)
-		} else if token, ok := resp["token"]; ok {
-			if _, isMap := token.(map[string]interface{}); !isMap {
-				fmt.Printf("  Token:    %v\n", token)
+
+		if len(delivered) > 0 {
+			for _, sink := range delivered {
+				fmt.Printf("  Secret:   → %s\n", sink)
 			}
+			return nil
+		}
+		switch secretField {
+		case "":
+		case "token":
+			fmt.Printf("  Token:    %v\n", secret)
+		default:
+			fmt.Printf("  Macaroon: %v\n", secret)
 		}
-
 		fmt.Println("\n⚠️  Save the token/macaroon now — it won't be shown again.")
+		fmt.Println("   Use --output-secret to write it to a file, Vault or a Kubernetes Secret instead.")
 
 		return nil
 	},
@@ -203,10 +286,236 @@ Non-interactive: provide --agent, --budget, --expiry flags.`,
 
 func init() {
 	mintCmd.Flags().StringVar(&mintAgent, "agent", "", "agent name")
-	mintCmd.Flags().Float64Var(&mintBudget, "budget", 0, "budget ceiling in currency units")
-	mintCmd.Flags().StringVar(&mintCurrency, "currency", "USD", "budget currency")
+	mintCmd.Flags().StringVar(&mintBudget, "budget", "", "budget ceiling, e.g. 50, 0.29 or 1500sats (blank or 0 = unlimited)")
+	mintCmd.Flags().StringVar(&mintCurrency, "currency", "USD", "budget currency: USD, EUR, SAT or MSAT (cloud: USD only)")
 	mintCmd.Flags().StringVar(&mintExpiry, "expiry", "", "token expiry (e.g. 30d, 24h)")
 	mintCmd.Flags().StringVar(&mintRoutes, "routes", "", "allowed routes (comma-separated)")
-	mintCmd.Flags().StringVar(&mintParent, "parent", "", "parent token ID (cloud surface, for delegation)")
+	mintCmd.Flags().StringVar(&mintParent, "parent", "", "parent token ID or unique prefix (cloud surface, for delegation)")
+	mintCmd.Flags().StringArrayVar(&mintLabels, "label", nil, "label as key=value (repeatable), e.g. team=support; cost-center and department map to the cloud fields")
+	mintCmd.Flags().StringVar(&mintTemplate, "template", "", "start from a named template (see 'satgate template list')")
+	mintCmd.Flags().StringArrayVar(&mintVars, "var", nil, "template variable as key=value (repeatable), e.g. team=support for {{.Team}}")
+	mintCmd.Flags().StringArrayVar(&mintSecretSinks, "output-secret", nil, "write the macaroon to a destination instead of printing it (repeatable): "+secrets.Kinds)
+	mintCmd.Flags().StringVar(&mintSecretKey, "secret-key", "", "env var, Secret data key or Vault field for --output-secret")
+	mintCmd.Flags().StringVar(&mintSecretName, "secret-name", "", "Kubernetes Secret name for --output-secret k8s: (default satgate-<agent>)")
+	mintCmd.Flags().BoolVar(&mintForce, "force", false, "let --output-secret file: and k8s: replace existing files")
+	mintCmd.Flags().StringVar(&mintFromFile, "from-file", "", "mint one token per row of a CSV, JSON or YAML file")
+	mintCmd.Flags().IntVar(&mintConcurrency, "concurrency", 4, "parallel mints with --from-file")
+	mintCmd.Flags().StringVar(&mintResults, "results", "", "results file for --from-file (default <file>.results.jsonl)")
+	mintCmd.Flags().BoolVar(&mintOverridePolicy, "override-policy", false, "mint despite policy violations (requires --override-reason)")
+	mintCmd.Flags().StringVar(&mintOverrideReason, "override-reason", "", "justification recorded in the audit log when overriding policy")
 	rootCmd.AddCommand(mintCmd)
 }
+
+// mintSpec is a mint request before it is shaped for a surface
+type mintSpec struct {
+	Agent  string
+	Budget money.Amount // zero = unlimited
+	Expiry string
+	Routes []string // empty = all routes
+	Parent string
+	Labels map[string]string
+}
+
+// request builds the surface-specific mint body. Cloud budgets are USD
+// credits (cents); the gateway takes the amount in its own currency.
+func (s mintSpec) request(surface string) (map[string]interface{}, error) {
+	req := map[string]interface{}{
+		"name": s.Agent,
+	}
+
+	if surface == "cloud" {
+		// Cloud uses credits (cents) and DelegateRequest format
+		if !s.Budget.IsZero() {
+			credits, err := s.Budget.Credits()
+			if err != nil {
+				return nil, err
+			}
+			req["budget_limit_credits"] = credits
+		}
+		scope := map[string]interface{}{}
+		if len(s.Routes) > 0 {
+			scope["routes"] = s.Routes
+		} else {
+			scope["routes"] = []string{"*"}
+		}
+		req["scope"] = scope
+		// Parent ID: default to root if not specified
+		if s.Parent != "" {
+			req["parent_id"] = s.Parent
+		}
+		if len(s.Labels) > 0 {
+			labels, fields := splitCloudLabels(s.Labels)
+			for k, v := range fields {
+				req[k] = v
+			}
+			if len(labels) > 0 {
+				req["labels"] = labels
+			}
+		}
+	} else {
+		// Gateway admin API format
+		if !s.Budget.IsZero() {
+			req["budget"] = json.Number(s.Budget.Decimal())
+			req["currency"] = s.Budget.Currency.Code
+		}
+		if len(s.Routes) > 0 {
+			req["routes"] = s.Routes
+		}
+		if len(s.Labels) > 0 {
+			req["labels"] = s.Labels
+		}
+	}
+	if s.Expiry != "" {
+		req["expiry"] = s.Expiry
+	}
+	return req, nil
+}
+
+// parseBudget reads a --budget value in the given default currency. Blank
+// and zero mean unlimited.
+func parseBudget(s, currency string) (money.Amount, error) {
+	cur, err := money.Lookup(currency)
+	if err != nil {
+		return money.Amount{}, err
+	}
+	if strings.TrimSpace(s) == "" {
+		return money.New(0, cur), nil
+	}
+	a, err := money.Parse(s, cur)
+	if err != nil {
+		return money.Amount{}, err
+	}
+	if a.Units < 0 {
+		return money.Amount{}, fmt.Errorf("budget cannot be negative")
+	}
+	return a, nil
+}
+
+// splitRoutes parses a comma-separated --routes value. Blank and "*" both
+// mean all routes and return nil.
+func splitRoutes(s string) []string {
+	if s == "" || s == "*" {
+		return nil
+	}
+	routes := strings.Split(s, ",")
+	for i := range routes {
+		routes[i] = strings.TrimSpace(routes[i])
+	}
+	return routes
+}
+
+// enforcePolicy checks a mint against the org policy file. Violations are
+// fatal unless --override-policy and --override-reason are both given, in
+// which case the override is attached to the audit log entry.
+func enforcePolicy(spec mintSpec) error {
+	cfg := config.Get()
+	pol, err := policy.Load(cfg.PolicyFile)
+	if err != nil {
+		return err
+	}
+	if pol == nil {
+		return nil
+	}
+
+	var expiry time.Duration
+	if spec.Expiry != "" {
+		if expiry, err = parseDuration(spec.Expiry); err != nil {
+			return fmt.Errorf("--expiry: %w", err)
+		}
+	}
+	violations := pol.Evaluate(policy.MintRequest{
+		Agent:  spec.Agent,
+		Budget: spec.Budget,
+		Expiry: expiry,
+		Routes: spec.Routes,
+		Parent: spec.Parent,
+		User:   audit.CurrentUser(),
+	})
+	if len(violations) == 0 {
+		return nil
+	}
+
+	fmt.Fprintf(os.Stderr, "\n⛔ Policy violations (%s):\n", pol.Path())
+	messages := make([]string, len(violations))
+	for i, v := range violations {
+		fmt.Fprintf(os.Stderr, "   • [%s] %s\n", v.Rule, v.Message)
+		messages[i] = v.Rule + ": " + v.Message
+	}
+
+	if !mintOverridePolicy {
+		return fmt.Errorf("mint blocked by policy: %d violation(s). Use --override-policy --override-reason \"...\" to proceed anyway", len(violations))
+	}
+	if strings.TrimSpace(mintOverrideReason) == "" {
+		return fmt.Errorf("--override-policy requires --override-reason")
+	}
+	if cfg.Audit.Disabled {
+		fmt.Fprintln(os.Stderr, "⚠️  Audit log is disabled; this override will not be recorded locally.")
+	}
+	fmt.Fprintf(os.Stderr, "⚠️  Overriding policy: %s\n", mintOverrideReason)
+	audit.SetPolicyOverride(audit.PolicyOverride{Reason: mintOverrideReason, Violations: messages})
+	return nil
+}
+
+func parseSecretSinks() ([]secrets.Sink, error) {
+	var sinks []secrets.Sink
+	for _, spec := range mintSecretSinks {
+		sink, err := secrets.Parse(spec, secrets.Options{Key: mintSecretKey, Name: mintSecretName, Force: mintForce})
+		if err != nil {
+			return nil, fmt.Errorf("--output-secret: %w", err)
+		}
+		if err := checkSink(sink); err != nil {
+			return nil, fmt.Errorf("--output-secret: %w", err)
+		}
+		sinks = append(sinks, sink)
+	}
+	return sinks, nil
+}
+
+// checkSink reports a destination that would refuse the secret, before a
+// token is minted that could then not be delivered. A replayed mint only
+// has the cassette's redacted macaroon, so no destination takes it.
+func checkSink(sink secrets.Sink) error {
+	if flagReplay != "" {
+		return fmt.Errorf("%s: cannot deliver a replayed macaroon; the cassette only holds it redacted", sink)
+	}
+	err := sink.Check()
+	if errors.Is(err, secrets.ErrExists) {
+		return fmt.Errorf("%w (use --force to replace it)", err)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", sink, err)
+	}
+	return nil
+}
+
+// mintSecret finds the credential in a mint response and the field it came
+// from: macaroon_token (cloud), macaroon, or a string token (gateway)
+func mintSecret(resp map[string]interface{}) (string, string) {
+	for _, field := range []string{"macaroon_token", "macaroon", "token"} {
+		if v, ok := resp[field].(string); ok && v != "" {
+			return field, v
+		}
+	}
+	return "", ""
+}
+
+// deliverSecret writes the secret to every sink and returns those that
+// succeeded. If none did, the caller decides what becomes of the secret.
+func deliverSecret(sinks []secrets.Sink, s secrets.Secret) []secrets.Sink {
+	if len(sinks) == 0 {
+		return nil
+	}
+	if s.Value == "" {
+		fmt.Fprintln(os.Stderr, "⚠️  Mint response contained no macaroon; nothing written to --output-secret.")
+		return nil
+	}
+	var delivered []secrets.Sink
+	for _, sink := range sinks {
+		if err := sink.Deliver(s); err != nil {
+			fmt.Fprintf(os.Stderr, "✗ Writing macaroon to %s: %v\n", sink, err)
+			continue
+		}
+		delivered = append(delivered, sink)
+	}
+	return delivered
+}
@@ -0,0 +1,570 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/SatGate-io/satgate-cli/internal/client"
+	"github.com/SatGate-io/satgate-cli/internal/config"
+	"github.com/SatGate-io/satgate-cli/internal/secrets"
+	"github.com/SatGate-io/satgate-cli/internal/templates"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// bulkColumns are the fields a --from-file row may set. Blank fields fall
+// back to the corresponding mint flag.
+var bulkColumns = []string{"agent", "budget", "currency", "expiry", "routes", "parent", "labels", "output_secret"}
+
+// bulkRow is one validated row of a --from-file batch
+type bulkRow struct {
+	Row   int // 1-based, header excluded
+	Spec  mintSpec
+	Sinks []secrets.Sink
+	Done  *bulkResult // set when a previous run already minted it
+}
+
+// bulkResult is one line of the results file. It records where the
+// secret went, never the secret itself.
+type bulkResult struct {
+	Row     int      `json:"row"`
+	Agent   string   `json:"agent"`
+	Parent  string   `json:"parent,omitempty"`
+	Status  string   `json:"status"` // minted | minted-undelivered | failed
+	TokenID string   `json:"token_id,omitempty"`
+	Secret  []string `json:"secret,omitempty"`
+	Error   string   `json:"error,omitempty"`
+	Time    string   `json:"time"`
+}
+
+// Result statuses. A minted-undelivered token exists but its secret reached
+// none of its destinations, so it is not minted again on resume.
+const (
+	bulkMinted      = "minted"
+	bulkUndelivered = "minted-undelivered"
+	bulkFailed      = "failed"
+)
+
+// bulkKey identifies a row across runs. The same agent may be minted under
+// different parents, and rows may be reordered between runs.
+func bulkKey(agent, parent string) string {
+	return agent + "\x00" + parent
+}
+
+// runBulkMint mints every row of --from-file. Rows are validated and shown
+// together before a single confirmation, minted with bounded concurrency,
+// and appended to the results file as they finish, so re-running the same
+// command after an interruption or failure skips rows already minted.
+func runBulkMint(cmd *cobra.Command, c *client.Client) error {
+	cfg := config.Get()
+
+	if mintTemplate != "" {
+		return fmt.Errorf("--template cannot be combined with --from-file")
+	}
+	if mintConcurrency < 1 {
+		return fmt.Errorf("--concurrency must be at least 1")
+	}
+
+	records, err := readBulkFile(mintFromFile)
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return fmt.Errorf("%s has no rows", mintFromFile)
+	}
+	rows, err := bulkRows(c, records)
+	if err != nil {
+		return err
+	}
+
+	resultsPath := mintResults
+	if resultsPath == "" {
+		resultsPath = strings.TrimSuffix(mintFromFile, filepath.Ext(mintFromFile)) + ".results.jsonl"
+	}
+	previous, err := readBulkResults(resultsPath)
+	if err != nil {
+		return err
+	}
+	pending := 0
+	var undelivered []bulkResult
+	for i := range rows {
+		r, ok := previous[bulkKey(rows[i].Spec.Agent, rows[i].Spec.Parent)]
+		switch {
+		case ok && r.Status == bulkUndelivered:
+			undelivered = append(undelivered, r)
+			fallthrough
+		case ok && r.Status == bulkMinted:
+			rows[i].Done = &r
+		default:
+			pending++
+		}
+	}
+
+	printTarget(cfg)
+	printBulkPlan(rows)
+	if pending < len(rows) {
+		fmt.Fprintf(os.Stderr, "\n  %d of %d rows already minted (%s); they will be skipped.\n", len(rows)-pending, len(rows), resultsPath)
+		if len(undelivered) > 0 {
+			fmt.Fprintf(os.Stderr, "  %d of them never had their secret delivered.\n", len(undelivered))
+		}
+	}
+	if pending == 0 {
+		fmt.Fprintln(os.Stderr, "  Nothing to mint.")
+		return undeliveredError(undelivered, resultsPath)
+	}
+
+	for _, r := range rows {
+		if r.Done != nil {
+			continue
+		}
+		if err := enforcePolicy(r.Spec); err != nil {
+			return fmt.Errorf("row %d (%s): %w", r.Row, r.Spec.Agent, err)
+		}
+		for _, sink := range r.Sinks {
+			if err := checkSink(sink); err != nil {
+				return fmt.Errorf("row %d (%s): %w", r.Row, r.Spec.Agent, err)
+			}
+		}
+	}
+
+	if flagDry {
+		fmt.Fprintf(os.Stderr, "\n[DRY RUN] Would mint %d token(s) with concurrency %d; results to %s\n", pending, mintConcurrency, resultsPath)
+		return nil
+	}
+	if !confirmAction(fmt.Sprintf("\n⚠️  Mint %d token(s)?", pending)) {
+		fmt.Fprintln(os.Stderr, "Cancelled.")
+		return nil
+	}
+
+	out, err := os.OpenFile(resultsPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("opening results file: %w", err)
+	}
+	defer out.Close()
+
+	// Ctrl-C stops new rows from starting; rows in flight finish and are
+	// recorded so the next run resumes cleanly
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var (
+		mu      sync.Mutex // guards results file and progress output
+		done    int
+		failed  []bulkResult
+		minted  int
+		lost    int // minted-undelivered in this run
+		queue   = make(chan bulkRow)
+		workers sync.WaitGroup
+	)
+	record := func(res bulkResult) {
+		mu.Lock()
+		defer mu.Unlock()
+		line, _ := json.Marshal(res)
+		if _, err := out.Write(append(line, '\n')); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  results file: %v\n", err)
+		}
+		done++
+		switch res.Status {
+		case bulkMinted:
+			minted++
+			fmt.Fprintf(os.Stderr, "[%d/%d] ✓ %s  %s → %s\n", done, pending, res.Agent, res.TokenID, strings.Join(res.Secret, ", "))
+		case bulkUndelivered:
+			lost++
+			undelivered = append(undelivered, res)
+			fmt.Fprintf(os.Stderr, "[%d/%d] ⚠ %s  %s minted, secret not delivered\n", done, pending, res.Agent, res.TokenID)
+		default:
+			failed = append(failed, res)
+			fmt.Fprintf(os.Stderr, "[%d/%d] ✗ %s  %s\n", done, pending, res.Agent, res.Error)
+		}
+	}
+
+	for i := 0; i < mintConcurrency; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for r := range queue {
+				record(mintBulkRow(c, r))
+			}
+		}()
+	}
+dispatch:
+	for _, r := range rows {
+		if r.Done != nil {
+			continue
+		}
+		select {
+		case queue <- r:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(queue)
+	workers.Wait()
+
+	interrupted := ctx.Err() != nil
+	fmt.Fprintf(os.Stderr, "\n%d minted, %d undelivered, %d failed", minted, lost, len(failed))
+	if skipped := pending - done; skipped > 0 {
+		fmt.Fprintf(os.Stderr, ", %d not started", skipped)
+	}
+	fmt.Fprintf(os.Stderr, ". Results: %s\n", resultsPath)
+
+	if flagJSON {
+		all, _ := readBulkResults(resultsPath)
+		list := make([]bulkResult, 0, len(all))
+		for _, r := range all {
+			list = append(list, r)
+		}
+		sort.Slice(list, func(i, j int) bool { return list[i].Row < list[j].Row })
+		data, _ := json.MarshalIndent(list, "", "  ")
+		fmt.Println(string(data))
+	}
+
+	if interrupted || len(failed) > 0 {
+		return fmt.Errorf("batch incomplete; re-run the same command to retry the remaining rows")
+	}
+	return undeliveredError(undelivered, resultsPath)
+}
+
+// undeliveredError reports tokens whose secret never reached a destination.
+// Re-running cannot fix them: the token exists and its secret is gone.
+func undeliveredError(undelivered []bulkResult, resultsPath string) error {
+	if len(undelivered) == 0 {
+		return nil
+	}
+	sort.Slice(undelivered, func(i, j int) bool { return undelivered[i].Row < undelivered[j].Row })
+	lines := make([]string, len(undelivered))
+	for i, r := range undelivered {
+		lines[i] = fmt.Sprintf("row %d (%s): %s", r.Row, r.Agent, r.TokenID)
+	}
+	return fmt.Errorf("%d token(s) minted without delivering their secret; revoke them and delete their lines from %s to mint them again:\n  %s",
+		len(undelivered), resultsPath, strings.Join(lines, "\n  "))
+}
+
+// mintBulkRow mints one row and delivers its secret. A secret that reaches
+// no destination is dropped rather than printed among other rows' output;
+// the row is recorded as undelivered so the token can be revoked.
+func mintBulkRow(c *client.Client, r bulkRow) bulkResult {
+	res := bulkResult{Row: r.Row, Agent: r.Spec.Agent, Parent: r.Spec.Parent, Status: bulkFailed, Time: clock().UTC().Format(time.RFC3339)}
+
+	path := "/admin/tokens/mint"
+	if c.Surface() == "cloud" {
+		path = "/cloud/delegation-v2/delegate"
+	}
+	req, err := r.Spec.request(c.Surface())
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	data, code, err := c.Post(path, req)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	if code != 200 && code != 201 {
+		res.Error = fmt.Sprintf("API returned HTTP %d: %s", code, strings.TrimSpace(string(data)))
+		return res
+	}
+
+	var resp map[string]interface{}
+	json.Unmarshal(data, &resp)
+	tokenData := resp
+	if nested, ok := resp["token"].(map[string]interface{}); ok {
+		tokenData = nested
+	}
+	res.Status = bulkMinted
+	res.TokenID, _ = tokenData["id"].(string)
+	_, secret := mintSecret(resp)
+
+	delivered := deliverSecret(r.Sinks, secrets.Secret{Value: secret, TokenID: res.TokenID, Agent: r.Spec.Agent})
+	for _, s := range delivered {
+		res.Secret = append(res.Secret, s.String())
+	}
+	if len(delivered) == 0 {
+		res.Status = bulkUndelivered
+		res.Error = "secret not delivered to any destination; revoke the token and mint it again"
+	}
+	return res
+}
+
+// readBulkFile returns the rows of a CSV, JSON or YAML file as column →
+// value maps. JSON and YAML rows may give routes as a list and labels as
+// a map.
+func readBulkFile(path string) ([]map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		r := csv.NewReader(strings.NewReader(string(data)))
+		r.TrimLeadingSpace = true
+		r.Comment = '#'
+		lines, err := r.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", path, err)
+		}
+		if len(lines) == 0 {
+			return nil, nil
+		}
+		header := make([]string, len(lines[0]))
+		for i, h := range lines[0] {
+			header[i] = bulkColumn(h)
+		}
+		var rows []map[string]string
+		for _, line := range lines[1:] {
+			row := map[string]string{}
+			for i, v := range line {
+				if i < len(header) {
+					row[header[i]] = strings.TrimSpace(v)
+				}
+			}
+			rows = append(rows, row)
+		}
+		return rows, nil
+
+	case ".json", ".yaml", ".yml":
+		var raw []map[string]interface{}
+		if err := yaml.Unmarshal(data, &raw); err != nil { // YAML is a superset of JSON
+			return nil, fmt.Errorf("parsing %s: expected a list of objects: %w", path, err)
+		}
+		rows := make([]map[string]string, len(raw))
+		for i, obj := range raw {
+			row := map[string]string{}
+			for k, v := range obj {
+				row[bulkColumn(k)] = bulkValue(v)
+			}
+			rows[i] = row
+		}
+		return rows, nil
+	}
+	return nil, fmt.Errorf("%s: unsupported file type (use .csv, .json, .yaml)", path)
+}
+
+// bulkColumn normalizes a column name: Output-Secret → output_secret,
+// name → agent
+func bulkColumn(name string) string {
+	name = strings.ToLower(strings.TrimSpace(strings.ReplaceAll(name, "-", "_")))
+	if name == "name" {
+		return "agent"
+	}
+	return name
+}
+
+// bulkValue flattens a JSON/YAML value to the CSV representation
+func bulkValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case []interface{}:
+		parts := make([]string, len(v))
+		for i, p := range v {
+			parts[i] = fmt.Sprint(p)
+		}
+		return strings.Join(parts, ",")
+	case map[string]interface{}:
+		labels := map[string]string{}
+		for k, val := range v {
+			labels[k] = fmt.Sprint(val)
+		}
+		return formatLabels(labels)
+	}
+	return fmt.Sprint(v)
+}
+
+// bulkRows validates every record up front, so a typo in row 40 is found
+// before row 1 is minted
+func bulkRows(c *client.Client, records []map[string]string) ([]bulkRow, error) {
+	known := map[string]bool{}
+	for _, col := range bulkColumns {
+		known[col] = true
+	}
+
+	var tokens []tokenInfo
+	for _, rec := range records {
+		if rec["parent"] != "" {
+			data, code, err := c.Get(tokensPath(c))
+			if err == nil && code == 200 {
+				tokens = parseTokens(data)
+			}
+			break
+		}
+	}
+
+	flagLabels, err := parseLabels(mintLabels)
+	if err != nil {
+		return nil, fmt.Errorf("--label: %w", err)
+	}
+
+	var rows []bulkRow
+	var problems []string
+	keys := map[string]int{}
+	destinations := map[string]int{}
+	for i, rec := range records {
+		n := i + 1
+		fail := func(format string, args ...interface{}) {
+			problems = append(problems, fmt.Sprintf("row %d: %s", n, fmt.Sprintf(format, args...)))
+		}
+		for col := range rec {
+			if !known[col] {
+				fail("unknown column %q (expected %s)", col, strings.Join(bulkColumns, ", "))
+			}
+		}
+
+		spec := mintSpec{
+			Agent:  rec["agent"],
+			Expiry: firstNonEmpty(rec["expiry"], mintExpiry),
+			Routes: splitRoutes(firstNonEmpty(rec["routes"], mintRoutes)),
+			Parent: firstNonEmpty(rec["parent"], mintParent),
+		}
+		if spec.Agent == "" {
+			fail("agent is required")
+			continue
+		}
+		if spec.Budget, err = parseBudget(firstNonEmpty(rec["budget"], mintBudget), firstNonEmpty(rec["currency"], mintCurrency)); err != nil {
+			fail("budget: %v", err)
+		} else if _, err := spec.request(c.Surface()); err != nil {
+			fail("%v", err)
+		}
+		if spec.Expiry != "" {
+			if _, err := parseDuration(spec.Expiry); err != nil {
+				fail("expiry: %v", err)
+			}
+		}
+		if spec.Parent != "" {
+			id, err := matchTokenPrefix(tokens, spec.Parent)
+			if err != nil {
+				fail("parent: %v", err)
+			}
+			spec.Parent = id.ID
+		}
+		if prev, dup := keys[bulkKey(spec.Agent, spec.Parent)]; dup {
+			fail("agent %q under the same parent already used in row %d", spec.Agent, prev)
+		}
+		keys[bulkKey(spec.Agent, spec.Parent)] = n
+
+		rowLabels, err := parseLabels([]string{rec["labels"]})
+		if err != nil {
+			fail("labels: %v", err)
+		}
+		spec.Labels = map[string]string{}
+		for k, v := range flagLabels {
+			spec.Labels[k] = v
+		}
+		for k, v := range rowLabels {
+			spec.Labels[k] = v
+		}
+
+		// Sink specs and the secret key may reference {{.Agent}} and labels,
+		// e.g. file:secrets/{{.Agent}}.macaroon
+		vars := templates.Vars(spec.Agent, spec.Labels)
+		specs := mintSecretSinks
+		if rec["output_secret"] != "" {
+			specs = []string{rec["output_secret"]}
+		}
+		if len(specs) == 0 {
+			fail("no secret destination; use --output-secret (e.g. 'file:secrets/{{.Agent}}.macaroon') or an output_secret column")
+		}
+		key, err := templates.Expand(mintSecretKey, vars)
+		if err != nil {
+			fail("--secret-key: %v", err)
+		}
+		name, err := templates.Expand(mintSecretName, vars)
+		if err != nil {
+			fail("--secret-name: %v", err)
+		}
+		var sinks []secrets.Sink
+		for _, s := range specs {
+			expanded, err := templates.Expand(s, vars)
+			if err != nil {
+				fail("output secret %q: %v", s, err)
+				continue
+			}
+			sink, err := secrets.Parse(expanded, secrets.Options{Key: key, Name: name, Force: mintForce})
+			if err != nil {
+				fail("%v", err)
+				continue
+			}
+			// Two rows writing the same destination would overwrite each
+			// other's secret
+			if !strings.HasPrefix(sink.String(), "exec:") {
+				if prev, dup := destinations[sink.String()]; dup {
+					fail("secret destination %s already used in row %d", sink, prev)
+				}
+				destinations[sink.String()] = n
+			}
+			sinks = append(sinks, sink)
+		}
+
+		rows = append(rows, bulkRow{Row: n, Spec: spec, Sinks: sinks})
+	}
+
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("%s has %d problem(s):\n  %s", mintFromFile, len(problems), strings.Join(problems, "\n  "))
+	}
+	return rows, nil
+}
+
+// readBulkResults loads a previous run's results, keyed by agent and
+// parent. The last line for a key wins, so a retried row replaces its
+// earlier failure.
+func readBulkResults(path string) (map[string]bulkResult, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return map[string]bulkResult{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading results file: %w", err)
+	}
+	defer f.Close()
+
+	results := map[string]bulkResult{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var r bulkResult
+		if json.Unmarshal(scanner.Bytes(), &r) == nil && r.Agent != "" {
+			results[bulkKey(r.Agent, r.Parent)] = r
+		}
+	}
+	return results, scanner.Err()
+}
+
+// printBulkPlan shows every row as it will be minted
+func printBulkPlan(rows []bulkRow) {
+	w := tabwriter.NewWriter(os.Stderr, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ROW\tAGENT\tBUDGET\tEXPIRY\tROUTES\tPARENT\tLABELS\tSECRET\tSTATUS")
+	fmt.Fprintln(w, "───\t─────\t──────\t──────\t──────\t──────\t──────\t──────\t──────")
+	for _, r := range rows {
+		budget := "unlimited"
+		if !r.Spec.Budget.IsZero() {
+			budget = r.Spec.Budget.String()
+		}
+		routes := "*"
+		if len(r.Spec.Routes) > 0 {
+			routes = strings.Join(r.Spec.Routes, ",")
+		}
+		sinks := make([]string, len(r.Sinks))
+		for i, s := range r.Sinks {
+			sinks[i] = s.String()
+		}
+		status := "pending"
+		if r.Done != nil {
+			status = r.Done.Status + " " + r.Done.TokenID
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", r.Row, r.Spec.Agent, budget,
+			firstNonEmpty(r.Spec.Expiry, "never"), truncate(routes, 30), firstNonEmpty(truncate(r.Spec.Parent, 12), "—"),
+			firstNonEmpty(labelColumn(r.Spec.Labels), "—"), strings.Join(sinks, ", "), status)
+	}
+	w.Flush()
+}
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/SatGate-io/satgate-cli/internal/mock"
+	"github.com/spf13/cobra"
+)
+
+var (
+	mockListen      string
+	mockSeed        string
+	mockAdminToken  string
+	mockBearerToken string
+	mockQuiet       bool
+)
+
+var mockCmd = &cobra.Command{
+	Use:   "mock",
+	Short: "Run an in-memory SatGate gateway and cloud API for tests and scripts",
+	Long: `The mock serves the admin (/admin/...) and delegation (/cloud/delegation-v2/...)
+endpoints the CLI uses from in-memory state, seeded by a fixture file.
+Minting, labels and revocation change that state; spend, threats,
+payments and audit events come from the fixture. Both surfaces share the
+same tokens.
+
+Any other path is treated as a protected route: requests with a minted
+macaroon are checked against the token's scope and budget and charged
+the route's cost, so satgate try works against it. Charge routes answer
+402 without issuing a Lightning invoice.
+
+State is lost when the mock exits.`,
+}
+
+var mockServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Start the mock server",
+	Example: `  satgate mock serve --seed example
+  satgate mock seed > fixture.yaml && satgate mock serve --seed fixture.yaml`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fx, err := loadMockFixture(mockSeed)
+		if err != nil {
+			return err
+		}
+		fx.AdminToken = firstNonEmpty(mockAdminToken, fx.AdminToken, "sgk_mock")
+		fx.BearerToken = firstNonEmpty(mockBearerToken, fx.BearerToken, "sg_mock")
+
+		ln, err := net.Listen("tcp", mockListen)
+		if err != nil {
+			return fmt.Errorf("cannot listen on %s: %w", mockListen, err)
+		}
+		server := mock.New(fx)
+		var handler http.Handler = server
+		if !mockQuiet {
+			handler = logRequests(server)
+		}
+		srv := &http.Server{Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+
+		url := "http://" + ln.Addr().String()
+		fmt.Printf("Mock SatGate listening on %s (%d tokens, %d routes)\n\n", url, len(fx.Tokens), len(fx.Routes))
+		fmt.Println("  # Gateway surface")
+		fmt.Printf("  export SATGATE_GATEWAY=%s SATGATE_ADMIN_TOKEN=%s\n", url, fx.AdminToken)
+		fmt.Println("  # Cloud surface")
+		fmt.Printf("  export SATGATE_SURFACE=cloud SATGATE_GATEWAY=%s SATGATE_BEARER_TOKEN=%s SATGATE_TENANT=mock\n\n", url, fx.BearerToken)
+		fmt.Println("Press Ctrl+C to stop.")
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		go func() {
+			<-ctx.Done()
+			shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			srv.Shutdown(shutdown)
+		}()
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	},
+}
+
+var mockSeedCmd = &cobra.Command{
+	Use:   "seed",
+	Short: "Print an example fixture file",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		os.Stdout.Write(mock.Example)
+	},
+}
+
+func init() {
+	f := mockServeCmd.Flags()
+	f.StringVar(&mockListen, "listen", "127.0.0.1:8090", "address to listen on (port 0 picks a free port)")
+	f.StringVar(&mockSeed, "seed", "", "fixture file (YAML or JSON), or 'example' for the built-in one (default: empty state)")
+	f.StringVar(&mockAdminToken, "admin-token", "", "X-Admin-Token to accept (default: the fixture's, else sgk_mock)")
+	f.StringVar(&mockBearerToken, "bearer-token", "", "cloud bearer token to accept (default: the fixture's, else sg_mock)")
+	f.BoolVar(&mockQuiet, "quiet", false, "do not log requests")
+
+	mockCmd.AddCommand(mockServeCmd)
+	mockCmd.AddCommand(mockSeedCmd)
+	rootCmd.AddCommand(mockCmd)
+}
+
+func loadMockFixture(seed string) (mock.Fixture, error) {
+	switch seed {
+	case "":
+		return mock.Fixture{}, nil
+	case "example":
+		return mock.Parse(mock.Example)
+	}
+	return mock.Load(seed)
+}
+
+// statusRecorder captures the status code for the request log
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.status = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+// logRequests logs one line per request to stderr
+func logRequests(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		h.ServeHTTP(rec, r)
+		fmt.Fprintf(os.Stderr, "%s %s %s → %d (%s)\n", start.Format("15:04:05"), r.Method, r.URL.RequestURI(), rec.status, time.Since(start).Round(time.Microsecond))
+	})
+}
@@ -12,13 +12,19 @@ import (
 var modeCmd = &cobra.Command{
 	Use:   "mode",
 	Short: "Show current policy mode (read-only in Phase 1)",
-	Long:  `Display the current policy mode for each route. Mode switching comes in a future release.`,
+	Long: `Display the current policy mode for each route. Mode switching comes in a future release.
+
+Routes are configured on the gateway, so mode is available on the gateway
+surface only.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cfg := config.Get()
 		c, err := client.New()
 		if err != nil {
 			return err
 		}
+		if c.Surface() == "cloud" {
+			return fmt.Errorf("mode is only available on the gateway surface (routes and their policy modes are configured on the gateway)")
+		}
 
 		data, code, err := c.Get("/admin/routes")
 		if err != nil {
@@ -33,42 +39,14 @@ var modeCmd = &cobra.Command{
 			return nil
 		}
 
-		var routes []struct {
-			Path   string `json:"path"`
-			Policy string `json:"policy"`
-			Name   string `json:"name"`
-		}
-		json.Unmarshal(data, &routes)
-
-		// Also try wrapped response
-		if len(routes) == 0 {
-			var wrapped struct {
-				Routes []struct {
-					Path   string `json:"path"`
-					Policy string `json:"policy"`
-					Name   string `json:"name"`
-				} `json:"routes"`
-			}
-			json.Unmarshal(data, &wrapped)
-			routes = wrapped.Routes
-		}
+		routes := parseRoutes(data)
 
 		fmt.Println("Policy Modes")
 		fmt.Println("─────────────────────────────")
 
-		icons := map[string]string{
-			"observe":    "👁  Observe",
-			"chargeback": "👁  Observe",
-			"control":    "🎛  Control",
-			"fiat402":    "🎛  Control",
-			"charge":     "💲 Charge",
-			"l402":       "💲 Charge",
-			"public":     "🔓 Public",
-		}
-
 		for _, r := range routes {
 			mode := r.Policy
-			display, ok := icons[mode]
+			display, ok := modeIcons[mode]
 			if !ok {
 				display = mode
 			}
@@ -76,6 +54,9 @@ var modeCmd = &cobra.Command{
 			if r.Name != "" {
 				name = r.Name + " (" + r.Path + ")"
 			}
+			if price, ok := r.priceMsat(); ok && canonicalMode(mode) == "charge" {
+				display += " (" + sats(float64(price)/1000) + ")"
+			}
 			fmt.Printf("  %-40s %s\n", name, display)
 		}
 
@@ -90,3 +71,64 @@ var modeCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(modeCmd)
 }
+
+// routeInfo is a single route and its policy mode
+type routeInfo struct {
+	Path      string `json:"path"`
+	Policy    string `json:"policy"`
+	Name      string `json:"name"`
+	PriceSats int64  `json:"price_sats,omitempty"` // charge routes
+	PriceMsat int64  `json:"price_msat,omitempty"`
+}
+
+// priceMsat returns a charge route's configured price, if the gateway
+// reports one
+func (r routeInfo) priceMsat() (int64, bool) {
+	switch {
+	case r.PriceMsat > 0:
+		return r.PriceMsat, true
+	case r.PriceSats > 0:
+		return r.PriceSats * 1000, true
+	}
+	return 0, false
+}
+
+// modeIcons maps policy names (including legacy aliases) to display labels
+var modeIcons = map[string]string{
+	"observe":    "👁  Observe",
+	"chargeback": "👁  Observe",
+	"control":    "🎛  Control",
+	"fiat402":    "🎛  Control",
+	"charge":     "💲 Charge",
+	"l402":       "💲 Charge",
+	"public":     "🔓 Public",
+}
+
+// canonicalMode folds legacy policy aliases into the four modes
+func canonicalMode(policy string) string {
+	switch policy {
+	case "observe", "chargeback":
+		return "observe"
+	case "control", "fiat402":
+		return "control"
+	case "charge", "l402":
+		return "charge"
+	}
+	return policy
+}
+
+// parseRoutes accepts a raw route array or a {"routes": [...]} wrapper
+func parseRoutes(data []byte) []routeInfo {
+	var routes []routeInfo
+	json.Unmarshal(data, &routes)
+
+	// Also try wrapped response
+	if len(routes) == 0 {
+		var wrapped struct {
+			Routes []routeInfo `json:"routes"`
+		}
+		json.Unmarshal(data, &wrapped)
+		routes = wrapped.Routes
+	}
+	return routes
+}
@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/SatGate-io/satgate-cli/internal/client"
 	"github.com/SatGate-io/satgate-cli/internal/config"
@@ -13,32 +12,23 @@ var pingCmd = &cobra.Command{
 	Use:   "ping",
 	Short: "Quick liveness check (exit code 0 = healthy, 1 = unreachable)",
 	RunE: func(cmd *cobra.Command, args []string) error {
+		// The ✗ line is the whole error report; main prints it and exits 1
+		cmd.SilenceErrors = true
 		cfg := config.Get()
 		c, err := client.New()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "✗ %s unreachable: %v\n", cfg.Gateway, err)
-			os.Exit(1)
-			return nil
+			return fmt.Errorf("✗ %s unreachable: %v", cfg.Gateway, err)
 		}
 
-		path := "/admin/ping"
-		if c.Surface() == "cloud" {
-			path = "/healthz"
-		}
-
-		_, code, err := c.Get(path)
+		_, code, err := c.Get(healthPath(c))
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "✗ %s unreachable: %v\n", cfg.Gateway, err)
-			os.Exit(1)
-			return nil
+			return fmt.Errorf("✗ %s unreachable: %v", cfg.Gateway, err)
 		}
 
-		if code == 200 {
-			fmt.Printf("✓ %s is healthy\n", cfg.Gateway)
-		} else {
-			fmt.Fprintf(os.Stderr, "✗ %s returned HTTP %d\n", cfg.Gateway, code)
-			os.Exit(1)
+		if code != 200 {
+			return fmt.Errorf("✗ %s returned HTTP %d", cfg.Gateway, code)
 		}
+		fmt.Printf("✓ %s is healthy\n", cfg.Gateway)
 		return nil
 	},
 }
@@ -0,0 +1,349 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/SatGate-io/satgate-cli/internal/client"
+	"github.com/SatGate-io/satgate-cli/internal/config"
+	"github.com/SatGate-io/satgate-cli/internal/l402"
+	"github.com/SatGate-io/satgate-cli/internal/money"
+	"github.com/spf13/cobra"
+)
+
+var (
+	probeMethod  string
+	probeHeaders []string
+	probeRoute   string
+	probeTimeout time.Duration
+)
+
+var probeCmd = &cobra.Command{
+	Use:   "probe <url>",
+	Short: "Check an L402 charge route end to end without paying",
+	Long: `Send an unauthenticated request to a charge-mode route and check the
+L402 challenge it returns: the WWW-Authenticate header is parsed, the
+BOLT11 invoice decoded (amount, expiry, description, payment hash) and the
+macaroon's caveats listed. Nothing is paid.
+
+When the admin API is reachable the route is looked up in the gateway's
+route config (as shown by satgate mode) to confirm it is in charge mode and
+that the invoice amount matches its price. A path is resolved against the
+configured gateway. The command exits non-zero when any check fails.`,
+	Example: `  satgate probe https://api.example.com/api/premium/search
+  satgate probe /api/premium/search
+  satgate probe /api/premium/search -X POST -H "Content-Type: application/json" --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		target := args[0]
+		if strings.HasPrefix(target, "/") {
+			target = strings.TrimRight(config.Get().Gateway, "/") + target
+		}
+		u, err := url.Parse(target)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("invalid URL %q", args[0])
+		}
+		headers := http.Header{}
+		for _, h := range probeHeaders {
+			k, v, ok := strings.Cut(h, ":")
+			if !ok {
+				return fmt.Errorf("invalid --header %q (use Name: value)", h)
+			}
+			headers.Add(strings.TrimSpace(k), strings.TrimSpace(v))
+		}
+		result := runProbe(strings.ToUpper(probeMethod), u, headers, clock())
+		if flagJSON {
+			out, _ := json.MarshalIndent(result, "", "  ")
+			fmt.Println(string(out))
+		} else {
+			result.print()
+		}
+		if n := result.failed(); n > 0 {
+			return fmt.Errorf("probe failed: %d of %d checks failed", n, len(result.Checks))
+		}
+		return nil
+	},
+}
+
+func init() {
+	probeCmd.Flags().StringVarP(&probeMethod, "method", "X", "GET", "HTTP method")
+	probeCmd.Flags().StringArrayVarP(&probeHeaders, "header", "H", nil, "extra request header, e.g. \"Accept: application/json\" (repeatable)")
+	probeCmd.Flags().StringVar(&probeRoute, "route", "", "route pattern to compare with (default: best match for the URL path)")
+	probeCmd.Flags().DurationVar(&probeTimeout, "timeout", 10*time.Second, "request timeout")
+	rootCmd.AddCommand(probeCmd)
+}
+
+// probeResult is everything the probe learned, also the --json output
+type probeResult struct {
+	Method   string         `json:"method"`
+	URL      string         `json:"url"`
+	Status   int            `json:"status"`
+	Latency  string         `json:"latency,omitempty"`
+	Header   []string       `json:"www_authenticate,omitempty"`
+	Invoice  *l402.Invoice  `json:"invoice,omitempty"`
+	Macaroon *l402.Macaroon `json:"macaroon,omitempty"`
+	Route    *routeInfo     `json:"route,omitempty"`
+	Checks   []probeCheck   `json:"checks"`
+}
+
+// probeCheck is one check, with report compliance's pass, fail, skipped
+// and info statuses
+type probeCheck struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+}
+
+func (r *probeResult) check(id, status, format string, args ...interface{}) {
+	r.Checks = append(r.Checks, probeCheck{ID: id, Status: status, Detail: fmt.Sprintf(format, args...)})
+}
+
+func (r *probeResult) failed() int {
+	n := 0
+	for _, c := range r.Checks {
+		if c.Status == "fail" {
+			n++
+		}
+	}
+	return n
+}
+
+func runProbe(method string, u *url.URL, headers http.Header, now time.Time) probeResult {
+	r := probeResult{Method: method, URL: u.String()}
+
+	req, err := http.NewRequest(method, u.String(), nil)
+	if err != nil {
+		r.check("challenge", "fail", "%v", err)
+		return r
+	}
+	req.Header = headers
+	req.Header.Set("User-Agent", "satgate-cli/"+version+" (probe)")
+	httpClient := &http.Client{
+		Timeout: probeTimeout,
+		// A redirect away from the route is a finding, not something to follow
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+	start := time.Now()
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		r.check("challenge", "fail", "request failed: %v", err)
+		return r
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+	resp.Body.Close()
+	r.Status = resp.StatusCode
+	r.Latency = time.Since(start).Round(time.Millisecond).String()
+	r.Header = resp.Header.Values("WWW-Authenticate")
+
+	var challenge l402.Challenge
+	switch {
+	case resp.StatusCode == http.StatusPaymentRequired:
+		challenge, err = l402.ParseChallenge(r.Header)
+		if err != nil {
+			r.check("challenge", "fail", "HTTP 402 but %v", err)
+		} else {
+			r.check("challenge", "pass", "HTTP 402 with an %s challenge", challenge.Scheme)
+		}
+	case resp.StatusCode < 300:
+		r.check("challenge", "fail", "HTTP %d: the route served the request without payment", resp.StatusCode)
+	case resp.StatusCode < 400:
+		r.check("challenge", "fail", "HTTP %d redirect to %s", resp.StatusCode, resp.Header.Get("Location"))
+	default:
+		r.check("challenge", "fail", "HTTP %d instead of 402 Payment Required", resp.StatusCode)
+	}
+
+	if challenge.Invoice != "" {
+		probeInvoice(&r, challenge.Invoice, now)
+	}
+	if challenge.Macaroon != "" {
+		probeMacaroon(&r, challenge.Macaroon)
+	}
+	probeRouteConfig(&r, u.Path)
+	return r
+}
+
+func probeInvoice(r *probeResult, s string, now time.Time) {
+	inv, err := l402.DecodeInvoice(s)
+	if err != nil {
+		r.check("invoice", "fail", "%v", err)
+		return
+	}
+	r.Invoice = &inv
+	switch {
+	case !inv.HasAmount:
+		r.check("invoice", "fail", "invoice has no amount; the payer would choose the price")
+	case !inv.ExpiresAt().After(now):
+		r.check("invoice", "fail", "invoice expired %s", relativeTime(inv.ExpiresAt(), now))
+	default:
+		r.check("invoice", "pass", "%s on %s, expires %s", sats(inv.AmountSats()), inv.Network, relativeTime(inv.ExpiresAt(), now))
+	}
+}
+
+func probeMacaroon(r *probeResult, s string) {
+	mac, err := l402.DecodeMacaroon(s)
+	if err != nil {
+		r.check("macaroon", "fail", "%v", err)
+		return
+	}
+	r.Macaroon = &mac
+	switch {
+	case mac.PaymentHash == "":
+		r.check("macaroon", "info", "v%d macaroon with %d caveats; identifier is not in the L402 layout, payment hash not compared", mac.Version, len(mac.Caveats))
+	case r.Invoice != nil && mac.PaymentHash != r.Invoice.PaymentHash:
+		r.check("macaroon", "fail", "macaroon payment hash %s does not match the invoice's %s", truncate(mac.PaymentHash, 16), truncate(r.Invoice.PaymentHash, 16))
+	default:
+		r.check("macaroon", "pass", "v%d macaroon with %d caveats, bound to the invoice's payment hash", mac.Version, len(mac.Caveats))
+	}
+}
+
+// probeRouteConfig compares the challenge with the route as configured on
+// the gateway. It needs the admin API, so it is skipped when that is not
+// configured or reachable.
+func probeRouteConfig(r *probeResult, path string) {
+	c, err := client.New()
+	if err != nil {
+		r.check("route-config", "skipped", "admin API not configured: %v", err)
+		return
+	}
+	if c.Surface() == "cloud" {
+		r.check("route-config", "skipped", "route config is not available on the cloud surface")
+		return
+	}
+	routes, err := fetchRoutes(c)
+	if err != nil {
+		r.check("route-config", "skipped", "%v", err)
+		return
+	}
+	route, ok := findRoute(routes, firstNonEmpty(probeRoute, path))
+	if !ok {
+		r.check("route-config", "fail", "no configured route matches %s", firstNonEmpty(probeRoute, path))
+		return
+	}
+	r.Route = &route
+	if canonicalMode(route.Policy) != "charge" {
+		r.check("route-config", "fail", "%s is in %s mode, not charge", route.Path, canonicalMode(route.Policy))
+		return
+	}
+	price, priced := route.priceMsat()
+	switch {
+	case r.Invoice == nil:
+		r.check("route-config", "info", "%s is in charge mode; no invoice to compare", route.Path)
+	case !priced:
+		r.check("route-config", "info", "%s is in charge mode but its config shows no price to compare with %s", route.Path, sats(r.Invoice.AmountSats()))
+	case price != r.Invoice.AmountMsat:
+		r.check("route-config", "fail", "invoice asks %s but %s is priced at %s", sats(r.Invoice.AmountSats()), route.Path, sats(float64(price)/1000))
+	default:
+		r.check("route-config", "pass", "%s is in charge mode and priced at %s", route.Path, sats(float64(price)/1000))
+	}
+}
+
+// findRoute returns the route matching path, an exact match first and
+// then the longest prefix pattern
+func findRoute(routes []routeInfo, path string) (routeInfo, bool) {
+	var best routeInfo
+	found := false
+	for _, rt := range routes {
+		if rt.Path == path {
+			return rt, true
+		}
+		if matchRoute(rt.Path, path) && (!found || len(rt.Path) > len(best.Path)) {
+			best, found = rt, true
+		}
+	}
+	return best, found
+}
+
+func (r probeResult) print() {
+	fmt.Printf("L402 Probe — %s %s\n", r.Method, r.URL)
+	fmt.Println("─────────────────────────────")
+	if r.Status > 0 {
+		fmt.Printf("  Response:     HTTP %d %s (%s)\n", r.Status, http.StatusText(r.Status), r.Latency)
+	}
+
+	if inv := r.Invoice; inv != nil {
+		fmt.Println("\nInvoice")
+		amount := "none"
+		if inv.HasAmount {
+			amount = sats(inv.AmountSats())
+			if inv.AmountMsat%1000 != 0 {
+				amount = money.Format(float64(inv.AmountMsat), "MSAT")
+			}
+		}
+		fmt.Printf("  Amount:       %s\n", amount)
+		fmt.Printf("  Network:      %s\n", inv.Network)
+		if inv.Description != "" {
+			fmt.Printf("  Description:  %s\n", inv.Description)
+		} else if inv.DescriptionHash != "" {
+			fmt.Printf("  Description:  (hash %s)\n", inv.DescriptionHash)
+		}
+		fmt.Printf("  Created:      %s\n", inv.Timestamp.Format(time.RFC3339))
+		fmt.Printf("  Expires:      %s (%s)\n", inv.ExpiresAt().Format(time.RFC3339), relativeTime(inv.ExpiresAt(), clock()))
+		fmt.Printf("  Payment hash: %s\n", inv.PaymentHash)
+		if inv.Payee != "" {
+			fmt.Printf("  Payee:        %s\n", inv.Payee)
+		}
+	}
+
+	if mac := r.Macaroon; mac != nil {
+		fmt.Println("\nMacaroon")
+		if mac.Location != "" {
+			fmt.Printf("  Location:     %s\n", mac.Location)
+		}
+		if mac.TokenID != "" {
+			fmt.Printf("  Token ID:     %s\n", mac.TokenID)
+		} else {
+			fmt.Printf("  Identifier:   %s\n", truncate(mac.Identifier, 64))
+		}
+		if len(mac.Caveats) == 0 {
+			fmt.Println("  Caveats:      none")
+		}
+		for i, c := range mac.Caveats {
+			label := ""
+			if i == 0 {
+				label = "Caveats:"
+			}
+			cond := c.Condition
+			if c.ThirdParty {
+				cond += " (third party, " + firstNonEmpty(c.Location, "no location") + ")"
+			}
+			fmt.Printf("  %-13s %s\n", label, cond)
+		}
+	}
+
+	if rt := r.Route; rt != nil {
+		fmt.Println("\nRoute config")
+		price := "not shown"
+		if p, ok := rt.priceMsat(); ok {
+			price = sats(float64(p) / 1000)
+		}
+		name := rt.Path
+		if rt.Name != "" {
+			name = rt.Name + " (" + rt.Path + ")"
+		}
+		fmt.Printf("  Route:        %s\n", name)
+		fmt.Printf("  Mode:         %s\n", firstNonEmpty(modeIcons[rt.Policy], rt.Policy))
+		fmt.Printf("  Price:        %s\n", price)
+	}
+
+	icons := map[string]string{
+		"pass":    "✓ pass",
+		"fail":    "✗ FAIL",
+		"skipped": "– skipped",
+		"info":    "ℹ info",
+	}
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CHECK\tSTATUS\tDETAIL")
+	fmt.Fprintln(w, "─────\t──────\t──────")
+	for _, c := range r.Checks {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", c.ID, icons[c.Status], c.Detail)
+	}
+	w.Flush()
+}
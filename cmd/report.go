@@ -3,22 +3,61 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
+	"sort"
+	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/SatGate-io/satgate-cli/internal/client"
 	"github.com/SatGate-io/satgate-cli/internal/config"
 	"github.com/spf13/cobra"
 )
 
+var reportSelector string
+
 var reportCmd = &cobra.Command{
 	Use:   "report",
 	Short: "Generate reports (threats, spend, compliance)",
+	Long: `Generate reports (threats, spend, compliance).
+
+-l restricts every report to tokens whose labels match the selector, e.g.
+-l team=support,env=prod. The selector is sent to the server and also
+applied locally where the report's data allows.`,
 }
 
+var (
+	threatsSince           string
+	threatsUntil           string
+	threatsAgent           string
+	threatsRoute           string
+	threatsCategory        string
+	threatsGroupBy         string
+	threatsRevokeOffenders bool
+	threatsRiskThreshold   int
+)
+
 var reportThreatsCmd = &cobra.Command{
 	Use:   "threats",
 	Short: "Show blocked requests, anomalies, and threat summary",
+	Long: `Show blocked requests, anomalies, and a per-agent risk score.
+
+Filters are sent to the gateway and also applied to the returned events;
+the totals and categories then count the filtered events, and events with
+an unreadable time are left out of --since/--until windows. --since and
+--until accept a duration ago (24h, 7d) or a date (YYYY-MM-DD).
+
+Risk is scored per token, so two tokens sharing an agent name are listed
+separately.
+
+The risk score sums a weight per blocked event (forged or invalid tokens
+weigh most, budget overruns least), capped at 100. --revoke-offenders
+proposes revoking every agent at or above --risk-threshold, one
+confirmation per agent.`,
+	Example: `  satgate report threats --since 24h
+  satgate report threats --since 7d --category route_denied --group-by route
+  satgate report threats --since 24h --revoke-offenders --risk-threshold 40`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cfg := config.Get()
 		c, err := client.New()
@@ -26,7 +65,28 @@ var reportThreatsCmd = &cobra.Command{
 			return err
 		}
 
-		data, code, err := c.Get("/admin/reports/threats")
+		now := clock()
+		filter := threatFilter{Agent: threatsAgent, Route: threatsRoute, Category: threatsCategory}
+		if filter.Selector, err = optionalSelector(reportSelector); err != nil {
+			return err
+		}
+		if threatsSince != "" {
+			if filter.Since, err = parseTimeFlag(threatsSince, now); err != nil {
+				return fmt.Errorf("--since: %w", err)
+			}
+		}
+		if threatsUntil != "" {
+			if filter.Until, err = parseTimeFlag(threatsUntil, now); err != nil {
+				return fmt.Errorf("--until: %w", err)
+			}
+		}
+		switch threatsGroupBy {
+		case "", "agent", "route", "ip":
+		default:
+			return fmt.Errorf("invalid --group-by %q (use agent, route or ip)", threatsGroupBy)
+		}
+
+		data, code, err := c.Get(threatsPath(c) + filter.query())
 		if err != nil {
 			return fmt.Errorf("cannot reach gateway at %s: %w", cfg.Gateway, err)
 		}
@@ -34,63 +94,481 @@ var reportThreatsCmd = &cobra.Command{
 			return fmt.Errorf("API returned HTTP %d: %s", code, string(data))
 		}
 
-		if flagJSON {
-			fmt.Println(string(data))
+		var resp threatReport
+		if err := json.Unmarshal(data, &resp); err != nil {
+			// Unknown shape: show it rather than guess
+			var raw interface{}
+			if err := json.Unmarshal(data, &raw); err != nil {
+				return fmt.Errorf("gateway returned invalid JSON: %w", err)
+			}
+			out, _ := json.MarshalIndent(raw, "", "  ")
+			fmt.Println(string(out))
 			return nil
 		}
-
-		var resp struct {
-			TotalBlocked int `json:"total_blocked"`
-			Categories   []struct {
-				Name  string `json:"name"`
-				Count int    `json:"count"`
-			} `json:"categories"`
-			RecentThreats []struct {
-				Time   string `json:"time"`
-				Type   string `json:"type"`
-				Agent  string `json:"agent"`
-				Route  string `json:"route"`
-				Action string `json:"action"`
-			} `json:"recent_threats"`
-		}
-		if err := json.Unmarshal(data, &resp); err == nil && resp.TotalBlocked > 0 {
-			fmt.Println("Threat Report")
-			fmt.Println("─────────────────────────────")
-			fmt.Printf("  Total Blocked: %d\n\n", resp.TotalBlocked)
-
-			if len(resp.Categories) > 0 {
-				w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-				fmt.Fprintln(w, "CATEGORY\tCOUNT")
-				fmt.Fprintln(w, "────────\t─────")
-				for _, cat := range resp.Categories {
-					fmt.Fprintf(w, "%s\t%d\n", cat.Name, cat.Count)
-				}
-				w.Flush()
-				fmt.Println()
+		events := filter.apply(resp.RecentThreats)
+		if filter.Selector != nil {
+			if events, err = selectThreatEvents(c, events, filter.Selector); err != nil {
+				return err
 			}
+		}
+		// The server's totals cover everything it returned; once events
+		// are filtered here they must describe the filtered events
+		if !filter.empty() {
+			resp.TotalBlocked, resp.Categories = len(events), threatCategories(events)
+		}
+		risks := agentRisks(events)
 
-			if len(resp.RecentThreats) > 0 {
-				fmt.Println("Recent Threats")
-				w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-				fmt.Fprintln(w, "TIME\tTYPE\tAGENT\tROUTE\tACTION")
-				for _, t := range resp.RecentThreats {
-					fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", t.Time, t.Type, t.Agent, t.Route, t.Action)
-				}
-				w.Flush()
-			}
-			return nil
+		if flagJSON {
+			out, _ := json.MarshalIndent(map[string]interface{}{
+				"total_blocked": resp.TotalBlocked,
+				"categories":    resp.Categories,
+				"events":        events,
+				"risks":         risks,
+			}, "", "  ")
+			fmt.Println(string(out))
+		} else {
+			printThreatReport(resp, events, risks, filter)
 		}
 
-		// Fallback
-		var raw interface{}
-		json.Unmarshal(data, &raw)
-		out, _ := json.MarshalIndent(raw, "", "  ")
-		fmt.Println(string(out))
+		if threatsRevokeOffenders {
+			return revokeOffenders(c, risks, threatsRiskThreshold)
+		}
 		return nil
 	},
 }
 
 func init() {
+	reportThreatsCmd.Flags().StringVar(&threatsSince, "since", "", "only threats after this time (e.g. 24h, 7d, 2026-10-01)")
+	reportThreatsCmd.Flags().StringVar(&threatsUntil, "until", "", "only threats before this time")
+	reportThreatsCmd.Flags().StringVar(&threatsAgent, "agent", "", "filter by agent name")
+	reportThreatsCmd.Flags().StringVar(&threatsRoute, "route", "", "filter by route")
+	reportThreatsCmd.Flags().StringVar(&threatsCategory, "category", "", "filter by threat category")
+	reportThreatsCmd.Flags().StringVar(&threatsGroupBy, "group-by", "", "group events by agent, route or ip")
+	reportThreatsCmd.Flags().BoolVar(&threatsRevokeOffenders, "revoke-offenders", false, "propose revoking agents at or above --risk-threshold")
+	reportThreatsCmd.Flags().IntVar(&threatsRiskThreshold, "risk-threshold", 50, "risk score (0-100) at which an agent counts as an offender")
+	reportCmd.PersistentFlags().StringVarP(&reportSelector, "selector", "l", "", "only tokens with matching labels, e.g. team=support,env=prod")
 	reportCmd.AddCommand(reportThreatsCmd)
 	rootCmd.AddCommand(reportCmd)
 }
+
+// threatReport is the threats report response
+type threatReport struct {
+	TotalBlocked  int              `json:"total_blocked"`
+	Categories    []threatCategory `json:"categories"`
+	RecentThreats []threatEvent    `json:"recent_threats"`
+}
+
+// threatCategory is the number of blocked requests of one type
+type threatCategory struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// threatCategories counts events by type, most frequent first
+func threatCategories(events []threatEvent) []threatCategory {
+	counts := map[string]int{}
+	for _, e := range events {
+		counts[e.Type]++
+	}
+	cats := make([]threatCategory, 0, len(counts))
+	for _, name := range sortedKeys(counts) {
+		cats = append(cats, threatCategory{Name: name, Count: counts[name]})
+	}
+	sort.SliceStable(cats, func(i, j int) bool { return cats[i].Count > cats[j].Count })
+	return cats
+}
+
+// threatEvent is a single blocked request
+type threatEvent struct {
+	Time    string `json:"time"`
+	Type    string `json:"type"`
+	Agent   string `json:"agent"`
+	TokenID string `json:"token_id"`
+	Route   string `json:"route"`
+	IP      string `json:"ip"`
+	Action  string `json:"action"`
+}
+
+// threatsPath returns the threat report endpoint for the client's surface
+func threatsPath(c *client.Client) string {
+	if c.Surface() == "cloud" {
+		return "/cloud/reports/threats"
+	}
+	return "/admin/reports/threats"
+}
+
+type threatFilter struct {
+	Since, Until           time.Time
+	Agent, Route, Category string
+	Selector               labelSelector
+}
+
+func (f threatFilter) query() string {
+	q := url.Values{}
+	if !f.Since.IsZero() {
+		q.Set("since", f.Since.UTC().Format(time.RFC3339))
+	}
+	if !f.Until.IsZero() {
+		q.Set("until", f.Until.UTC().Format(time.RFC3339))
+	}
+	if f.Agent != "" {
+		q.Set("agent", f.Agent)
+	}
+	if f.Route != "" {
+		q.Set("route", f.Route)
+	}
+	if f.Category != "" {
+		q.Set("category", f.Category)
+	}
+	if f.Selector != nil {
+		q.Set("selector", f.Selector.String())
+	}
+	if len(q) == 0 {
+		return ""
+	}
+	return "?" + q.Encode()
+}
+
+// empty reports whether no filter is set
+func (f threatFilter) empty() bool {
+	return f.Since.IsZero() && f.Until.IsZero() && f.Agent == "" && f.Route == "" && f.Category == "" && f.Selector == nil
+}
+
+// apply filters events client-side, for gateways that ignore the query.
+// With a time bound, events whose time cannot be parsed are dropped.
+func (f threatFilter) apply(events []threatEvent) []threatEvent {
+	out := []threatEvent{}
+	for _, e := range events {
+		if f.Agent != "" && e.Agent != f.Agent {
+			continue
+		}
+		if f.Route != "" && e.Route != f.Route {
+			continue
+		}
+		if f.Category != "" && e.Type != f.Category {
+			continue
+		}
+		if !f.Since.IsZero() || !f.Until.IsZero() {
+			t, ok := parseTimestamp(e.Time)
+			if !ok {
+				continue
+			}
+			if !f.Since.IsZero() && t.Before(f.Since) {
+				continue
+			}
+			if !f.Until.IsZero() && !t.Before(f.Until) {
+				continue
+			}
+		}
+		out = append(out, e)
+	}
+	return out
+}
+
+func (f threatFilter) String() string {
+	var parts []string
+	if !f.Since.IsZero() {
+		parts = append(parts, "since "+f.Since.Format("2006-01-02 15:04"))
+	}
+	if !f.Until.IsZero() {
+		parts = append(parts, "until "+f.Until.Format("2006-01-02 15:04"))
+	}
+	for _, kv := range [][2]string{{"agent", f.Agent}, {"route", f.Route}, {"category", f.Category}, {"labels", f.Selector.String()}} {
+		if kv[1] != "" {
+			parts = append(parts, kv[0]+"="+kv[1])
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// selectThreatEvents keeps events from tokens matching sel, matching by
+// token ID or, for events without one, by agent name
+func selectThreatEvents(c *client.Client, events []threatEvent, sel labelSelector) ([]threatEvent, error) {
+	data, code, err := c.Get(tokensPath(c))
+	if err != nil {
+		return nil, fmt.Errorf("cannot reach gateway at %s: %w", config.Get().Gateway, err)
+	}
+	if code != 200 {
+		return nil, fmt.Errorf("API returned HTTP %d: %s", code, string(data))
+	}
+	ids, names := map[string]bool{}, map[string]bool{}
+	for _, t := range sel.selectTokens(parseTokens(data)) {
+		ids[t.ID] = true
+		names[t.Name] = true
+	}
+
+	out := []threatEvent{}
+	for _, e := range events {
+		if ids[e.TokenID] || (e.TokenID == "" && names[e.Agent]) {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+// threatWeights is how much one blocked event of a category adds to an
+// agent's risk score. Unknown categories weigh 2.
+var threatWeights = map[string]int{
+	"invalid_token":     10,
+	"forged_macaroon":   10,
+	"caveat_violation":  5,
+	"route_denied":      3,
+	"rate_limited":      2,
+	"expired_token":     2,
+	"budget_exceeded":   1,
+	"insufficient_fund": 1,
+}
+
+// agentRisk summarizes one agent's blocked requests
+type agentRisk struct {
+	Agent   string         `json:"agent"`
+	TokenID string         `json:"token_id,omitempty"`
+	Blocked int            `json:"blocked"`
+	Score   int            `json:"score"`
+	ByType  map[string]int `json:"by_type"`
+}
+
+// agentRisks scores each token in events, highest risk first. Events
+// without a token ID are scored by agent name.
+func agentRisks(events []threatEvent) []agentRisk {
+	byAgent := map[string]*agentRisk{}
+	for _, e := range events {
+		if e.Agent == "" && e.TokenID == "" {
+			continue
+		}
+		key := "id:" + e.TokenID
+		if e.TokenID == "" {
+			key = "name:" + e.Agent
+		}
+		r, ok := byAgent[key]
+		if !ok {
+			r = &agentRisk{Agent: firstNonEmpty(e.Agent, e.TokenID), TokenID: e.TokenID, ByType: map[string]int{}}
+			byAgent[key] = r
+		}
+		r.Blocked++
+		r.ByType[e.Type]++
+		w, ok := threatWeights[e.Type]
+		if !ok {
+			w = 2
+		}
+		r.Score += w
+	}
+
+	risks := make([]agentRisk, 0, len(byAgent))
+	for _, r := range byAgent {
+		if r.Score > 100 {
+			r.Score = 100
+		}
+		risks = append(risks, *r)
+	}
+	sort.Slice(risks, func(i, j int) bool {
+		if risks[i].Score != risks[j].Score {
+			return risks[i].Score > risks[j].Score
+		}
+		if risks[i].Agent != risks[j].Agent {
+			return risks[i].Agent < risks[j].Agent
+		}
+		return risks[i].TokenID < risks[j].TokenID
+	})
+	return risks
+}
+
+func printThreatReport(resp threatReport, events []threatEvent, risks []agentRisk, filter threatFilter) {
+	fmt.Println("Threat Report")
+	fmt.Println("─────────────────────────────")
+	if f := filter.String(); f != "" {
+		fmt.Printf("  Filter:        %s\n", f)
+	}
+	fmt.Printf("  Total Blocked: %d\n\n", resp.TotalBlocked)
+
+	if resp.TotalBlocked == 0 && len(events) == 0 {
+		fmt.Println("  No blocked requests in this period.")
+		return
+	}
+
+	if len(resp.Categories) > 0 {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "CATEGORY\tCOUNT")
+		fmt.Fprintln(w, "────────\t─────")
+		for _, cat := range resp.Categories {
+			fmt.Fprintf(w, "%s\t%d\n", cat.Name, cat.Count)
+		}
+		w.Flush()
+		fmt.Println()
+	}
+
+	if threatsGroupBy != "" {
+		printThreatGroups(events, threatsGroupBy)
+		fmt.Println()
+	}
+
+	if len(risks) > 0 {
+		fmt.Println("Agent Risk")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "AGENT\tTOKEN\tBLOCKED\tRISK\tTOP CATEGORY")
+		for _, r := range risks {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", r.Agent, firstNonEmpty(truncate(r.TokenID, 16), "—"), r.Blocked, riskLabel(r.Score), topKey(r.ByType))
+		}
+		w.Flush()
+		fmt.Println()
+	}
+
+	if len(events) > 0 && threatsGroupBy == "" {
+		fmt.Println("Recent Threats")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "TIME\tTYPE\tAGENT\tROUTE\tACTION")
+		for _, t := range events {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", t.Time, t.Type, t.Agent, t.Route, t.Action)
+		}
+		w.Flush()
+	}
+}
+
+// printThreatGroups prints event counts grouped by agent, route or ip
+func printThreatGroups(events []threatEvent, groupBy string) {
+	type group struct {
+		key    string
+		count  int
+		byType map[string]int
+		last   string
+	}
+	groups := map[string]*group{}
+	for _, e := range events {
+		key := map[string]string{"agent": e.Agent, "route": e.Route, "ip": e.IP}[groupBy]
+		if key == "" {
+			key = "(unknown)"
+		}
+		g, ok := groups[key]
+		if !ok {
+			g = &group{key: key, byType: map[string]int{}}
+			groups[key] = g
+		}
+		g.count++
+		g.byType[e.Type]++
+		if e.Time > g.last {
+			g.last = e.Time
+		}
+	}
+	sorted := make([]*group, 0, len(groups))
+	for _, g := range groups {
+		sorted = append(sorted, g)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].count != sorted[j].count {
+			return sorted[i].count > sorted[j].count
+		}
+		return sorted[i].key < sorted[j].key
+	})
+
+	fmt.Printf("Blocked by %s\n", groupBy)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "%s\tBLOCKED\tTOP CATEGORY\tLAST SEEN\n", strings.ToUpper(groupBy))
+	for _, g := range sorted {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", g.key, g.count, topKey(g.byType), g.last)
+	}
+	w.Flush()
+}
+
+// revokeOffenders proposes revocation of every agent at or above threshold,
+// resolving agent names to token IDs where events lack them
+func revokeOffenders(c *client.Client, risks []agentRisk, threshold int) error {
+	var offenders []agentRisk
+	for _, r := range risks {
+		if r.Score >= threshold {
+			offenders = append(offenders, r)
+		}
+	}
+	if len(offenders) == 0 {
+		fmt.Fprintf(os.Stderr, "No agents at or above risk threshold %d.\n", threshold)
+		return nil
+	}
+
+	printTarget(config.Get())
+	fmt.Fprintf(os.Stderr, "%d agent(s) at or above risk threshold %d\n", len(offenders), threshold)
+
+	var tokens []tokenInfo
+	revoked := 0
+	for _, o := range offenders {
+		tokenID := o.TokenID
+		if tokenID == "" {
+			if tokens == nil {
+				data, code, err := c.Get(tokensPath(c))
+				if err != nil {
+					return err
+				}
+				if code != 200 {
+					return fmt.Errorf("API returned HTTP %d: %s", code, string(data))
+				}
+				tokens = parseTokens(data)
+			}
+			var matches []string
+			for _, t := range tokens {
+				if t.Name == o.Agent && (t.Status == "" || t.Status == "active") {
+					matches = append(matches, t.ID)
+				}
+			}
+			if len(matches) != 1 {
+				fmt.Fprintf(os.Stderr, "⚠️  Skipping %s: %d active tokens match this agent name; revoke by ID instead.\n", o.Agent, len(matches))
+				continue
+			}
+			tokenID = matches[0]
+		}
+
+		if flagDry {
+			fmt.Fprintf(os.Stderr, "[DRY RUN] Would revoke token %s (%s, risk %d)\n", tokenID, o.Agent, o.Score)
+			continue
+		}
+
+		tokenName := describeToken(c, tokenID)
+		if !confirmAction(fmt.Sprintf("⚠️  Revoke token %s? Risk score %d from %d blocked requests.\n   This is immediate and irreversible. The agent will lose all access.", tokenName, o.Score, o.Blocked)) {
+			fmt.Fprintln(os.Stderr, "Skipped.")
+			continue
+		}
+		if _, err := revokeToken(c, tokenID); err != nil {
+			return err
+		}
+		revoked++
+		fmt.Fprintf(os.Stderr, "✓ Token %s revoked.\n", tokenName)
+	}
+	if !flagDry {
+		fmt.Fprintf(os.Stderr, "%d of %d offender(s) revoked.\n", revoked, len(offenders))
+	}
+	return nil
+}
+
+func riskLabel(score int) string {
+	switch {
+	case score >= 75:
+		return fmt.Sprintf("%d high", score)
+	case score >= 40:
+		return fmt.Sprintf("%d medium", score)
+	default:
+		return fmt.Sprintf("%d low", score)
+	}
+}
+
+// topKey returns the key with the highest count, ties broken alphabetically
+func topKey(counts map[string]int) string {
+	best := ""
+	for _, k := range sortedKeys(counts) {
+		if best == "" || counts[k] > counts[best] {
+			best = k
+		}
+	}
+	return best
+}
+
+// parseTimeFlag accepts a duration ago ("24h", "7d"), a date (YYYY-MM-DD)
+// or an RFC 3339 timestamp
+func parseTimeFlag(s string, now time.Time) (time.Time, error) {
+	if d, err := parseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (use 24h, 7d, YYYY-MM-DD or RFC 3339)", s)
+}
@@ -0,0 +1,294 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/SatGate-io/satgate-cli/internal/client"
+	"github.com/SatGate-io/satgate-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	complianceUnused   string
+	complianceMaxDepth int
+	complianceSince    string
+	complianceSkip     []string
+)
+
+var reportComplianceCmd = &cobra.Command{
+	Use:   "compliance",
+	Short: "Audit tokens and routes against governance rules (non-zero exit on failure)",
+	Long: `Evaluate every token and route against a fixed set of governance rules and
+report pass/fail per rule with the offending tokens or routes.
+
+Rules:
+  unlimited-budget   active tokens without a budget ceiling
+  no-expiry          active tokens that never expire
+  wildcard-routes    active tokens scoped to all routes (*)
+  public-routes      routes served without authentication
+  unused-tokens      active tokens not used within --unused (default 30d)
+  delegation-depth   delegation chains deeper than --max-depth
+  revocations        tokens revoked since --since (informational)
+
+The command exits non-zero when any rule fails, so it can gate CI pipelines.
+Use --skip to accept known exceptions, e.g. --skip public-routes.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := config.Get()
+		c, err := client.New()
+		if err != nil {
+			return err
+		}
+
+		unusedAge, err := parseDuration(complianceUnused)
+		if err != nil {
+			return fmt.Errorf("--unused: %w", err)
+		}
+		sinceAge, err := parseDuration(complianceSince)
+		if err != nil {
+			return fmt.Errorf("--since: %w", err)
+		}
+		for _, id := range complianceSkip {
+			if _, ok := complianceRuleNames[id]; !ok {
+				return fmt.Errorf("unknown rule %q in --skip", id)
+			}
+		}
+
+		data, code, err := c.Get(tokensPath(c))
+		if err != nil {
+			return fmt.Errorf("cannot reach gateway at %s: %w", cfg.Gateway, err)
+		}
+		if code != 200 {
+			return fmt.Errorf("API returned HTTP %d: %s", code, string(data))
+		}
+		sel, err := optionalSelector(reportSelector)
+		if err != nil {
+			return err
+		}
+		tokens := sel.selectTokens(parseTokens(data))
+
+		routes, err := fetchRoutes(c)
+		if err != nil {
+			return err
+		}
+
+		now := clock()
+		report := complianceReport{
+			GeneratedAt: now.UTC().Format(time.RFC3339),
+			Gateway:     cfg.Gateway,
+			Surface:     cfg.Surface,
+			Selector:    sel.String(),
+			Rules: evaluateCompliance(tokens, routes, complianceOptions{
+				Now:         now,
+				UnusedAfter: unusedAge,
+				MaxDepth:    complianceMaxDepth,
+				Since:       now.Add(-sinceAge),
+				Skip:        complianceSkip,
+			}),
+		}
+		for _, r := range report.Rules {
+			if r.Status == "fail" {
+				report.Failed++
+			}
+		}
+
+		if flagJSON {
+			out, _ := json.MarshalIndent(report, "", "  ")
+			fmt.Println(string(out))
+		} else {
+			report.print()
+		}
+
+		if report.Failed > 0 {
+			return fmt.Errorf("compliance check failed: %d of %d rules failed", report.Failed, len(report.Rules))
+		}
+		return nil
+	},
+}
+
+func init() {
+	reportComplianceCmd.Flags().StringVar(&complianceUnused, "unused", "30d", "flag active tokens unused for this long")
+	reportComplianceCmd.Flags().IntVar(&complianceMaxDepth, "max-depth", 3, "maximum allowed delegation depth")
+	reportComplianceCmd.Flags().StringVar(&complianceSince, "since", "30d", "report revocations within this period")
+	reportComplianceCmd.Flags().StringSliceVar(&complianceSkip, "skip", nil, "rules to report but not fail on (comma-separated)")
+	reportCmd.AddCommand(reportComplianceCmd)
+}
+
+// fetchRoutes returns the gateway's route table
+func fetchRoutes(c *client.Client) ([]routeInfo, error) {
+	data, code, err := c.Get("/admin/routes")
+	if err != nil {
+		return nil, fmt.Errorf("cannot reach gateway at %s: %w", config.Get().Gateway, err)
+	}
+	if code != 200 {
+		return nil, fmt.Errorf("API returned HTTP %d: %s", code, string(data))
+	}
+	return parseRoutes(data), nil
+}
+
+// complianceRuleNames describes each rule for display
+var complianceRuleNames = map[string]string{
+	"unlimited-budget": "Tokens with unlimited budget",
+	"no-expiry":        "Tokens without expiry",
+	"wildcard-routes":  "Tokens scoped to all routes",
+	"public-routes":    "Public (unauthenticated) routes",
+	"unused-tokens":    "Unused active tokens",
+	"delegation-depth": "Delegation depth",
+	"revocations":      "Revocations in period",
+}
+
+type complianceOptions struct {
+	Now         time.Time
+	UnusedAfter time.Duration
+	MaxDepth    int
+	Since       time.Time
+	Skip        []string
+}
+
+type complianceReport struct {
+	GeneratedAt string           `json:"generated_at"`
+	Gateway     string           `json:"gateway"`
+	Surface     string           `json:"surface"`
+	Selector    string           `json:"selector,omitempty"`
+	Failed      int              `json:"failed"`
+	Rules       []complianceRule `json:"rules"`
+}
+
+// complianceRule is one rule's outcome. Status is pass, fail, skipped
+// (failed but accepted via --skip) or info (never fails).
+type complianceRule struct {
+	ID          string              `json:"id"`
+	Description string              `json:"description"`
+	Status      string              `json:"status"`
+	Findings    []complianceFinding `json:"findings"`
+}
+
+type complianceFinding struct {
+	ID     string `json:"id"`
+	Name   string `json:"name,omitempty"`
+	Detail string `json:"detail,omitempty"`
+}
+
+func evaluateCompliance(tokens []tokenInfo, routes []routeInfo, opts complianceOptions) []complianceRule {
+	skip := map[string]bool{}
+	for _, id := range opts.Skip {
+		skip[id] = true
+	}
+
+	var active []tokenInfo
+	for _, t := range tokens {
+		if t.Status == "" || t.Status == "active" {
+			active = append(active, t)
+		}
+	}
+	finding := func(t tokenInfo, detail string) complianceFinding {
+		return complianceFinding{ID: t.ID, Name: t.Name, Detail: detail}
+	}
+
+	findings := map[string][]complianceFinding{}
+	for _, t := range active {
+		if t.Budget <= 0 {
+			findings["unlimited-budget"] = append(findings["unlimited-budget"], finding(t, ""))
+		}
+		if t.ExpiresAt == "" {
+			findings["no-expiry"] = append(findings["no-expiry"], finding(t, ""))
+		}
+		if len(t.Routes) == 0 {
+			findings["wildcard-routes"] = append(findings["wildcard-routes"], finding(t, "no route restriction"))
+		} else {
+			for _, r := range t.Routes {
+				if r == "*" || r == "/*" {
+					findings["wildcard-routes"] = append(findings["wildcard-routes"], finding(t, "routes: "+strings.Join(t.Routes, ", ")))
+					break
+				}
+			}
+		}
+		if lastUsed, ok := parseTimestamp(t.LastUsedAt); ok {
+			if opts.Now.Sub(lastUsed) > opts.UnusedAfter {
+				findings["unused-tokens"] = append(findings["unused-tokens"], finding(t, "last used "+lastUsed.Format("2006-01-02")))
+			}
+		} else if created, ok := parseTimestamp(t.CreatedAt); ok && opts.Now.Sub(created) > opts.UnusedAfter {
+			findings["unused-tokens"] = append(findings["unused-tokens"], finding(t, "never used, created "+created.Format("2006-01-02")))
+		}
+		if t.Depth > opts.MaxDepth {
+			findings["delegation-depth"] = append(findings["delegation-depth"], finding(t, fmt.Sprintf("depth %d > %d", t.Depth, opts.MaxDepth)))
+		}
+	}
+	for _, r := range routes {
+		if canonicalMode(r.Policy) == "public" {
+			findings["public-routes"] = append(findings["public-routes"], complianceFinding{ID: r.Path, Name: r.Name})
+		}
+	}
+	for _, t := range tokens {
+		if revoked, ok := parseTimestamp(t.RevokedAt); ok && !revoked.Before(opts.Since) {
+			findings["revocations"] = append(findings["revocations"], finding(t, "revoked "+revoked.Format("2006-01-02 15:04")))
+		}
+	}
+
+	order := []string{"unlimited-budget", "no-expiry", "wildcard-routes", "public-routes", "unused-tokens", "delegation-depth", "revocations"}
+	rules := make([]complianceRule, 0, len(order))
+	for _, id := range order {
+		rule := complianceRule{
+			ID:          id,
+			Description: complianceRuleNames[id],
+			Status:      "pass",
+			Findings:    findings[id],
+		}
+		switch {
+		case id == "revocations":
+			rule.Status = "info"
+		case len(rule.Findings) > 0 && skip[id]:
+			rule.Status = "skipped"
+		case len(rule.Findings) > 0:
+			rule.Status = "fail"
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+func (r complianceReport) print() {
+	fmt.Println("Compliance Report")
+	fmt.Println("─────────────────────────────")
+	if r.Selector != "" {
+		fmt.Printf("  Tokens labeled %s\n\n", r.Selector)
+	}
+
+	icons := map[string]string{
+		"pass":    "✓ pass",
+		"fail":    "✗ FAIL",
+		"skipped": "– skipped",
+		"info":    "ℹ info",
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RULE\tSTATUS\tFINDINGS\tDESCRIPTION")
+	fmt.Fprintln(w, "────\t──────\t────────\t───────────")
+	for _, rule := range r.Rules {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", rule.ID, icons[rule.Status], len(rule.Findings), rule.Description)
+	}
+	w.Flush()
+
+	for _, rule := range r.Rules {
+		if len(rule.Findings) == 0 {
+			continue
+		}
+		fmt.Printf("\n%s (%s)\n", rule.Description, rule.ID)
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, f := range rule.Findings {
+			fmt.Fprintf(w, "  %s\t%s\t%s\n", f.ID, f.Name, f.Detail)
+		}
+		w.Flush()
+	}
+
+	fmt.Println()
+	if r.Failed > 0 {
+		fmt.Printf("✗ %d of %d rules failed\n", r.Failed, len(r.Rules))
+	} else {
+		fmt.Printf("✓ All %d rules passed\n", len(r.Rules))
+	}
+}
@@ -0,0 +1,720 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/SatGate-io/satgate-cli/internal/client"
+	"github.com/SatGate-io/satgate-cli/internal/config"
+	"github.com/SatGate-io/satgate-cli/internal/money"
+	"github.com/SatGate-io/satgate-cli/internal/rates"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reportSpendMonth   string
+	reportSpendSince   string
+	reportSpendUntil   string
+	reportSpendGroupBy string
+	reportSpendFormat  string
+	reportSpendOutput  string
+)
+
+var reportSpendCmd = &cobra.Command{
+	Use:   "spend",
+	Short: "Chargeback report of spend for a period, grouped and compared to the previous period",
+	Long: `Generate a period-bounded chargeback report.
+
+Spend is grouped by cost center, department, agent or route and compared with
+the preceding period of the same length (month-over-month for --month).
+The default period is the last full calendar month. Cloud rollups group by
+cost center or department only, and a gateway that cannot bound spend to
+the period is an error rather than a report of all-time totals.
+
+Formats: table (default), csv, markdown, html. The HTML report is a single
+self-contained file with inline charts, suitable for mailing to finance.
+
+Spend in several currencies (e.g. sats and USD) needs --currency to be
+added up; the report then records the rates and timestamp it used.`,
+	Example: `  satgate report spend --month 2026-09 --group-by department
+  satgate report spend --since 2026-09-01 --until 2026-10-01 --format csv -o sept.csv
+  satgate report spend --format html -o chargeback.html
+  satgate report spend --currency EUR --rates https://prices.example.com/btc.json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := client.New()
+		if err != nil {
+			return err
+		}
+
+		groupBy, ok := spendGroupings[reportSpendGroupBy]
+		if !ok {
+			return fmt.Errorf("invalid --group-by %q (use cost-center, department, agent or route)", reportSpendGroupBy)
+		}
+		period, err := resolveReportPeriod(reportSpendMonth, reportSpendSince, reportSpendUntil, clock())
+		if err != nil {
+			return err
+		}
+
+		sel, err := optionalSelector(reportSelector)
+		if err != nil {
+			return err
+		}
+
+		current, err := fetchSpendRows(c, period, groupBy, sel)
+		if err != nil {
+			return err
+		}
+		previous, err := fetchSpendRows(c, period.previous(), groupBy, sel)
+		if err != nil {
+			return err
+		}
+		if sel != nil {
+			keep, err := spendRowFilter(c, groupBy, sel)
+			if err != nil {
+				return err
+			}
+			current, previous = filterSpendRows(current, keep), filterSpendRows(previous, keep)
+		}
+		cv, err := newConverter(c)
+		if err != nil {
+			return err
+		}
+		currency, err := convertSpendRows(cv, current, previous)
+		if err != nil {
+			return err
+		}
+		report := buildSpendReport(period, groupBy, currency, current, previous)
+		if sel != nil {
+			report.Meta["selector"] = sel.String()
+		}
+		if quotes := cv.quotes(); len(quotes) > 0 {
+			report.Meta["rates"] = quotes
+		}
+
+		out := io.Writer(os.Stdout)
+		if reportSpendOutput != "" {
+			f, err := os.Create(reportSpendOutput)
+			if err != nil {
+				return fmt.Errorf("creating %s: %w", reportSpendOutput, err)
+			}
+			defer f.Close()
+			out = f
+		}
+
+		format := reportSpendFormat
+		if flagJSON {
+			format = "json"
+		}
+		switch format {
+		case "json":
+			enc := json.NewEncoder(out)
+			enc.SetIndent("", "  ")
+			err = enc.Encode(report)
+		case "csv":
+			err = report.writeCSV(out)
+		case "markdown", "md":
+			err = report.writeMarkdown(out)
+		case "html":
+			err = report.writeHTML(out)
+		case "table", "":
+			err = report.writeTable(out)
+		default:
+			return fmt.Errorf("invalid --format %q (use table, csv, markdown or html)", format)
+		}
+		if err != nil {
+			return err
+		}
+
+		if reportSpendOutput != "" {
+			fmt.Fprintf(os.Stderr, "✓ Wrote %s report to %s\n", format, reportSpendOutput)
+		}
+		return nil
+	},
+}
+
+func init() {
+	reportSpendCmd.Flags().StringVar(&reportSpendMonth, "month", "", "calendar month to report (YYYY-MM)")
+	reportSpendCmd.Flags().StringVar(&reportSpendSince, "since", "", "period start date, inclusive (YYYY-MM-DD)")
+	reportSpendCmd.Flags().StringVar(&reportSpendUntil, "until", "", "period end date, exclusive (YYYY-MM-DD, default today)")
+	reportSpendCmd.Flags().StringVar(&reportSpendGroupBy, "group-by", "cost-center", "group by cost-center, department, agent or route")
+	reportSpendCmd.Flags().StringVar(&reportSpendFormat, "format", "table", "output format: table, csv, markdown, html")
+	reportSpendCmd.Flags().StringVarP(&reportSpendOutput, "output", "o", "", "write the report to a file instead of stdout")
+	addCurrencyFlags(reportSpendCmd.Flags())
+	reportCmd.AddCommand(reportSpendCmd)
+}
+
+// spendGroupings maps --group-by values to the field name used by the APIs
+var spendGroupings = map[string]string{
+	"cost-center": "costCenter",
+	"department":  "department",
+	"agent":       "agent",
+	"route":       "route",
+}
+
+// reportPeriod is a half-open [Since, Until) date range
+type reportPeriod struct {
+	Since time.Time `json:"since"`
+	Until time.Time `json:"until"`
+	Month bool      `json:"-"`
+}
+
+func (p reportPeriod) String() string {
+	if p.Month {
+		return p.Since.Format("January 2006")
+	}
+	return p.Since.Format("2006-01-02") + " → " + p.Until.AddDate(0, 0, -1).Format("2006-01-02")
+}
+
+// previous returns the period of the same length immediately before p.
+// Calendar months step back one month so deltas are month-over-month.
+func (p reportPeriod) previous() reportPeriod {
+	if p.Month {
+		return reportPeriod{Since: p.Since.AddDate(0, -1, 0), Until: p.Since, Month: true}
+	}
+	length := p.Until.Sub(p.Since)
+	return reportPeriod{Since: p.Since.Add(-length), Until: p.Since}
+}
+
+// resolveReportPeriod turns --month or --since/--until into a period.
+// With neither, it returns the last full calendar month before now.
+func resolveReportPeriod(month, since, until string, now time.Time) (reportPeriod, error) {
+	if month != "" {
+		if since != "" || until != "" {
+			return reportPeriod{}, fmt.Errorf("--month cannot be combined with --since/--until")
+		}
+		start, err := time.Parse("2006-01", month)
+		if err != nil {
+			return reportPeriod{}, fmt.Errorf("invalid --month %q (use YYYY-MM)", month)
+		}
+		return reportPeriod{Since: start, Until: start.AddDate(0, 1, 0), Month: true}, nil
+	}
+	if since == "" && until == "" {
+		thisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return reportPeriod{Since: thisMonth.AddDate(0, -1, 0), Until: thisMonth, Month: true}, nil
+	}
+	if since == "" {
+		return reportPeriod{}, fmt.Errorf("--until requires --since")
+	}
+	start, err := time.Parse("2006-01-02", since)
+	if err != nil {
+		return reportPeriod{}, fmt.Errorf("invalid --since %q (use YYYY-MM-DD)", since)
+	}
+	end := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+	if until != "" {
+		end, err = time.Parse("2006-01-02", until)
+		if err != nil {
+			return reportPeriod{}, fmt.Errorf("invalid --until %q (use YYYY-MM-DD)", until)
+		}
+	}
+	if !end.After(start) {
+		return reportPeriod{}, fmt.Errorf("--until must be after --since")
+	}
+	return reportPeriod{Since: start, Until: end}, nil
+}
+
+// spendRow is one group's spend in display units of Currency (USD when
+// blank)
+type spendRow struct {
+	Key       string  `json:"key"`
+	Consumed  float64 `json:"consumed"`
+	Allocated float64 `json:"allocated"`
+	Tokens    int     `json:"tokens"`
+	Currency  string  `json:"currency"`
+}
+
+// convertSpendRows converts rows to --currency in place and returns the
+// currency the report is in. Without --currency every row must already
+// share one currency.
+func convertSpendRows(cv *converter, sets ...[]spendRow) (string, error) {
+	if cv == nil {
+		found := money.Totals{}
+		for _, rows := range sets {
+			for _, r := range rows {
+				found.Add(0, r.Currency)
+			}
+		}
+		if len(found) > 1 {
+			return "", fmt.Errorf("spend is in several currencies (%s); pass --currency to convert it to one", strings.Join(sortedKeys(found), ", "))
+		}
+		_, code, _ := found.Single()
+		return currencyCode(code), nil
+	}
+	for _, rows := range sets {
+		for i := range rows {
+			r := &rows[i]
+			var err error
+			if r.Consumed, err = cv.convert(r.Consumed, r.Currency); err != nil {
+				return "", err
+			}
+			if r.Allocated, err = cv.convert(r.Allocated, r.Currency); err != nil {
+				return "", err
+			}
+			r.Currency = cv.to.Code
+		}
+	}
+	return cv.to.Code, nil
+}
+
+// fetchSpendRows queries spend for a period, grouped server-side
+func fetchSpendRows(c *client.Client, p reportPeriod, groupBy string, sel labelSelector) ([]spendRow, error) {
+	q := url.Values{}
+	var path string
+	if c.Surface() == "cloud" {
+		path = "/cloud/delegation-v2/cost-rollups"
+		q.Set("from", p.Since.Format(time.RFC3339))
+		q.Set("to", p.Until.Format(time.RFC3339))
+		q.Set("groupBy", groupBy)
+	} else {
+		path = "/admin/spend"
+		q.Set("since", p.Since.Format(time.RFC3339))
+		q.Set("until", p.Until.Format(time.RFC3339))
+		q.Set("group_by", groupBy)
+	}
+	if sel != nil {
+		q.Set("selector", sel.String())
+	}
+
+	data, code, err := c.Get(path + "?" + q.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("cannot reach gateway at %s: %w", config.Get().Gateway, err)
+	}
+	if code != 200 {
+		return nil, fmt.Errorf("API returned HTTP %d: %s", code, string(data))
+	}
+	return parseSpendRows(data, groupBy)
+}
+
+// spendRowFilter decides locally which grouped rows can hold tokens that
+// match sel: agent rows by the matching tokens' names, cost center and
+// department rows by the selector's requirements on that label. Route
+// rows cannot be attributed to tokens and rely on the server.
+func spendRowFilter(c *client.Client, groupBy string, sel labelSelector) (func(spendRow) bool, error) {
+	switch groupBy {
+	case "agent":
+		data, code, err := c.Get(tokensPath(c))
+		if err != nil {
+			return nil, fmt.Errorf("cannot reach gateway at %s: %w", config.Get().Gateway, err)
+		}
+		if code != 200 {
+			return nil, fmt.Errorf("API returned HTTP %d: %s", code, string(data))
+		}
+		names := map[string]bool{}
+		for _, t := range sel.selectTokens(parseTokens(data)) {
+			names[t.Name] = true
+		}
+		return func(r spendRow) bool { return names[r.Key] }, nil
+	case "costCenter":
+		return func(r spendRow) bool { return sel.matchesGroup(labelCostCenter, r.Key) }, nil
+	case "department":
+		return func(r spendRow) bool { return sel.matchesGroup(labelDepartment, r.Key) }, nil
+	}
+	return func(spendRow) bool { return true }, nil
+}
+
+func filterSpendRows(rows []spendRow, keep func(spendRow) bool) []spendRow {
+	var out []spendRow
+	for _, r := range rows {
+		if keep(r) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// parseSpendRows accepts grouped spend ({"groups": [...]}) or cloud
+// rollups (credits) grouped by groupBy.
+// Rollups only group by cost center or department. A gateway that answers
+// with its all-time org summary cannot bound spend to the period, so that
+// is an error rather than a report with meaningless deltas.
+func parseSpendRows(data []byte, groupBy string) ([]spendRow, error) {
+	var resp struct {
+		Groups  *[]spendRow     `json:"groups"`
+		Rollups json.RawMessage `json:"rollups"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("gateway returned invalid JSON: %w", err)
+	}
+	if resp.Groups != nil {
+		return *resp.Groups, nil
+	}
+	if resp.Rollups == nil {
+		return nil, fmt.Errorf("gateway does not report spend per period (it returned its all-time summary); use satgate spend for totals")
+	}
+
+	switch groupBy {
+	case "costCenter", "department":
+	default:
+		return nil, fmt.Errorf("cloud cost rollups can only be grouped by cost-center or department, not %s", reportSpendGroupBy)
+	}
+	var rollups spendRollups
+	if err := json.Unmarshal(data, &rollups); err != nil {
+		return nil, fmt.Errorf("gateway returned invalid JSON: %w", err)
+	}
+	byKey := map[string]*spendRow{}
+	var order []string
+	for _, r := range rollups.Rollups {
+		key := r.CostCenter
+		if groupBy == "department" {
+			key = r.Department
+		}
+		if key == "" {
+			key = "(unassigned)"
+		}
+		row, ok := byKey[key]
+		if !ok {
+			row = &spendRow{Key: key, Currency: "USD"}
+			byKey[key] = row
+			order = append(order, key)
+		}
+		row.Consumed += r.TotalConsumed / 100 // credits → dollars
+		row.Allocated += r.TotalAllocated / 100
+		row.Tokens += r.TokenCount
+	}
+	rows := make([]spendRow, 0, len(order))
+	for _, key := range order {
+		rows = append(rows, *byKey[key])
+	}
+	return rows, nil
+}
+
+// spendReport is a chargeback report with period-over-period deltas
+type spendReport struct {
+	Period   reportPeriod           `json:"period"`
+	Previous reportPeriod           `json:"previous_period"`
+	GroupBy  string                 `json:"group_by"`
+	Currency string                 `json:"currency"`
+	Rows     []spendReportRow       `json:"rows"`
+	Total    spendReportRow         `json:"total"`
+	Meta     map[string]interface{} `json:"meta"`
+}
+
+type spendReportRow struct {
+	Key          string   `json:"key"`
+	Consumed     float64  `json:"consumed"`
+	Allocated    float64  `json:"allocated"`
+	Tokens       int      `json:"tokens"`
+	Utilization  *float64 `json:"utilization_pct"`
+	PrevConsumed float64  `json:"previous_consumed"`
+	Delta        float64  `json:"delta"`
+	DeltaPct     *float64 `json:"delta_pct"`
+	currency     string
+}
+
+func buildSpendReport(p reportPeriod, groupBy, currency string, current, previous []spendRow) spendReport {
+	prev := map[string]float64{}
+	for _, r := range previous {
+		prev[r.Key] += r.Consumed
+	}
+	seen := map[string]bool{}
+
+	report := spendReport{
+		Period:   p,
+		Previous: p.previous(),
+		GroupBy:  groupBy,
+		Currency: currency,
+		Total:    spendReportRow{Key: "Total"},
+		Meta: map[string]interface{}{
+			"generated_at": clock().UTC().Format(time.RFC3339),
+			"gateway":      config.Get().Gateway,
+			"surface":      config.Get().Surface,
+		},
+	}
+	add := func(key string, consumed, allocated float64, tokens int) {
+		row := newSpendReportRow(key, consumed, allocated, tokens, prev[key])
+		report.Rows = append(report.Rows, row)
+		report.Total.Consumed += consumed
+		report.Total.Allocated += allocated
+		report.Total.Tokens += tokens
+		report.Total.PrevConsumed += prev[key]
+	}
+	for _, r := range current {
+		seen[r.Key] = true
+		add(r.Key, r.Consumed, r.Allocated, r.Tokens)
+	}
+	// Groups that spent last period but not this one still belong in a chargeback
+	for key := range prev {
+		if !seen[key] {
+			add(key, 0, 0, 0)
+		}
+	}
+	report.Total = newSpendReportRow("Total", report.Total.Consumed, report.Total.Allocated, report.Total.Tokens, report.Total.PrevConsumed)
+
+	report.Total.currency = currency
+	for i := range report.Rows {
+		report.Rows[i].currency = currency
+	}
+
+	sort.Slice(report.Rows, func(i, j int) bool {
+		if report.Rows[i].Consumed != report.Rows[j].Consumed {
+			return report.Rows[i].Consumed > report.Rows[j].Consumed
+		}
+		return report.Rows[i].Key < report.Rows[j].Key
+	})
+	return report
+}
+
+func newSpendReportRow(key string, consumed, allocated float64, tokens int, prevConsumed float64) spendReportRow {
+	row := spendReportRow{
+		Key:          key,
+		Consumed:     consumed,
+		Allocated:    allocated,
+		Tokens:       tokens,
+		PrevConsumed: prevConsumed,
+		Delta:        consumed - prevConsumed,
+	}
+	if allocated > 0 {
+		u := consumed / allocated * 100
+		row.Utilization = &u
+	}
+	if prevConsumed > 0 {
+		d := (consumed - prevConsumed) / prevConsumed * 100
+		row.DeltaPct = &d
+	}
+	return row
+}
+
+// Util formats utilization for display ("—" when unlimited)
+func (r spendReportRow) Util() string {
+	if r.Utilization == nil {
+		return "—"
+	}
+	return fmt.Sprintf("%.1f%%", *r.Utilization)
+}
+
+// Change formats the period-over-period delta, e.g. "+$12.00 (+4.1%)"
+func (r spendReportRow) Change() string {
+	sign := "+"
+	if r.Delta < 0 {
+		sign = "-"
+	}
+	s := sign + money.Format(math.Abs(r.Delta), r.currency)
+	if r.DeltaPct != nil {
+		s += fmt.Sprintf(" (%+.1f%%)", *r.DeltaPct)
+	} else if r.Consumed > 0 {
+		s += " (new)"
+	}
+	return s
+}
+
+// spendGroupTitles are display names for each grouping, keyed by API field
+var spendGroupTitles = map[string]string{
+	"costCenter": "Cost center",
+	"department": "Department",
+	"agent":      "Agent",
+	"route":      "Route",
+}
+
+func (r spendReport) groupTitle() string {
+	if t, ok := spendGroupTitles[r.GroupBy]; ok {
+		return t
+	}
+	return r.GroupBy
+}
+
+func (r spendReport) writeTable(out io.Writer) error {
+	fmt.Fprintf(out, "Spend Report — %s\n", r.Period)
+	fmt.Fprintln(out, "─────────────────────────────")
+	fmt.Fprintf(out, "  Compared with: %s\n", r.Previous)
+	if sel, ok := r.Meta["selector"]; ok {
+		fmt.Fprintf(out, "  Labels:        %s\n", sel)
+	}
+	if r.Currency != "USD" {
+		fmt.Fprintf(out, "  Currency:      %s\n", r.Currency)
+	}
+	fmt.Fprintln(out)
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "%s\tCONSUMED\tALLOCATED\tUTILIZATION\tPREVIOUS\tCHANGE\n", strings.ToUpper(r.groupTitle()))
+	fmt.Fprintln(w, "─────\t────────\t─────────\t───────────\t────────\t──────")
+	for _, row := range append(r.Rows, r.Total) {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			row.Key, r.format(row.Consumed), r.format(row.Allocated), row.Util(), r.format(row.PrevConsumed), row.Change())
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if note := r.ratesNote(); note != "" {
+		fmt.Fprintf(out, "\nRates: %s\n", note)
+	}
+	return nil
+}
+
+func (r spendReport) format(v float64) string {
+	return money.Format(v, r.Currency)
+}
+
+// ratesNote describes the rates a converted report used
+func (r spendReport) ratesNote() string {
+	quotes, _ := r.Meta["rates"].([]rates.Quote)
+	return describeQuotes(quotes)
+}
+
+func (r spendReport) writeCSV(out io.Writer) error {
+	w := csv.NewWriter(out)
+	unit := strings.ToLower(r.Currency)
+	w.Write([]string{strings.ToLower(strings.ReplaceAll(r.groupTitle(), " ", "_")), "period_start", "period_end", "consumed_" + unit, "allocated_" + unit, "utilization_pct", "previous_consumed_" + unit, "delta_" + unit, "delta_pct", "tokens"})
+	for _, row := range append(r.Rows, r.Total) {
+		util, delta := "", ""
+		if row.Utilization != nil {
+			util = fmt.Sprintf("%.2f", *row.Utilization)
+		}
+		if row.DeltaPct != nil {
+			delta = fmt.Sprintf("%.2f", *row.DeltaPct)
+		}
+		w.Write([]string{
+			row.Key,
+			r.Period.Since.Format("2006-01-02"),
+			r.Period.Until.Format("2006-01-02"),
+			r.decimal(row.Consumed),
+			r.decimal(row.Allocated),
+			util,
+			r.decimal(row.PrevConsumed),
+			r.decimal(row.Delta),
+			delta,
+			fmt.Sprint(row.Tokens),
+		})
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// decimal formats an amount for CSV: plain digits at the currency's precision
+func (r spendReport) decimal(v float64) string {
+	c, err := money.Lookup(r.Currency)
+	if err != nil {
+		return fmt.Sprintf("%.2f", v)
+	}
+	return money.FromFloat(v, c).Decimal()
+}
+
+func (r spendReport) writeMarkdown(out io.Writer) error {
+	fmt.Fprintf(out, "# Spend Report — %s\n\n", r.Period)
+	fmt.Fprintf(out, "Compared with %s. Generated %s from `%s`.\n\n", r.Previous, r.Meta["generated_at"], r.Meta["gateway"])
+	if sel, ok := r.Meta["selector"]; ok {
+		fmt.Fprintf(out, "Tokens labeled `%s`.\n\n", sel)
+	}
+	fmt.Fprintf(out, "| %s | Consumed | Allocated | Utilization | Previous | Change |\n", r.groupTitle())
+	fmt.Fprintln(out, "|---|---:|---:|---:|---:|---:|")
+	for _, row := range r.Rows {
+		fmt.Fprintf(out, "| %s | %s | %s | %s | %s | %s |\n",
+			markdownEscape(row.Key), r.format(row.Consumed), r.format(row.Allocated), row.Util(), r.format(row.PrevConsumed), row.Change())
+	}
+	t := r.Total
+	fmt.Fprintf(out, "| **Total** | **%s** | **%s** | **%s** | **%s** | **%s** |\n",
+		r.format(t.Consumed), r.format(t.Allocated), t.Util(), r.format(t.PrevConsumed), t.Change())
+	if note := r.ratesNote(); note != "" {
+		fmt.Fprintf(out, "\nRates: %s.\n", note)
+	}
+	return nil
+}
+
+func markdownEscape(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+func (r spendReport) writeHTML(out io.Writer) error {
+	max := 0.0
+	for _, row := range r.Rows {
+		max = math.Max(max, math.Max(row.Consumed, math.Max(row.Allocated, row.PrevConsumed)))
+	}
+	type bar struct {
+		Row                              spendReportRow
+		Y                                int
+		ConsumedW, AllocatedW, PreviousW float64
+		LabelX                           float64
+	}
+	const chartWidth = 520.0
+	bars := make([]bar, len(r.Rows))
+	for i, row := range r.Rows {
+		scale := 0.0
+		if max > 0 {
+			scale = chartWidth / max
+		}
+		bars[i] = bar{
+			Row:        row,
+			Y:          i * 44,
+			ConsumedW:  row.Consumed * scale,
+			AllocatedW: row.Allocated * scale,
+			PreviousW:  row.PrevConsumed * scale,
+			LabelX:     200 + math.Max(row.Consumed, row.Allocated)*scale + 6,
+		}
+	}
+
+	return spendHTMLTemplate.Execute(out, map[string]interface{}{
+		"Report":      r,
+		"Rates":       r.ratesNote(),
+		"GroupLabel":  r.groupTitle(),
+		"Bars":        bars,
+		"ChartHeight": len(bars)*44 + 10,
+	})
+}
+
+var spendHTMLTemplate = template.Must(template.New("spend").Funcs(template.FuncMap{
+	"money": money.Format,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>SatGate Spend Report — {{.Report.Period}}</title>
+<style>
+  body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2rem auto; max-width: 960px; color: #1f2328; }
+  h1 { font-size: 1.5rem; margin-bottom: .25rem; }
+  .meta { color: #656d76; margin-top: 0; }
+  table { border-collapse: collapse; width: 100%; margin: 1.5rem 0; }
+  th, td { padding: .4rem .6rem; border-bottom: 1px solid #d0d7de; text-align: right; }
+  th:first-child, td:first-child { text-align: left; }
+  tr.total td { font-weight: 600; border-top: 2px solid #1f2328; }
+  .up { color: #cf222e; } .down { color: #1a7f37; }
+  .legend span { display: inline-block; margin-right: 1rem; }
+  .swatch { display: inline-block; width: .8rem; height: .8rem; margin-right: .3rem; vertical-align: middle; }
+</style>
+</head>
+<body>
+<h1>Spend Report — {{.Report.Period}}</h1>
+<p class="meta">Compared with {{.Report.Previous}} · {{.Report.Meta.gateway}} ({{.Report.Meta.surface}}) · generated {{.Report.Meta.generated_at}}{{with .Report.Meta.selector}} · labels {{.}}{{end}}</p>
+
+<table>
+  <thead><tr><th>{{.GroupLabel}}</th><th>Consumed</th><th>Allocated</th><th>Utilization</th><th>Previous</th><th>Change</th></tr></thead>
+  <tbody>
+  {{- range .Report.Rows}}
+    <tr><td>{{.Key}}</td><td>{{money .Consumed $.Report.Currency}}</td><td>{{money .Allocated $.Report.Currency}}</td><td>{{.Util}}</td><td>{{money .PrevConsumed $.Report.Currency}}</td><td class="{{if gt .Delta 0.0}}up{{else if lt .Delta 0.0}}down{{end}}">{{.Change}}</td></tr>
+  {{- end}}
+  {{- with .Report.Total}}
+    <tr class="total"><td>Total</td><td>{{money .Consumed $.Report.Currency}}</td><td>{{money .Allocated $.Report.Currency}}</td><td>{{.Util}}</td><td>{{money .PrevConsumed $.Report.Currency}}</td><td>{{.Change}}</td></tr>
+  {{- end}}
+  </tbody>
+</table>
+{{- with .Rates}}
+<p class="meta">Rates: {{.}}</p>
+{{- end}}
+
+<h2>Consumed vs. allocated vs. previous period</h2>
+<p class="legend">
+  <span><i class="swatch" style="background:#d0d7de"></i>Allocated</span>
+  <span><i class="swatch" style="background:#0969da"></i>Consumed</span>
+  <span><i class="swatch" style="background:#bf8700"></i>Previous period</span>
+</p>
+<svg xmlns="http://www.w3.org/2000/svg" width="900" height="{{.ChartHeight}}" role="img" aria-label="Spend by {{.GroupLabel}}">
+{{- range .Bars}}
+  <g transform="translate(0,{{.Y}})">
+    <text x="0" y="18" font-size="12">{{.Row.Key}}</text>
+    <rect x="200" y="4" width="{{printf "%.1f" .AllocatedW}}" height="18" fill="#d0d7de"/>
+    <rect x="200" y="4" width="{{printf "%.1f" .ConsumedW}}" height="18" fill="#0969da"/>
+    <rect x="200" y="25" width="{{printf "%.1f" .PreviousW}}" height="6" fill="#bf8700"/>
+    <text x="{{printf "%.1f" .LabelX}}" y="18" font-size="11" fill="#1f2328">{{money .Row.Consumed $.Report.Currency}}</text>
+  </g>
+{{- end}}
+</svg>
+</body>
+</html>
+`))
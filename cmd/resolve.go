@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/SatGate-io/satgate-cli/internal/client"
+)
+
+// resolvedID is a token ID argument after prefix resolution
+type resolvedID struct {
+	Input string
+	ID    string
+	Name  string
+}
+
+// resolveTokenID expands a unique ID prefix to the full token ID, git
+// style. The "…" the tokens table appends to truncated IDs is accepted.
+// Ambiguous prefixes are an error listing the candidates. When nothing in
+// the listing matches, or the listing cannot be fetched, the input is
+// passed through unchanged so the API has the final say.
+func resolveTokenID(c *client.Client, input string) (resolvedID, error) {
+	data, code, err := c.Get(tokensPath(c))
+	if err != nil || code != 200 {
+		data = nil
+	}
+	return matchTokenPrefix(parseTokens(data), input)
+}
+
+// matchTokenPrefix resolves input against an already-fetched token list
+func matchTokenPrefix(tokens []tokenInfo, input string) (resolvedID, error) {
+	prefix := strings.TrimSuffix(strings.TrimSpace(input), "…")
+	r := resolvedID{Input: input, ID: prefix}
+	if prefix == "" {
+		return r, fmt.Errorf("empty token ID")
+	}
+
+	var matches []tokenInfo
+	for _, t := range tokens {
+		if t.ID == prefix {
+			r.Name = t.Name
+			return r, nil
+		}
+		if strings.HasPrefix(t.ID, prefix) {
+			matches = append(matches, t)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return r, nil
+	case 1:
+		r.ID, r.Name = matches[0].ID, matches[0].Name
+		return r, nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "token ID prefix %q is ambiguous; it matches %d tokens:", prefix, len(matches))
+	for _, t := range matches {
+		fmt.Fprintf(&b, "\n  %s  %s (%s)", t.ID, t.Name, t.Status)
+	}
+	return r, fmt.Errorf("%s", b.String())
+}
+
+// resolved reports whether the input was expanded to a different ID
+func (r resolvedID) resolved() bool {
+	return r.Input != r.ID
+}
+
+func (r resolvedID) String() string {
+	if r.Name != "" {
+		return fmt.Sprintf("%s (%s)", r.ID, r.Name)
+	}
+	return r.ID
+}
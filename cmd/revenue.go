@@ -0,0 +1,524 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/SatGate-io/satgate-cli/internal/client"
+	"github.com/SatGate-io/satgate-cli/internal/config"
+	"github.com/SatGate-io/satgate-cli/internal/money"
+	"github.com/spf13/cobra"
+)
+
+var (
+	revenueSince    string
+	revenueUntil    string
+	revenueRoute    string
+	revenueClient   string
+	revenueStatus   string
+	revenueInterval string
+	revenueTop      int
+	revenueLimit    int
+	revenueFormat   string
+	revenueOutput   string
+)
+
+var revenueCmd = &cobra.Command{
+	Use:   "revenue",
+	Short: "Summarize Lightning invoices paid on charge (L402) routes",
+	Long: `Summarize income from charge-mode (L402) routes: invoices issued and
+paid, sats received, and the split by settlement status, route and paying
+client, with a time series of settled sats.
+
+Revenue settles on the gateway's own Lightning node, so this command is
+available on the gateway surface only. Filters are sent to the gateway and
+also applied to the returned payments. --since and --until select invoices
+by settlement time, or creation time for unpaid ones, so an invoice issued
+before the window but paid inside it counts.
+
+--format csv writes one row per invoice for accounting; --currency adds a
+fiat equivalent using the rate source from --rates.`,
+	Example: `  satgate revenue
+  satgate revenue --since 7d --interval hour
+  satgate revenue --route /api/premium/* --status settled
+  satgate revenue --since 2026-09-01 --until 2026-10-01 --format csv -o september.csv`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := client.New()
+		if err != nil {
+			return err
+		}
+		if c.Surface() == "cloud" {
+			return fmt.Errorf("revenue is only available on the gateway surface (L402 invoices settle on the gateway's Lightning node)")
+		}
+
+		now := clock()
+		var since, until time.Time
+		if revenueSince != "" {
+			if since, err = parseTimeFlag(revenueSince, now); err != nil {
+				return fmt.Errorf("--since: %w", err)
+			}
+		}
+		if revenueUntil != "" {
+			if until, err = parseTimeFlag(revenueUntil, now); err != nil {
+				return fmt.Errorf("--until: %w", err)
+			}
+		}
+		if _, ok := revenueBuckets[revenueInterval]; !ok {
+			return fmt.Errorf("invalid --interval %q (use hour, day, week or month)", revenueInterval)
+		}
+		switch revenueStatus {
+		case "", "settled", "pending", "expired", "failed":
+		default:
+			return fmt.Errorf("invalid --status %q (use settled, pending, expired or failed)", revenueStatus)
+		}
+		switch revenueFormat {
+		case "table", "", "csv":
+		default:
+			return fmt.Errorf("invalid --format %q (use table or csv)", revenueFormat)
+		}
+		cv, err := newConverter(c)
+		if err != nil {
+			return err
+		}
+		q := url.Values{}
+		if !since.IsZero() {
+			q.Set("since", since.UTC().Format(time.RFC3339))
+		}
+		if !until.IsZero() {
+			q.Set("until", until.UTC().Format(time.RFC3339))
+		}
+		if revenueRoute != "" {
+			q.Set("route", revenueRoute)
+		}
+		if revenueClient != "" {
+			q.Set("client", revenueClient)
+		}
+		if revenueStatus != "" {
+			q.Set("status", revenueStatus)
+		}
+
+		payments, err := fetchPayments(c, q, revenueLimit)
+		if err != nil {
+			return err
+		}
+		filter := paymentFilter{Since: since, Until: until, Route: revenueRoute, Client: revenueClient, Status: revenueStatus}
+		payments = filter.apply(payments)
+
+		out := io.Writer(os.Stdout)
+		if revenueOutput != "" {
+			f, err := os.Create(revenueOutput)
+			if err != nil {
+				return fmt.Errorf("creating %s: %w", revenueOutput, err)
+			}
+			defer f.Close()
+			out = f
+		}
+
+		if revenueFormat == "csv" {
+			if err := writePaymentsCSV(out, payments); err != nil {
+				return err
+			}
+			if revenueOutput != "" {
+				fmt.Fprintf(os.Stderr, "✓ Wrote %d invoices to %s\n", len(payments), revenueOutput)
+			}
+			return nil
+		}
+
+		summary, err := summarizeRevenue(payments, revenueInterval, revenueTop, cv)
+		if err != nil {
+			return err
+		}
+		summary.Since, summary.Until = formatBound(since), formatBound(until)
+		if flagJSON {
+			enc := json.NewEncoder(out)
+			enc.SetIndent("", "  ")
+			return enc.Encode(summary)
+		}
+		printRevenue(out, summary, filter)
+		cv.note(out)
+		return nil
+	},
+}
+
+func init() {
+	revenueCmd.Flags().StringVar(&revenueSince, "since", "30d", "only invoices settled (or, if unpaid, created) after this time (e.g. 24h, 7d, 2026-10-01)")
+	revenueCmd.Flags().StringVar(&revenueUntil, "until", "", "only invoices before this time")
+	revenueCmd.Flags().StringVar(&revenueRoute, "route", "", "filter by route (trailing * matches a prefix)")
+	revenueCmd.Flags().StringVar(&revenueClient, "client", "", "filter by paying client (token ID, agent or node pubkey)")
+	revenueCmd.Flags().StringVar(&revenueStatus, "status", "", "filter by status: settled, pending, expired or failed")
+	revenueCmd.Flags().StringVar(&revenueInterval, "interval", "day", "time series bucket: hour, day, week or month")
+	revenueCmd.Flags().IntVar(&revenueTop, "top", 10, "routes and clients to list (0 for all)")
+	revenueCmd.Flags().IntVar(&revenueLimit, "limit", 0, "maximum invoices to fetch across all pages (0 for all)")
+	revenueCmd.Flags().StringVar(&revenueFormat, "format", "table", "output format: table, or csv (one row per invoice)")
+	revenueCmd.Flags().StringVarP(&revenueOutput, "output", "o", "", "write to a file instead of stdout")
+	addCurrencyFlags(revenueCmd.Flags())
+	rootCmd.AddCommand(revenueCmd)
+}
+
+// payment is one L402 invoice issued by the gateway
+type payment struct {
+	PaymentHash string `json:"payment_hash"`
+	Route       string `json:"route"`
+	Client      string `json:"client"` // token ID, agent or payer node pubkey
+	ClientName  string `json:"client_name,omitempty"`
+	AmountSats  int64  `json:"amount_sats"`
+	AmountMsat  int64  `json:"amount_msat,omitempty"`
+	Status      string `json:"status"` // settled, pending, expired, failed
+	CreatedAt   string `json:"created_at"`
+	SettledAt   string `json:"settled_at,omitempty"`
+}
+
+// msats returns the invoice amount, preferring the exact msat figure
+func (p payment) msats() int64 {
+	if p.AmountMsat > 0 {
+		return p.AmountMsat
+	}
+	return p.AmountSats * 1000
+}
+
+// at is when the payment counts for the time series: settlement time for
+// paid invoices, creation time otherwise
+func (p payment) at() (time.Time, bool) {
+	if t, ok := parseTimestamp(p.SettledAt); ok {
+		return t, true
+	}
+	return parseTimestamp(p.CreatedAt)
+}
+
+func (p payment) clientLabel() string {
+	if p.ClientName != "" && p.ClientName != p.Client {
+		return p.ClientName + " (" + truncate(p.Client, 16) + ")"
+	}
+	return firstNonEmpty(p.Client, "(anonymous)")
+}
+
+// fetchPayments follows next_cursor like audit remote does and returns
+// payments oldest first
+func fetchPayments(c *client.Client, q url.Values, limit int) ([]payment, error) {
+	var payments []payment
+	cursor := ""
+	for {
+		q.Set("limit", "500")
+		if cursor != "" {
+			q.Set("cursor", cursor)
+		}
+		data, code, err := c.Get("/admin/payments?" + q.Encode())
+		if err != nil {
+			return nil, fmt.Errorf("cannot reach gateway at %s: %w", config.Get().Gateway, err)
+		}
+		if code == 404 {
+			return nil, fmt.Errorf("this gateway does not expose a payments endpoint (HTTP 404); is a charge route configured?")
+		}
+		if code != 200 {
+			return nil, fmt.Errorf("API returned HTTP %d: %s", code, string(data))
+		}
+
+		var page struct {
+			Payments   []payment `json:"payments"`
+			NextCursor string    `json:"next_cursor"`
+		}
+		if err := json.Unmarshal(data, &page); err != nil {
+			if err := json.Unmarshal(data, &page.Payments); err != nil {
+				return nil, fmt.Errorf("unexpected payments response: %w", err)
+			}
+		}
+		payments = append(payments, page.Payments...)
+
+		if limit > 0 && len(payments) >= limit {
+			payments = payments[:limit]
+			break
+		}
+		if page.NextCursor == "" || page.NextCursor == cursor || len(page.Payments) == 0 {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	sort.SliceStable(payments, func(i, j int) bool { return payments[i].CreatedAt < payments[j].CreatedAt })
+	return payments, nil
+}
+
+type paymentFilter struct {
+	Since, Until          time.Time
+	Route, Client, Status string
+}
+
+// apply filters payments client-side, for gateways that ignore the query.
+// The time window uses the same instant as the time series: settlement for
+// paid invoices, creation otherwise.
+func (f paymentFilter) apply(payments []payment) []payment {
+	var out []payment
+	for _, p := range payments {
+		if f.Route != "" && !matchRoute(f.Route, p.Route) {
+			continue
+		}
+		if f.Client != "" && f.Client != p.Client && f.Client != p.ClientName {
+			continue
+		}
+		if f.Status != "" && !strings.EqualFold(f.Status, p.Status) {
+			continue
+		}
+		if t, ok := p.at(); ok {
+			if !f.Since.IsZero() && t.Before(f.Since) || !f.Until.IsZero() && !t.Before(f.Until) {
+				continue
+			}
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+func (f paymentFilter) String() string {
+	var parts []string
+	if f.Route != "" {
+		parts = append(parts, "route "+f.Route)
+	}
+	if f.Client != "" {
+		parts = append(parts, "client "+f.Client)
+	}
+	if f.Status != "" {
+		parts = append(parts, f.Status+" only")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// matchRoute compares a route filter with a trailing * as a prefix match
+func matchRoute(pattern, route string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(route, prefix)
+	}
+	return pattern == route
+}
+
+// revenueSummary is the aggregated view, also the --json output. Amounts
+// are sats; Converted holds the --currency equivalent of Received.
+type revenueSummary struct {
+	Since       string                 `json:"since,omitempty"`
+	Until       string                 `json:"until,omitempty"`
+	Invoices    int                    `json:"invoices"`
+	Paid        int                    `json:"paid"`
+	Received    float64                `json:"sats_received"`
+	Outstanding float64                `json:"sats_pending"`
+	Average     float64                `json:"sats_per_paid_invoice"`
+	Converted   *float64               `json:"converted,omitempty"`
+	Statuses    []revenueGroup         `json:"by_status"`
+	Routes      []revenueGroup         `json:"by_route"`
+	Clients     []revenueGroup         `json:"by_client"`
+	Interval    string                 `json:"interval"`
+	Series      []revenueGroup         `json:"series"`
+	Conversion  map[string]interface{} `json:"conversion,omitempty"`
+}
+
+// revenueGroup is one row of a breakdown: invoices issued, paid and sats
+// received for a route, client or time bucket. Status rows count the value
+// of every invoice, so pending and expired amounts are visible.
+type revenueGroup struct {
+	Key      string  `json:"key"`
+	Invoices int     `json:"invoices"`
+	Paid     int     `json:"paid"`
+	Sats     float64 `json:"sats"`
+}
+
+// revenueBuckets truncates a time to the start of its series bucket
+var revenueBuckets = map[string]func(time.Time) string{
+	"hour":  func(t time.Time) string { return t.Truncate(time.Hour).Format("2006-01-02 15:00") },
+	"day":   func(t time.Time) string { return t.Format("2006-01-02") },
+	"month": func(t time.Time) string { return t.Format("2006-01") },
+	"week": func(t time.Time) string {
+		offset := (int(t.Weekday()) + 6) % 7 // weeks start on Monday
+		return t.AddDate(0, 0, -offset).Format("2006-01-02")
+	},
+}
+
+func summarizeRevenue(payments []payment, interval string, top int, cv *converter) (revenueSummary, error) {
+	s := revenueSummary{Invoices: len(payments), Interval: interval}
+	bucket := revenueBuckets[interval]
+	statuses, routes, clients, series := map[string]*revenueGroup{}, map[string]*revenueGroup{}, map[string]*revenueGroup{}, map[string]*revenueGroup{}
+	add := func(m map[string]*revenueGroup, key string, p payment) {
+		g, ok := m[key]
+		if !ok {
+			g = &revenueGroup{Key: key}
+			m[key] = g
+		}
+		g.Invoices++
+		if strings.EqualFold(p.Status, "settled") {
+			g.Paid++
+			g.Sats += float64(p.msats()) / 1000
+		}
+	}
+
+	for _, p := range payments {
+		status := strings.ToLower(firstNonEmpty(p.Status, "unknown"))
+		switch status {
+		case "settled":
+			s.Paid++
+			s.Received += float64(p.msats()) / 1000
+		case "pending":
+			s.Outstanding += float64(p.msats()) / 1000
+		}
+		add(statuses, status, p)
+		if status != "settled" {
+			statuses[status].Sats += float64(p.msats()) / 1000
+		}
+		add(routes, firstNonEmpty(p.Route, "(unknown)"), p)
+		add(clients, p.clientLabel(), p)
+		if t, ok := p.at(); ok && status == "settled" {
+			add(series, bucket(t.UTC()), p)
+		}
+	}
+	if s.Paid > 0 {
+		s.Average = s.Received / float64(s.Paid)
+	}
+
+	s.Statuses = sortedGroups(statuses, 0)
+	s.Routes = sortedGroups(routes, top)
+	s.Clients = sortedGroups(clients, top)
+	s.Series = sortedGroups(series, 0)
+	sort.Slice(s.Series, func(i, j int) bool { return s.Series[i].Key < s.Series[j].Key })
+
+	if cv != nil {
+		v, err := cv.convert(s.Received, "SAT")
+		if err != nil {
+			return s, err
+		}
+		s.Converted = &v
+		s.Conversion = cv.meta()
+	}
+	return s, nil
+}
+
+// sortedGroups orders groups by sats received, then invoices, then key,
+// keeping the first top (0 for all)
+func sortedGroups(m map[string]*revenueGroup, top int) []revenueGroup {
+	out := make([]revenueGroup, 0, len(m))
+	for _, g := range m {
+		out = append(out, *g)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Sats != out[j].Sats {
+			return out[i].Sats > out[j].Sats
+		}
+		if out[i].Invoices != out[j].Invoices {
+			return out[i].Invoices > out[j].Invoices
+		}
+		return out[i].Key < out[j].Key
+	})
+	if top > 0 && len(out) > top {
+		out = out[:top]
+	}
+	return out
+}
+
+func formatBound(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func sats(v float64) string {
+	return money.Format(v, "SAT")
+}
+
+func printRevenue(out io.Writer, s revenueSummary, filter paymentFilter) {
+	fmt.Fprintln(out, "L402 Revenue")
+	fmt.Fprintln(out, "─────────────────────────────")
+	period := "all time"
+	if s.Since != "" || s.Until != "" {
+		period = firstNonEmpty(s.Since, "start") + " → " + firstNonEmpty(s.Until, "now")
+	}
+	fmt.Fprintf(out, "  Period:      %s\n", period)
+	if f := filter.String(); f != "" {
+		fmt.Fprintf(out, "  Filter:      %s\n", f)
+	}
+	if s.Invoices == 0 {
+		fmt.Fprintln(out, "\n  No invoices in this period.")
+		return
+	}
+	rate := float64(s.Paid) / float64(s.Invoices) * 100
+	fmt.Fprintf(out, "  Invoices:    %d issued, %d paid (%.1f%%)\n", s.Invoices, s.Paid, rate)
+	received := sats(s.Received)
+	if s.Converted != nil {
+		received += fmt.Sprintf(" (≈ %s)", money.Format(*s.Converted, s.Conversion["currency"].(string)))
+	}
+	fmt.Fprintf(out, "  Received:    %s\n", received)
+	if s.Paid > 0 {
+		fmt.Fprintf(out, "  Average:     %s per paid invoice\n", sats(s.Average))
+	}
+	if s.Outstanding > 0 {
+		fmt.Fprintf(out, "  Pending:     %s\n", sats(s.Outstanding))
+	}
+
+	fmt.Fprintln(out)
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "STATUS\tINVOICES\tAMOUNT")
+	fmt.Fprintln(w, "──────\t────────\t──────")
+	for _, g := range s.Statuses {
+		fmt.Fprintf(w, "%s\t%d\t%s\n", g.Key, g.Invoices, sats(g.Sats))
+	}
+	w.Flush()
+
+	printRevenueGroups(out, "ROUTE", s.Routes, s.Received)
+	printRevenueGroups(out, "CLIENT", s.Clients, s.Received)
+
+	if len(s.Series) > 0 {
+		values := make([]float64, len(s.Series))
+		for i, g := range s.Series {
+			values[i] = g.Sats
+		}
+		fmt.Fprintf(out, "\nSettled per %s  %s\n", s.Interval, sparkline(values))
+		w = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, strings.ToUpper(s.Interval)+"\tPAID\tRECEIVED")
+		fmt.Fprintln(w, "────\t────\t────────")
+		for _, g := range s.Series {
+			fmt.Fprintf(w, "%s\t%d\t%s\n", g.Key, g.Paid, sats(g.Sats))
+		}
+		w.Flush()
+	}
+}
+
+func printRevenueGroups(out io.Writer, title string, groups []revenueGroup, total float64) {
+	fmt.Fprintln(out)
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "%s\tINVOICES\tPAID\tRECEIVED\tSHARE\n", title)
+	fmt.Fprintf(w, "%s\t────────\t────\t────────\t─────\n", strings.Repeat("─", len(title)))
+	for _, g := range groups {
+		share := "—"
+		if total > 0 {
+			share = fmt.Sprintf("%.1f%%", g.Sats/total*100)
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%s\n", g.Key, g.Invoices, g.Paid, sats(g.Sats), share)
+	}
+	w.Flush()
+}
+
+// writePaymentsCSV writes one row per invoice for import into accounting
+func writePaymentsCSV(out io.Writer, payments []payment) error {
+	w := csv.NewWriter(out)
+	w.Write([]string{"created_at", "settled_at", "payment_hash", "route", "client", "client_name", "status", "amount_sats", "amount_msat"})
+	for _, p := range payments {
+		msat := p.msats()
+		w.Write([]string{
+			p.CreatedAt,
+			p.SettledAt,
+			p.PaymentHash,
+			p.Route,
+			p.Client,
+			p.ClientName,
+			p.Status,
+			strconv.FormatFloat(float64(msat)/1000, 'f', -1, 64),
+			strconv.FormatInt(msat, 10),
+		})
+	}
+	w.Flush()
+	return w.Error()
+}
@@ -10,41 +10,57 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var revokeSelector string
+
 var revokeCmd = &cobra.Command{
 	Use:   "revoke [token-id]",
 	Short: "Immediately revoke a capability token",
-	Long:  `Revoke a token, instantly killing the agent's access. This is irreversible.`,
-	Args:  cobra.ExactArgs(1),
+	Long: `Revoke a token, instantly killing the agent's access. This is irreversible.
+
+The token ID may be any unique prefix, such as the truncated IDs shown by
+'satgate tokens'. The full ID it resolves to is printed before confirming.
+
+With -l instead of an ID, every active token whose labels match is listed
+and revoked after a single confirmation.`,
+	Example: `  satgate revoke tok_abc1
+  satgate revoke -l team=support,env=staging --dry-run`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if revokeSelector != "" {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cfg := config.Get()
-		printTarget(cfg)
-
-		tokenID := args[0]
+		c, err := client.New()
+		if err != nil {
+			return err
+		}
 
-		if flagDry {
-			fmt.Fprintf(os.Stderr, "[DRY RUN] Would revoke token %s\n", tokenID)
-			return nil
+		if revokeSelector != "" {
+			sel, err := optionalSelector(revokeSelector)
+			if err != nil {
+				return err
+			}
+			return revokeSelected(c, sel)
 		}
 
-		// Try to get token name for confirmation message
-		c, err := client.New()
+		id, err := resolveTokenID(c, args[0])
 		if err != nil {
 			return err
 		}
+		printTarget(cfg, id)
+		tokenID := id.ID
 
-		// Try to get token name for confirmation
-		tokenName := tokenID
-		detailPath := "/admin/tokens/" + tokenID
-		if c.Surface() == "cloud" {
-			detailPath = "/cloud/delegation-v2/token/" + tokenID
+		if flagDry {
+			fmt.Fprintf(os.Stderr, "[DRY RUN] Would revoke token %s\n", id)
+			return nil
 		}
-		detailData, detailCode, _ := c.Get(detailPath)
-		if detailCode == 200 {
-			var detail map[string]interface{}
-			json.Unmarshal(detailData, &detail)
-			if name, ok := detail["name"].(string); ok && name != "" {
-				tokenName = fmt.Sprintf("%s (%s)", tokenID, name)
-			}
+
+		// Try to get token name for confirmation
+		tokenName := id.String()
+		if id.Name == "" {
+			tokenName = describeToken(c, tokenID)
 		}
 
 		if !confirmAction(fmt.Sprintf("⚠️  Revoke token %s?\n   This is immediate and irreversible. The agent will lose all access.", tokenName)) {
@@ -52,24 +68,11 @@ var revokeCmd = &cobra.Command{
 			return nil
 		}
 
-		var data []byte
-		var code int
-		if c.Surface() == "cloud" {
-			data, code, err = c.Post("/cloud/delegation-v2/revoke/"+tokenID, nil)
-		} else {
-			data, code, err = c.Delete("/admin/tokens/" + tokenID + "/revoke")
-		}
+		data, err := revokeToken(c, tokenID)
 		if err != nil {
 			return err
 		}
 
-		if code == 404 {
-			return fmt.Errorf("token %s not found", tokenID)
-		}
-		if code != 200 && code != 204 {
-			return fmt.Errorf("API returned HTTP %d: %s", code, string(data))
-		}
-
 		if flagJSON {
 			fmt.Println(string(data))
 			return nil
@@ -81,5 +84,96 @@ var revokeCmd = &cobra.Command{
 }
 
 func init() {
+	revokeCmd.Flags().StringVarP(&revokeSelector, "selector", "l", "", "revoke every active token with matching labels, e.g. team=support")
 	rootCmd.AddCommand(revokeCmd)
 }
+
+// revokeSelected revokes every active token matching sel after one
+// confirmation listing them all
+func revokeSelected(c *client.Client, sel labelSelector) error {
+	data, code, err := c.Get(tokensPath(c))
+	if err != nil {
+		return fmt.Errorf("cannot reach gateway at %s: %w", config.Get().Gateway, err)
+	}
+	if code != 200 {
+		return fmt.Errorf("API returned HTTP %d: %s", code, string(data))
+	}
+
+	var targets []tokenInfo
+	for _, t := range sel.selectTokens(parseTokens(data)) {
+		if t.Status == "" || t.Status == "active" {
+			targets = append(targets, t)
+		}
+	}
+
+	printTarget(config.Get())
+	if len(targets) == 0 {
+		fmt.Fprintf(os.Stderr, "No active tokens match %s.\n", sel)
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "%d active token(s) match %s:\n", len(targets), sel)
+	for _, t := range targets {
+		fmt.Fprintf(os.Stderr, "   %s (%s)  %s\n", t.ID, t.Name, formatLabels(t.Labels))
+	}
+
+	if flagDry {
+		fmt.Fprintf(os.Stderr, "[DRY RUN] Would revoke %d token(s)\n", len(targets))
+		return nil
+	}
+	if !confirmAction(fmt.Sprintf("⚠️  Revoke all %d tokens?\n   This is immediate and irreversible. The agents will lose all access.", len(targets))) {
+		fmt.Fprintln(os.Stderr, "Cancelled.")
+		return nil
+	}
+
+	var failed []string
+	for _, t := range targets {
+		if _, err := revokeToken(c, t.ID); err != nil {
+			fmt.Fprintf(os.Stderr, "✗ %s (%s): %v\n", t.ID, t.Name, err)
+			failed = append(failed, t.ID)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "✓ Token %s (%s) revoked.\n", t.ID, t.Name)
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d revocations failed", len(failed), len(targets))
+	}
+	return nil
+}
+
+// describeToken returns "id (name)" when the token's name can be looked up,
+// or just the ID otherwise
+func describeToken(c *client.Client, tokenID string) string {
+	data, code, _ := c.Get(tokenDetailPath(c, tokenID))
+	if code == 200 {
+		var detail map[string]interface{}
+		json.Unmarshal(data, &detail)
+		if name, ok := detail["name"].(string); ok && name != "" {
+			return fmt.Sprintf("%s (%s)", tokenID, name)
+		}
+	}
+	return tokenID
+}
+
+// revokeToken revokes a token on the client's surface. Callers are
+// responsible for printTarget and confirmAction beforehand.
+func revokeToken(c *client.Client, tokenID string) ([]byte, error) {
+	var data []byte
+	var code int
+	var err error
+	if c.Surface() == "cloud" {
+		data, code, err = c.Post("/cloud/delegation-v2/revoke/"+tokenID, nil)
+	} else {
+		data, code, err = c.Delete("/admin/tokens/" + tokenID + "/revoke")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if code == 404 {
+		return nil, fmt.Errorf("token %s not found", tokenID)
+	}
+	if code != 200 && code != 204 {
+		return nil, fmt.Errorf("API returned HTTP %d: %s", code, string(data))
+	}
+	return data, nil
+}
@@ -3,8 +3,15 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/SatGate-io/satgate-cli/internal/audit"
+	"github.com/SatGate-io/satgate-cli/internal/cassette"
+	"github.com/SatGate-io/satgate-cli/internal/client"
 	"github.com/SatGate-io/satgate-cli/internal/config"
+	"github.com/SatGate-io/satgate-cli/internal/telemetry"
 	"github.com/spf13/cobra"
 )
 
@@ -15,8 +22,20 @@ var (
 	flagJSON  bool
 	flagYes   bool
 	flagDry   bool
+	flagTrace bool
+
+	flagRecord string
+	flagReplay string
+
+	flagVerbose   bool
+	flagDebug     bool
+	flagPrintCurl bool
 )
 
+// clock is the time reports, relative times and default windows are based
+// on; tests pin it
+var clock = time.Now
+
 func SetVersionInfo(v, b string) {
 	version = v
 	buildTime = b
@@ -31,14 +50,106 @@ Mint tokens, track spend, revoke agents, and view security reports
 from the terminal. The server-side counterpart to lnget.
 
 They're the wallet. We're the register.`,
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// Flags and arguments have parsed by now; later errors are about
+		// the request, not how the command was invoked, so skip the usage
+		cmd.SilenceUsage = true
+
 		// Load config before every command
 		config.Load(cfgFile)
+
+		cfg := config.Get()
+		if err := startCassette(cmd, cfg); err != nil {
+			return err
+		}
+		client.SetDebug(debugOptions())
+		if flagTrace || cfg.Telemetry.Enabled {
+			telemetry.Init(telemetry.Options{
+				Endpoint:       cfg.Telemetry.Endpoint,
+				File:           cfg.Telemetry.File,
+				ServiceVersion: version,
+				RootName:       cmd.CommandPath(),
+			})
+		}
+
+		// A replay must not add the recorded mutations to the local audit log
+		if !cfg.Audit.Disabled && flagReplay == "" {
+			dir := cfg.Audit.Dir
+			if dir == "" {
+				dir = audit.DefaultDir()
+			}
+			profile := cfgFile
+			if profile == "" {
+				profile = "default"
+			}
+			audit.Begin(audit.Context{
+				Dir:     dir,
+				Profile: profile,
+				Gateway: cfg.Gateway,
+				Surface: cfg.Surface,
+				Tenant:  cfg.Tenant,
+				Command: cmd.CommandPath(),
+				Args:    os.Args[1:],
+			})
+		}
+		return nil
 	},
 }
 
+// startCassette begins --record or --replay. A replay takes the gateway,
+// surface and tenant from the cassette and runs at the time it was
+// recorded, so relative windows resolve to the recorded requests; no
+// credentials are needed.
+func startCassette(cmd *cobra.Command, cfg *config.Config) error {
+	switch {
+	case flagRecord != "" && flagReplay != "":
+		return fmt.Errorf("--record and --replay cannot be used together")
+	case flagReplay != "":
+		c, err := cassette.Replay(flagReplay)
+		if err != nil {
+			return err
+		}
+		cfg.Gateway, cfg.Surface, cfg.Tenant = c.Gateway, c.Surface, c.Tenant
+		cfg.AdminToken, cfg.BearerToken, cfg.SessionToken = cassette.Redacted, cassette.Redacted, ""
+		recordedAt := c.RecordedAt
+		clock = func() time.Time { return recordedAt }
+		fmt.Fprintf(os.Stderr, "📼 Replaying %s (%d exchange(s) recorded %s against %s)\n",
+			flagReplay, len(c.Interactions), recordedAt.Format(time.RFC3339), c.Gateway)
+	case flagRecord != "":
+		return cassette.Record(flagRecord, cassette.Meta{
+			CLIVersion: version,
+			Command:    cmd.CommandPath(),
+			Args:       audit.RedactArgs(os.Args[1:]),
+			Gateway:    cfg.Gateway,
+			Surface:    cfg.Surface,
+			Tenant:     cfg.Tenant,
+			Secrets:    []string{cfg.AdminToken, cfg.BearerToken, cfg.SessionToken},
+		})
+	}
+	return nil
+}
+
+// debugOptions maps --verbose, --debug and --print-curl to request logging
+func debugOptions() client.DebugOptions {
+	opts := client.DebugOptions{Curl: flagPrintCurl}
+	switch {
+	case flagDebug:
+		opts.Level = client.LogDebug
+	case flagVerbose:
+		opts.Level = client.LogVerbose
+	}
+	return opts
+}
+
 func Execute() error {
-	return rootCmd.Execute()
+	err := rootCmd.Execute()
+	if path, n := cassette.Stop(); path != "" {
+		fmt.Fprintf(os.Stderr, "📼 Recorded %d HTTP exchange(s) to %s (credentials and macaroons redacted)\n", n, path)
+	}
+	if terr := telemetry.Shutdown(err); terr != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  %v\n", terr)
+	}
+	return err
 }
 
 func init() {
@@ -46,15 +157,53 @@ func init() {
 	rootCmd.PersistentFlags().BoolVar(&flagJSON, "json", false, "output in JSON format")
 	rootCmd.PersistentFlags().BoolVar(&flagYes, "yes", false, "skip confirmation prompts")
 	rootCmd.PersistentFlags().BoolVar(&flagDry, "dry-run", false, "show what would happen without executing")
+	rootCmd.PersistentFlags().BoolVar(&flagTrace, "trace", false, "export OpenTelemetry spans for API calls (see telemetry in config)")
+	rootCmd.PersistentFlags().StringVar(&flagRecord, "record", "", "record every API exchange to this cassette file, with credentials redacted")
+	rootCmd.PersistentFlags().StringVar(&flagReplay, "replay", "", "serve API responses from a recorded cassette instead of the network")
+	rootCmd.PersistentFlags().BoolVarP(&flagVerbose, "verbose", "v", false, "log each API request's method, URL, status, latency and size to stderr")
+	rootCmd.PersistentFlags().BoolVar(&flagDebug, "debug", false, "like --verbose, plus redacted headers and bodies")
+	rootCmd.PersistentFlags().BoolVar(&flagPrintCurl, "print-curl", false, "print an equivalent curl command for each API request to stderr")
 }
 
-// printTarget prints the target gateway info before mutating commands
-func printTarget(cfg *config.Config) {
+// printTarget prints the target gateway info before mutating commands,
+// along with any token ID prefixes that were expanded
+func printTarget(cfg *config.Config, ids ...resolvedID) {
 	fmt.Fprintf(os.Stderr, "⚡ Target: %s (%s)", cfg.Gateway, cfg.Surface)
 	if cfg.Tenant != "" && cfg.Tenant != "default" {
 		fmt.Fprintf(os.Stderr, " tenant=%s", cfg.Tenant)
 	}
 	fmt.Fprintln(os.Stderr)
+	for _, id := range ids {
+		if id.resolved() {
+			fmt.Fprintf(os.Stderr, "   Resolved %s → %s\n", id.Input, id)
+		}
+	}
+}
+
+// parseDuration is time.ParseDuration with support for a day suffix ("30d")
+func parseDuration(s string) (time.Duration, error) {
+	if strings.HasSuffix(s, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(s, "d"))
+		if err != nil || days < 0 {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q (e.g. 30d, 24h)", s)
+	}
+	return d, nil
+}
+
+// parseTimestamp parses an RFC 3339 timestamp from the API, reporting
+// false for empty or malformed values
+func parseTimestamp(s string) (time.Time, bool) {
+	if s == "" {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	return t, err == nil
 }
 
 // confirmAction asks for y/N confirmation. Returns true if confirmed.
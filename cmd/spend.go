@@ -8,17 +8,33 @@ import (
 
 	"github.com/SatGate-io/satgate-cli/internal/client"
 	"github.com/SatGate-io/satgate-cli/internal/config"
+	"github.com/SatGate-io/satgate-cli/internal/money"
 	"github.com/spf13/cobra"
 )
 
 var (
-	spendAgent  string
-	spendPeriod string
+	spendAgent    string
+	spendPeriod   string
+	spendSelector string
 )
 
 var spendCmd = &cobra.Command{
 	Use:   "spend",
 	Short: "Show spend summary (org-wide or per-agent)",
+	Long: `Show spend summary (org-wide or per-agent).
+
+With -l the summary covers only tokens whose labels match the selector,
+computed from the token listing on either surface. On the cloud surface
+cost-center and department select on the token's costCenter and
+department fields.
+
+--currency converts every amount to one currency (USD, EUR, SAT, MSAT)
+using --rates, so sats and fiat budgets add up; the rates and their
+timestamp are printed with the result.`,
+	Example: `  satgate spend
+  satgate spend -l team=support
+  satgate spend -l cost-center=CC-100,env=prod --json
+  satgate spend -l team=support --currency USD --rates gateway`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cfg := config.Get()
 		c, err := client.New()
@@ -26,6 +42,21 @@ var spendCmd = &cobra.Command{
 			return err
 		}
 
+		sel, err := optionalSelector(spendSelector)
+		if err != nil {
+			return err
+		}
+		cv, err := newConverter(c)
+		if err != nil {
+			return err
+		}
+		if sel != nil && spendPeriod != "" {
+			return fmt.Errorf("--period cannot be combined with -l: label selection sums lifetime spend from the token listing")
+		}
+		if sel != nil {
+			return printSelectedSpend(c, sel, spendAgent, cv)
+		}
+
 		var path string
 		if c.Surface() == "cloud" {
 			path = "/cloud/delegation-v2/cost-rollups"
@@ -51,23 +82,21 @@ var spendCmd = &cobra.Command{
 			return fmt.Errorf("API returned HTTP %d: %s", code, string(data))
 		}
 
-		if flagJSON {
+		if flagJSON && cv == nil {
 			fmt.Println(string(data))
 			return nil
 		}
 
 		// Try cloud cost-rollups format first
-		var rollupResp struct {
-			Rollups []struct {
-				CostCenter     string  `json:"costCenter"`
-				Department     string  `json:"department"`
-				TotalAllocated float64 `json:"totalAllocated"`
-				TotalConsumed  float64 `json:"totalConsumed"`
-				TokenCount     int     `json:"tokenCount"`
-				PercentUsed    float64 `json:"percentUsed"`
-			} `json:"rollups"`
-		}
+		var rollupResp spendRollups
 		if err := json.Unmarshal(data, &rollupResp); err == nil && len(rollupResp.Rollups) > 0 {
+			if err := cv.rollups(&rollupResp); err != nil {
+				return err
+			}
+			if flagJSON {
+				printConverted(rollupResp, cv)
+				return nil
+			}
 			fmt.Println("Cost Center Spend")
 			fmt.Println("─────────────────────────────")
 
@@ -75,26 +104,25 @@ var spendCmd = &cobra.Command{
 			fmt.Fprintln(w, "COST CENTER\tDEPARTMENT\tCONSUMED\tALLOCATED\tUTILIZATION")
 			fmt.Fprintln(w, "───────────\t──────────\t────────\t─────────\t───────────")
 			for _, r := range rollupResp.Rollups {
-				consumed := fmt.Sprintf("$%.2f", r.TotalConsumed/100) // credits → dollars
-				allocated := fmt.Sprintf("$%.2f", r.TotalAllocated/100)
+				consumed, allocated := rollupResp.format(r.TotalConsumed), rollupResp.format(r.TotalAllocated)
 				util := fmt.Sprintf("%.1f%%", r.PercentUsed)
 				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", r.CostCenter, r.Department, consumed, allocated, util)
 			}
 			w.Flush()
+			cv.note(os.Stdout)
 			return nil
 		}
 
 		// Try admin format: org summary
-		var orgResp struct {
-			TotalAllocated float64 `json:"total_allocated"`
-			TotalConsumed  float64 `json:"total_consumed"`
-			Agents         []struct {
-				Name   string  `json:"name"`
-				Spent  float64 `json:"spent"`
-				Budget float64 `json:"budget"`
-			} `json:"agents"`
-		}
+		var orgResp spendSummary
 		if err := json.Unmarshal(data, &orgResp); err == nil && (orgResp.TotalAllocated > 0 || len(orgResp.Agents) > 0) {
+			if err := cv.summary(&orgResp); err != nil {
+				return err
+			}
+			if flagJSON {
+				printConverted(orgResp, cv)
+				return nil
+			}
 			fmt.Println("Spend Summary")
 			fmt.Println("─────────────────────────────")
 			if orgResp.TotalAllocated > 0 {
@@ -102,8 +130,8 @@ var spendCmd = &cobra.Command{
 				if orgResp.TotalAllocated > 0 {
 					pct = (orgResp.TotalConsumed / orgResp.TotalAllocated) * 100
 				}
-				fmt.Printf("  Allocated:  $%.2f\n", orgResp.TotalAllocated)
-				fmt.Printf("  Consumed:   $%.2f (%.1f%%)\n", orgResp.TotalConsumed, pct)
+				fmt.Printf("  Allocated:  %s\n", money.Format(orgResp.TotalAllocated, orgResp.Currency))
+				fmt.Printf("  Consumed:   %s (%.1f%%)\n", money.Format(orgResp.TotalConsumed, orgResp.Currency), pct)
 				fmt.Println()
 			}
 
@@ -116,20 +144,29 @@ var spendCmd = &cobra.Command{
 					if a.Budget > 0 {
 						util = fmt.Sprintf("%.1f%%", (a.Spent/a.Budget)*100)
 					}
+					cur := firstNonEmpty(a.Currency, orgResp.Currency)
 					budget := "unlimited"
 					if a.Budget > 0 {
-						budget = fmt.Sprintf("$%.2f", a.Budget)
+						budget = money.Format(a.Budget, cur)
 					}
-					fmt.Fprintf(w, "%s\t$%.2f\t%s\t%s\n", a.Name, a.Spent, budget, util)
+					fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", a.Name, money.Format(a.Spent, cur), budget, util)
 				}
 				w.Flush()
 			}
+			cv.note(os.Stdout)
+			return nil
+		}
+
+		if flagJSON {
+			fmt.Println(string(data))
 			return nil
 		}
 
 		// Fallback: just pretty-print whatever we got
 		var raw interface{}
-		json.Unmarshal(data, &raw)
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return fmt.Errorf("gateway returned invalid JSON: %w", err)
+		}
 		out, _ := json.MarshalIndent(raw, "", "  ")
 		fmt.Println(string(out))
 		return nil
@@ -139,5 +176,217 @@ var spendCmd = &cobra.Command{
 func init() {
 	spendCmd.Flags().StringVar(&spendAgent, "agent", "", "filter by agent name")
 	spendCmd.Flags().StringVar(&spendPeriod, "period", "", "time period (e.g. 7d, 30d)")
+	spendCmd.Flags().StringVarP(&spendSelector, "selector", "l", "", "only tokens with matching labels, e.g. team=support,env=prod")
+	addCurrencyFlags(spendCmd.Flags())
 	rootCmd.AddCommand(spendCmd)
 }
+
+// spendRollups is the cloud cost-rollups response. Amounts are credits
+// (cents) until converted, then display units of Currency.
+type spendRollups struct {
+	Currency string `json:"currency,omitempty"`
+	Rollups  []struct {
+		CostCenter     string  `json:"costCenter"`
+		Department     string  `json:"department"`
+		TotalAllocated float64 `json:"totalAllocated"`
+		TotalConsumed  float64 `json:"totalConsumed"`
+		TokenCount     int     `json:"tokenCount"`
+		PercentUsed    float64 `json:"percentUsed"`
+	} `json:"rollups"`
+}
+
+// spendSummary is the gateway /admin/spend org summary. Amounts are in
+// Currency, USD when blank.
+type spendSummary struct {
+	TotalAllocated float64 `json:"total_allocated"`
+	TotalConsumed  float64 `json:"total_consumed"`
+	Currency       string  `json:"currency"`
+	Agents         []struct {
+		Name     string  `json:"name"`
+		Spent    float64 `json:"spent"`
+		Budget   float64 `json:"budget"`
+		Currency string  `json:"currency"`
+	} `json:"agents"`
+}
+
+func (r spendRollups) format(v float64) string {
+	if r.Currency == "" {
+		return money.FromCredits(v).String()
+	}
+	return money.Format(v, r.Currency)
+}
+
+// rollups converts cost-rollup credits to --currency
+func (cv *converter) rollups(r *spendRollups) error {
+	if cv == nil {
+		return nil
+	}
+	for i := range r.Rollups {
+		ru := &r.Rollups[i]
+		var err error
+		if ru.TotalAllocated, err = cv.convert(money.FromCredits(ru.TotalAllocated).Float(), "USD"); err != nil {
+			return err
+		}
+		if ru.TotalConsumed, err = cv.convert(money.FromCredits(ru.TotalConsumed).Float(), "USD"); err != nil {
+			return err
+		}
+	}
+	r.Currency = cv.to.Code
+	return nil
+}
+
+// summary converts the org spend summary to --currency
+func (cv *converter) summary(s *spendSummary) error {
+	if cv == nil {
+		return nil
+	}
+	var err error
+	if s.TotalAllocated, err = cv.convert(s.TotalAllocated, s.Currency); err != nil {
+		return err
+	}
+	if s.TotalConsumed, err = cv.convert(s.TotalConsumed, s.Currency); err != nil {
+		return err
+	}
+	for i := range s.Agents {
+		a := &s.Agents[i]
+		cur := firstNonEmpty(a.Currency, s.Currency)
+		if a.Spent, err = cv.convert(a.Spent, cur); err != nil {
+			return err
+		}
+		if a.Budget, err = cv.convert(a.Budget, cur); err != nil {
+			return err
+		}
+		a.Currency = cv.to.Code
+	}
+	s.Currency = cv.to.Code
+	return nil
+}
+
+// printConverted prints a converted response with the rates used
+func printConverted(v interface{}, cv *converter) {
+	var m map[string]interface{}
+	data, _ := json.Marshal(v)
+	json.Unmarshal(data, &m)
+	m["conversion"] = cv.meta()
+	out, _ := json.MarshalIndent(m, "", "  ")
+	fmt.Println(string(out))
+}
+
+// printSelectedSpend summarizes spend across the tokens matching sel and,
+// when agent is set, named agent
+func printSelectedSpend(c *client.Client, sel labelSelector, agent string, cv *converter) error {
+	data, code, err := c.Get(tokensPath(c))
+	if err != nil {
+		return fmt.Errorf("cannot reach gateway at %s: %w", config.Get().Gateway, err)
+	}
+	if code != 200 {
+		return fmt.Errorf("API returned HTTP %d: %s", code, string(data))
+	}
+	tokens := sel.selectTokens(parseTokens(data))
+	if agent != "" {
+		var named []tokenInfo
+		for _, t := range tokens {
+			if t.Name == agent {
+				named = append(named, t)
+			}
+		}
+		tokens = named
+	}
+	tokens, err = cv.tokens(tokens)
+	if err != nil {
+		return err
+	}
+
+	// Children carve their budget out of their parent's, so only budgets
+	// of tokens whose parent is not also selected count as allocated.
+	// Totals are kept per currency.
+	selected := map[string]bool{}
+	for _, t := range tokens {
+		selected[t.ID] = true
+	}
+	spent, budget := money.Totals{}, money.Totals{}
+	unlimited := 0
+	for _, t := range tokens {
+		spent.Add(t.Spent, t.Currency)
+		switch {
+		case selected[t.ParentID]:
+		case t.Budget > 0:
+			budget.Add(t.Budget, t.Currency)
+		default:
+			unlimited++
+		}
+	}
+
+	if flagJSON {
+		// Totals stay plain numbers in the usual single-currency case and
+		// become per-currency objects only when currencies are mixed
+		result := map[string]interface{}{
+			"selector":        sel.String(),
+			"tokens":          tokens,
+			"total_consumed":  spent,
+			"total_allocated": budget,
+			"unlimited":       unlimited,
+		}
+		all := money.Totals{}
+		for code := range spent {
+			all.Add(0, code)
+		}
+		for code := range budget {
+			all.Add(0, code)
+		}
+		if _, code, ok := all.Single(); ok {
+			result["currency"] = code
+			result["total_consumed"] = spent[code]
+			result["total_allocated"] = budget[code]
+		}
+		if agent != "" {
+			result["agent"] = agent
+		}
+		if cv != nil {
+			result["conversion"] = cv.meta()
+		}
+		out, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(out))
+		return nil
+	}
+
+	fmt.Printf("Spend for %s", sel)
+	if agent != "" {
+		fmt.Printf(", agent %s", agent)
+	}
+	fmt.Println()
+	fmt.Println("─────────────────────────────")
+	if len(tokens) == 0 {
+		fmt.Println("  No tokens match.")
+		return nil
+	}
+	fmt.Printf("  Tokens:     %d", len(tokens))
+	if unlimited > 0 {
+		fmt.Printf(" (%d unlimited)", unlimited)
+	}
+	fmt.Println()
+	fmt.Printf("  Allocated:  %s\n", budget)
+	b, bcur, oneBudget := budget.Single()
+	s, scur, oneSpent := spent.Single()
+	if oneBudget && oneSpent && bcur == scur && b > 0 {
+		fmt.Printf("  Consumed:   %s (%.1f%%)\n", spent, s/b*100)
+	} else {
+		fmt.Printf("  Consumed:   %s\n", spent)
+	}
+	fmt.Println()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "AGENT\tSPENT\tBUDGET\tUTILIZATION\tLABELS")
+	fmt.Fprintln(w, "─────\t─────\t──────\t───────────\t──────")
+	for _, t := range tokens {
+		util, limit := "—", "unlimited"
+		if t.Budget > 0 {
+			util = fmt.Sprintf("%.1f%%", t.Spent/t.Budget*100)
+			limit = money.Format(t.Budget, t.Currency)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", t.Name, money.Format(t.Spent, t.Currency), limit, util, labelColumn(t.Labels))
+	}
+	w.Flush()
+	cv.note(os.Stdout)
+	return nil
+}
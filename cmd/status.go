@@ -20,11 +20,7 @@ var statusCmd = &cobra.Command{
 			return err
 		}
 
-		path := "/admin/ping"
-		if c.Surface() == "cloud" {
-			path = "/healthz"
-		}
-		data, code, err := c.Get(path)
+		data, code, err := c.Get(healthPath(c))
 		if err != nil {
 			return fmt.Errorf("cannot reach gateway at %s: %w", cfg.Gateway, err)
 		}
@@ -78,3 +74,11 @@ var statusCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(statusCmd)
 }
+
+// healthPath returns the liveness endpoint for the client's surface
+func healthPath(c *client.Client) string {
+	if c.Surface() == "cloud" {
+		return "/healthz"
+	}
+	return "/admin/ping"
+}
@@ -0,0 +1,265 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/SatGate-io/satgate-cli/internal/config"
+	"github.com/SatGate-io/satgate-cli/internal/templates"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	templateDescription string
+	templateName        string
+	templateBudget      string
+	templateCurrency    string
+	templateExpiry      string
+	templateRoutes      string
+	templateLabels      []string
+	templateParent      string
+	templateForce       bool
+)
+
+var templateCmd = &cobra.Command{
+	Use:   "template",
+	Short: "Manage named mint templates (satgate mint --template <name>)",
+	Long: `Templates are named mint defaults: budget, currency, expiry, routes,
+labels and parent. They live in the templates section of the config file
+or as one YAML file per template in templates_dir (default
+~/.satgate/templates); a file overrides a config entry of the same name.
+
+String fields may use variables: {{.Agent}} is the --agent value and
+--var team=support makes {{.Team}} available; the first letter is
+upper-cased and names may only use letters, digits and '_'. Labels given
+with --label on mint are also available as variables, except those whose
+keys are not valid names (e.g. cost-center).
+
+  # ~/.satgate/templates/support-bot.yaml
+  name: "{{.Team}}-{{.Agent}}"
+  budget: 50
+  expiry: 30d
+  routes: ["/api/openai/*"]
+  labels: {team: "{{.Team}}", env: prod}`,
+}
+
+var templateListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List available templates",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		all, err := loadTemplates()
+		if err != nil {
+			return err
+		}
+
+		if flagJSON {
+			out, _ := json.MarshalIndent(all, "", "  ")
+			fmt.Println(string(out))
+			return nil
+		}
+		if len(all) == 0 {
+			fmt.Fprintf(os.Stderr, "No templates. Create one with 'satgate template create' or add files to %s\n", templatesDir())
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tBUDGET\tEXPIRY\tROUTES\tLABELS\tSOURCE")
+		fmt.Fprintln(w, "────\t──────\t──────\t──────\t──────\t──────")
+		for _, name := range templates.Names(all) {
+			t := all[name]
+			budget := "—"
+			if b, err := parseBudget(t.Budget, t.Currency); err == nil && !b.IsZero() {
+				budget = b.String()
+			} else if err != nil {
+				budget = t.Budget + " " + t.Currency
+			}
+			routes := "*"
+			if len(t.Routes) > 0 {
+				routes = strings.Join(t.Routes, ",")
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", name, budget, firstNonEmpty(t.Expiry, "—"), truncate(routes, 30), labelColumn(t.Labels), t.Source)
+		}
+		w.Flush()
+		return nil
+	},
+}
+
+var templateShowCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Show a template's definition",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		t, err := findTemplate(args[0])
+		if err != nil {
+			return err
+		}
+
+		if flagJSON {
+			out, _ := json.MarshalIndent(t, "", "  ")
+			fmt.Println(string(out))
+			return nil
+		}
+		out, _ := yaml.Marshal(t)
+		fmt.Printf("# %s (%s)\n%s", args[0], t.Source, string(out))
+		return nil
+	},
+}
+
+var templateCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a template file in the templates directory",
+	Example: `  satgate template create support-bot --name '{{.Team}}-{{.Agent}}' \
+    --budget 50 --expiry 30d --routes '/api/openai/*' \
+    --label 'team={{.Team}}' --label env=prod`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		labels, err := parseLabels(templateLabels)
+		if err != nil {
+			return fmt.Errorf("--label: %w", err)
+		}
+		t := templates.Template{
+			Description: templateDescription,
+			Name:        templateName,
+			Budget:      templateBudget,
+			Currency:    templateCurrency,
+			Expiry:      templateExpiry,
+			Routes:      splitRoutes(templateRoutes),
+			Parent:      templateParent,
+		}
+		if len(labels) > 0 {
+			t.Labels = labels
+		}
+		if !strings.Contains(t.Budget+t.Currency, "{{") {
+			if _, err := parseBudget(t.Budget, t.Currency); err != nil {
+				return fmt.Errorf("--budget: %w", err)
+			}
+		}
+		if t.Expiry != "" && !strings.Contains(t.Expiry, "{{") {
+			if _, err := parseDuration(t.Expiry); err != nil {
+				return fmt.Errorf("--expiry: %w", err)
+			}
+		}
+		if err := t.Validate(); err != nil {
+			return err
+		}
+
+		path, err := templates.Save(templatesDir(), args[0], t, templateForce)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "✓ Created template %s at %s\n", args[0], path)
+		return nil
+	},
+}
+
+func init() {
+	f := templateCreateCmd.Flags()
+	f.StringVar(&templateDescription, "description", "", "what the template is for")
+	f.StringVar(&templateName, "name", "", "token name, e.g. '{{.Team}}-{{.Agent}}' (default: the --agent given to mint)")
+	f.StringVar(&templateBudget, "budget", "", "budget ceiling, e.g. 50, 0.29 or 1500sats")
+	f.StringVar(&templateCurrency, "currency", "", "budget currency")
+	f.StringVar(&templateExpiry, "expiry", "", "token expiry (e.g. 30d, 24h)")
+	f.StringVar(&templateRoutes, "routes", "", "allowed routes (comma-separated)")
+	f.StringArrayVar(&templateLabels, "label", nil, "label as key=value (repeatable)")
+	f.StringVar(&templateParent, "parent", "", "parent token ID or unique prefix")
+	f.BoolVar(&templateForce, "force", false, "replace an existing template file")
+
+	templateCmd.AddCommand(templateListCmd)
+	templateCmd.AddCommand(templateShowCmd)
+	templateCmd.AddCommand(templateCreateCmd)
+	rootCmd.AddCommand(templateCmd)
+}
+
+// templatesDir returns the configured templates directory
+func templatesDir() string {
+	if dir := config.Get().TemplatesDir; dir != "" {
+		return dir
+	}
+	return templates.DefaultDir()
+}
+
+func loadTemplates() (map[string]templates.Template, error) {
+	return templates.Load(config.Get().Templates, templatesDir())
+}
+
+func findTemplate(name string) (templates.Template, error) {
+	all, err := loadTemplates()
+	if err != nil {
+		return templates.Template{}, err
+	}
+	t, ok := all[name]
+	if !ok {
+		names := templates.Names(all)
+		if len(names) == 0 {
+			return t, fmt.Errorf("template %q not found (no templates defined)", name)
+		}
+		return t, fmt.Errorf("template %q not found (available: %s)", name, strings.Join(names, ", "))
+	}
+	return t, nil
+}
+
+// applyMintTemplate renders --template with --var, --label and --agent as
+// variables and fills in every mint flag that was not given explicitly.
+// Template labels are returned merged under the explicit ones.
+func applyMintTemplate(cmd *cobra.Command, labels map[string]string) (map[string]string, error) {
+	t, err := findTemplate(mintTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	pairs := map[string]string{}
+	for k, v := range labels {
+		pairs[k] = v
+	}
+	for _, kv := range mintVars {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok || strings.TrimSpace(k) == "" {
+			return nil, fmt.Errorf("--var %q: expected key=value", kv)
+		}
+		k = strings.TrimSpace(k)
+		if !templates.ValidVar(k) {
+			return nil, fmt.Errorf("--var %q: %s is not a valid variable name (letters, digits and '_', e.g. team_name)", kv, k)
+		}
+		pairs[k] = v
+	}
+	t, err = t.Render(templates.Vars(mintAgent, pairs))
+	if err != nil {
+		return nil, fmt.Errorf("template %s: %w", mintTemplate, err)
+	}
+
+	flags := cmd.Flags()
+	if t.Name != "" {
+		mintAgent = t.Name
+	}
+	if t.Budget != "" && !flags.Changed("budget") {
+		mintBudget = t.Budget
+	}
+	if t.Currency != "" && !flags.Changed("currency") {
+		mintCurrency = t.Currency
+	}
+	if t.Expiry != "" && !flags.Changed("expiry") {
+		mintExpiry = t.Expiry
+	}
+	if len(t.Routes) > 0 && !flags.Changed("routes") {
+		mintRoutes = strings.Join(t.Routes, ",")
+	}
+	if t.Parent != "" && !flags.Changed("parent") {
+		mintParent = t.Parent
+	}
+
+	merged := map[string]string{}
+	for k, v := range t.Labels {
+		if !labelKeyPattern.MatchString(k) {
+			return nil, fmt.Errorf("template %s: invalid label key %q", mintTemplate, k)
+		}
+		merged[k] = v
+	}
+	for k, v := range labels {
+		merged[k] = v
+	}
+	return merged, nil
+}
@@ -0,0 +1,641 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/SatGate-io/satgate-cli/internal/client"
+	"github.com/SatGate-io/satgate-cli/internal/money"
+	"github.com/spf13/cobra"
+)
+
+var tokenDays int
+
+var tokenCmd = &cobra.Command{
+	Use:   "token [id]",
+	Short: "Show token detail: caveats, delegation chain, spend history",
+	Long: `Show a token's detail page: its ancestry with each ancestor's remaining
+budget, caveats in plain language, spend broken down by route, a daily
+spend sparkline, when and where it was last seen, and its child tokens.
+
+Amounts are in each token's own currency, with cloud credits shown as
+dollars. Ancestry and children come from the token listing; usage comes from the token's usage endpoint when the gateway
+provides one. --json prints the same normalized view.
+
+The ID may be any unique prefix, including a truncated ID from the table.`,
+	Example: `  satgate token tok_abc123
+  satgate token tok_abc1
+  satgate token tok_abc123 --days 7
+  satgate token tok_abc123 --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := client.New()
+		if err != nil {
+			return err
+		}
+
+		id, err := resolveTokenID(c, args[0])
+		if err != nil {
+			return err
+		}
+		data, code, err := c.Get(tokenDetailPath(c, id.ID))
+		if err != nil {
+			return err
+		}
+		if code == 404 {
+			return fmt.Errorf("token %s not found", id.ID)
+		}
+		if code != 200 {
+			return fmt.Errorf("API returned HTTP %d: %s", code, string(data))
+		}
+
+		d := parseTokenDetail(data)
+		if d.ID == "" {
+			d.ID = id.ID
+		}
+		loadTokenUsage(c, &d, tokenDays)
+		loadTokenFamily(c, &d)
+
+		if flagJSON {
+			out, _ := json.MarshalIndent(d, "", "  ")
+			fmt.Println(string(out))
+			return nil
+		}
+
+		printTokenDetail(d, clock())
+		return nil
+	},
+}
+
+func init() {
+	tokenCmd.Flags().IntVar(&tokenDays, "days", 30, "days of spend history to show")
+}
+
+// tokenDetail is the normalized detail view of one token, in display units
+// of its currency
+type tokenDetail struct {
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`
+	Status     string            `json:"status"`
+	Spent      float64           `json:"spent"`
+	Budget     float64           `json:"budget"` // 0 = unlimited
+	Currency   string            `json:"currency"`
+	CreatedAt  string            `json:"created_at,omitempty"`
+	ExpiresAt  string            `json:"expires_at,omitempty"`
+	RevokedAt  string            `json:"revoked_at,omitempty"`
+	ParentID   string            `json:"parent_id,omitempty"`
+	Routes     []string          `json:"routes,omitempty"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	Caveats    []string          `json:"caveats,omitempty"`
+	LastSeenAt string            `json:"last_seen_at,omitempty"`
+	LastSeenIP string            `json:"last_seen_ip,omitempty"`
+
+	RouteSpend []routeSpend   `json:"route_spend,omitempty"`
+	History    []dailySpend   `json:"history,omitempty"`
+	Ancestry   []tokenSummary `json:"ancestry,omitempty"` // root first
+	Children   []tokenSummary `json:"children,omitempty"`
+}
+
+type routeSpend struct {
+	Route    string  `json:"route"`
+	Spent    float64 `json:"spent"`
+	Requests int64   `json:"requests,omitempty"`
+}
+
+type dailySpend struct {
+	Date  string  `json:"date"`
+	Spent float64 `json:"spent"`
+}
+
+// tokenSummary is an ancestor or child as shown on the detail page.
+// Remaining is the budget left after the token's whole subtree's spend,
+// or -1 when the token is unlimited.
+type tokenSummary struct {
+	ID        string  `json:"id"`
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	Spent     float64 `json:"spent"`
+	Budget    float64 `json:"budget"`
+	Currency  string  `json:"currency"`
+	Remaining float64 `json:"remaining"`
+}
+
+// rawTokenDetail covers the field names used by both surfaces. Amounts
+// ending in _credits are cloud cents.
+type rawTokenDetail struct {
+	tokenInfo
+	Caveats         []json.RawMessage `json:"caveats"`
+	LastSeenAt      string            `json:"last_seen_at"`
+	LastSeenIP      string            `json:"last_seen_ip"`
+	LastUsedIP      string            `json:"last_used_ip"`
+	DelegationChain []json.RawMessage `json:"delegation_chain"`
+	tokenUsage
+}
+
+// tokenUsage is the usage payload, either embedded in the detail response
+// or served separately
+type tokenUsage struct {
+	SpendByRoute json.RawMessage `json:"spend_by_route"`
+	RouteSpend   json.RawMessage `json:"route_spend"`
+	SpendHistory json.RawMessage `json:"spend_history"`
+	DailySpend   json.RawMessage `json:"daily_spend"`
+}
+
+func parseTokenDetail(data []byte) tokenDetail {
+	var raw rawTokenDetail
+	json.Unmarshal(data, &raw)
+	// Some gateways wrap the detail: {"token": {...}}
+	if raw.ID == "" {
+		var wrapped struct {
+			Token json.RawMessage `json:"token"`
+		}
+		if json.Unmarshal(data, &wrapped) == nil && len(wrapped.Token) > 0 {
+			json.Unmarshal(wrapped.Token, &raw)
+		}
+	}
+
+	t := creditsToDollars(normalizeTokens([]tokenInfo{raw.tokenInfo})[0])
+
+	d := tokenDetail{
+		ID:         t.ID,
+		Name:       t.Name,
+		Status:     t.Status,
+		Spent:      t.Spent,
+		Budget:     t.Budget,
+		Currency:   currencyCode(t.Currency),
+		CreatedAt:  t.CreatedAt,
+		ExpiresAt:  t.ExpiresAt,
+		RevokedAt:  t.RevokedAt,
+		ParentID:   t.ParentID,
+		Routes:     t.Routes,
+		Labels:     t.Labels,
+		LastSeenAt: firstNonEmpty(raw.LastSeenAt, t.LastUsedAt),
+		LastSeenIP: firstNonEmpty(raw.LastSeenIP, raw.LastUsedIP),
+	}
+	for _, c := range raw.Caveats {
+		if s := describeCaveat(c, d.Currency); s != "" {
+			d.Caveats = append(d.Caveats, s)
+		}
+	}
+	// Without the listing, the chain embedded in the detail is the best
+	// ancestry available; loadTokenFamily replaces it when it can.
+	for _, link := range raw.DelegationChain {
+		var info tokenInfo
+		if json.Unmarshal(link, &info.ID) != nil {
+			json.Unmarshal(link, &info)
+			info = creditsToDollars(info)
+		}
+		if info.ID != "" && info.ID != d.ID {
+			d.Ancestry = append(d.Ancestry, summarizeToken(info, info.Spent))
+		}
+	}
+	applyTokenUsage(&d, raw.tokenUsage)
+	return d
+}
+
+// loadTokenUsage fills route spend and history from the usage endpoint when
+// the detail response did not include them. Gateways without the endpoint
+// simply leave those sections empty.
+func loadTokenUsage(c *client.Client, d *tokenDetail, days int) {
+	if len(d.RouteSpend) > 0 && len(d.History) > 0 {
+		return
+	}
+	path := tokenDetailPath(c, d.ID) + "/usage"
+	if days > 0 {
+		path += "?" + url.Values{"days": {strconv.Itoa(days)}}.Encode()
+	}
+	data, code, err := c.Get(path)
+	if err != nil || code != 200 {
+		return
+	}
+	var u tokenUsage
+	if json.Unmarshal(data, &u) == nil {
+		applyTokenUsage(d, u)
+	}
+	if days > 0 && len(d.History) > days {
+		d.History = d.History[len(d.History)-days:]
+	}
+}
+
+func applyTokenUsage(d *tokenDetail, u tokenUsage) {
+	if len(d.RouteSpend) == 0 {
+		d.RouteSpend = parseRouteSpend(firstRaw(u.SpendByRoute, u.RouteSpend))
+	}
+	if len(d.History) == 0 {
+		d.History = parseDailySpend(firstRaw(u.SpendHistory, u.DailySpend))
+	}
+}
+
+// loadTokenFamily derives ancestry and children from the token listing, so
+// remaining budgets account for spend delegated further down each subtree
+func loadTokenFamily(c *client.Client, d *tokenDetail) {
+	data, code, err := c.Get(tokensPath(c))
+	if err != nil || code != 200 {
+		return
+	}
+	roots := buildTokenTree(parseTokens(data))
+	node := findTokenNode(roots, d.ID)
+	if node == nil {
+		return
+	}
+
+	nodes := map[string]*tokenNode{}
+	var index func([]*tokenNode)
+	index = func(ns []*tokenNode) {
+		for _, n := range ns {
+			nodes[n.Token.ID] = n
+			index(n.Children)
+		}
+	}
+	index(roots)
+
+	var ancestry []tokenSummary
+	for id := node.Token.ParentID; id != "" && len(ancestry) < len(nodes); {
+		p, ok := nodes[id]
+		if !ok {
+			break
+		}
+		ancestry = append([]tokenSummary{summarizeToken(p.Token, p.SubtreeSpent)}, ancestry...)
+		id = p.Token.ParentID
+	}
+	if len(ancestry) > 0 || node.Token.ParentID == "" {
+		d.Ancestry = ancestry
+	}
+	if d.ParentID == "" {
+		d.ParentID = node.Token.ParentID
+	}
+
+	d.Children = nil
+	for _, ch := range node.Children {
+		d.Children = append(d.Children, summarizeToken(ch.Token, ch.SubtreeSpent))
+	}
+}
+
+// currencyCode normalizes an API currency, defaulting to USD
+func currencyCode(code string) string {
+	if c, err := money.Lookup(code); err == nil {
+		return c.Code
+	}
+	return strings.ToUpper(code)
+}
+
+// creditsToDollars fills dollar amounts from cloud credit fields
+func creditsToDollars(t tokenInfo) tokenInfo {
+	if t.BudgetLim > 0 && t.Budget == 0 {
+		t.Budget = t.BudgetLim / 100
+	}
+	if t.BudgetSp > 0 && t.Spent == 0 {
+		t.Spent = t.BudgetSp / 100
+	}
+	return t
+}
+
+func summarizeToken(t tokenInfo, subtreeSpent float64) tokenSummary {
+	return tokenSummary{
+		ID:        t.ID,
+		Name:      t.Name,
+		Status:    t.Status,
+		Spent:     subtreeSpent,
+		Budget:    t.Budget,
+		Currency:  currencyCode(t.Currency),
+		Remaining: remainingBudget(t.Budget, subtreeSpent),
+	}
+}
+
+func remainingBudget(budget, spent float64) float64 {
+	if budget <= 0 {
+		return -1
+	}
+	return math.Max(budget-spent, 0)
+}
+
+// parseRouteSpend accepts {"route": amount} or a list of objects with a
+// route/path and spent/credits, highest spend first
+func parseRouteSpend(data json.RawMessage) []routeSpend {
+	var out []routeSpend
+	var byRoute map[string]float64
+	if json.Unmarshal(data, &byRoute) == nil {
+		for route, spent := range byRoute {
+			out = append(out, routeSpend{Route: route, Spent: spent})
+		}
+	} else {
+		var rows []struct {
+			Route    string  `json:"route"`
+			Path     string  `json:"path"`
+			Spent    float64 `json:"spent"`
+			Credits  float64 `json:"credits"`
+			Requests int64   `json:"requests"`
+		}
+		json.Unmarshal(data, &rows)
+		for _, r := range rows {
+			spent := r.Spent
+			if spent == 0 && r.Credits > 0 {
+				spent = r.Credits / 100
+			}
+			out = append(out, routeSpend{Route: firstNonEmpty(r.Route, r.Path), Spent: spent, Requests: r.Requests})
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Spent != out[j].Spent {
+			return out[i].Spent > out[j].Spent
+		}
+		return out[i].Route < out[j].Route
+	})
+	return out
+}
+
+// parseDailySpend accepts {"2026-10-01": amount} or a list of
+// {date, spent|credits}, oldest first
+func parseDailySpend(data json.RawMessage) []dailySpend {
+	var out []dailySpend
+	var byDate map[string]float64
+	if json.Unmarshal(data, &byDate) == nil {
+		for date, spent := range byDate {
+			out = append(out, dailySpend{Date: date, Spent: spent})
+		}
+	} else {
+		var rows []struct {
+			Date    string  `json:"date"`
+			Day     string  `json:"day"`
+			Spent   float64 `json:"spent"`
+			Credits float64 `json:"credits"`
+		}
+		json.Unmarshal(data, &rows)
+		for _, r := range rows {
+			spent := r.Spent
+			if spent == 0 && r.Credits > 0 {
+				spent = r.Credits / 100
+			}
+			out = append(out, dailySpend{Date: firstNonEmpty(r.Date, r.Day), Spent: spent})
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Date < out[j].Date })
+	return out
+}
+
+// describeCaveat turns a macaroon caveat, either a "key op value" string or
+// a {type|key, op, value} object, into plain language. A plain budget is in
+// the token's currency.
+func describeCaveat(raw json.RawMessage, currency string) string {
+	var key, op, value string
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		key, op, value = splitCaveat(s)
+		if key == "" {
+			return s
+		}
+	} else {
+		var obj struct {
+			Type      string      `json:"type"`
+			Key       string      `json:"key"`
+			Condition string      `json:"condition"`
+			Op        string      `json:"op"`
+			Value     interface{} `json:"value"`
+		}
+		if json.Unmarshal(raw, &obj) != nil {
+			return string(raw)
+		}
+		key, op, value = firstNonEmpty(obj.Type, obj.Key, obj.Condition), obj.Op, fmt.Sprint(obj.Value)
+		if v, ok := obj.Value.(float64); ok {
+			value = strconv.FormatFloat(v, 'f', -1, 64) // not 5e+06
+		}
+		if op == "" {
+			op = "="
+		}
+	}
+
+	switch strings.ToLower(strings.ReplaceAll(key, "-", "_")) {
+	case "time", "expires", "expires_at", "expiry", "before":
+		if t, ok := parseTimestamp(value); ok {
+			return "Expires " + t.Local().Format("2006-01-02 15:04 MST")
+		}
+		return "Expires " + value
+	case "route", "routes", "path", "paths":
+		return "Only routes " + strings.Join(splitRoutes(value), ", ")
+	case "method", "methods":
+		return "Only HTTP methods " + strings.ToUpper(value)
+	case "budget", "max_spend":
+		if c, err := money.Lookup(currency); err == nil {
+			if a, err := money.Parse(value, c); err == nil {
+				return "Spend capped at " + a.String()
+			}
+		}
+		return "Spend capped at " + value + " " + currencyCode(currency)
+	case "budget_usd":
+		if a, err := money.Parse(value, money.USD); err == nil {
+			return "Spend capped at " + a.String()
+		}
+		return "Spend capped at $" + value
+	case "budget_credits":
+		if v, err := strconv.ParseFloat(value, 64); err == nil {
+			return "Spend capped at " + money.FromCredits(v).String()
+		}
+	case "budget_sats", "max_sats":
+		if a, err := money.Parse(value, money.SAT); err == nil {
+			return "Spend capped at " + a.String()
+		}
+		return "Spend capped at " + value + " sats"
+	case "budget_msats", "max_msats":
+		if a, err := money.Parse(value, money.MSAT); err == nil {
+			return "Spend capped at " + a.String()
+		}
+	case "ip", "ip_range", "cidr", "source_ip":
+		return "Only from " + value
+	case "rate", "rate_limit":
+		return "Rate limited to " + value
+	case "agent", "name":
+		return "Bound to agent " + value
+	case "parent", "delegated_from":
+		return "Delegated from " + value
+	}
+	return fmt.Sprintf("%s %s %s", key, op, value)
+}
+
+// splitCaveat splits "key op value" on the first comparison operator
+func splitCaveat(s string) (key, op, value string) {
+	for _, o := range []string{"<=", ">=", "!=", "==", "<", ">", "="} {
+		if i := strings.Index(s, o); i > 0 {
+			return strings.TrimSpace(s[:i]), o, strings.TrimSpace(s[i+len(o):])
+		}
+	}
+	return "", "", ""
+}
+
+// sparkline renders values as a row of block characters scaled to the max
+func sparkline(values []float64) string {
+	blocks := []rune("▁▂▃▄▅▆▇█")
+	max := 0.0
+	for _, v := range values {
+		max = math.Max(max, v)
+	}
+	var b strings.Builder
+	for _, v := range values {
+		i := 0
+		if max > 0 {
+			i = int(math.Round(v / max * float64(len(blocks)-1)))
+		}
+		b.WriteRune(blocks[i])
+	}
+	return b.String()
+}
+
+func printTokenDetail(d tokenDetail, now time.Time) {
+	fmt.Printf("%s (%s)\n", d.Name, d.ID)
+	fmt.Println("─────────────────────────────")
+
+	status := d.Status
+	if d.RevokedAt != "" {
+		status += ", revoked " + d.RevokedAt
+	}
+	fmt.Printf("  %-12s %s\n", "Status:", status)
+	cur := d.Currency
+	if d.Budget > 0 {
+		fmt.Printf("  %-12s %s of %s (%.1f%%), %s left\n", "Spent:", money.Format(d.Spent, cur), money.Format(d.Budget, cur), d.Spent/d.Budget*100, money.Format(remainingBudget(d.Budget, d.Spent), cur))
+	} else {
+		fmt.Printf("  %-12s %s (unlimited budget)\n", "Spent:", money.Format(d.Spent, cur))
+	}
+	if d.CreatedAt != "" {
+		fmt.Printf("  %-12s %s\n", "Created:", d.CreatedAt)
+	}
+	expires := "never"
+	if t, ok := parseTimestamp(d.ExpiresAt); ok {
+		expires = fmt.Sprintf("%s (%s)", d.ExpiresAt, relativeTime(t, now))
+	}
+	fmt.Printf("  %-12s %s\n", "Expires:", expires)
+
+	lastSeen := "never"
+	if t, ok := parseTimestamp(d.LastSeenAt); ok {
+		lastSeen = relativeTime(t, now)
+	}
+	if d.LastSeenIP != "" {
+		lastSeen += " from " + d.LastSeenIP
+	}
+	fmt.Printf("  %-12s %s\n", "Last seen:", lastSeen)
+
+	routes := "all routes"
+	if len(d.Routes) > 0 {
+		routes = strings.Join(d.Routes, ", ")
+	}
+	fmt.Printf("  %-12s %s\n", "Routes:", routes)
+	if len(d.Labels) > 0 {
+		fmt.Printf("  %-12s %s\n", "Labels:", formatLabels(d.Labels))
+	}
+
+	if len(d.Ancestry) > 0 {
+		fmt.Println("\nDelegation chain")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for i, a := range d.Ancestry {
+			fmt.Fprintf(w, "  %s%s (%s)\t%s\t%s\n", strings.Repeat("  ", i), a.Name, truncate(a.ID, 16), a.Status, describeRemaining(a))
+		}
+		fmt.Fprintf(w, "  %s└── %s (this token)\t\t\n", strings.Repeat("  ", len(d.Ancestry)-1), d.Name)
+		w.Flush()
+	}
+
+	if len(d.Caveats) > 0 {
+		fmt.Println("\nCaveats")
+		for _, cv := range d.Caveats {
+			fmt.Printf("  • %s\n", cv)
+		}
+	}
+
+	if len(d.RouteSpend) > 0 {
+		fmt.Println("\nSpend by route")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, r := range d.RouteSpend {
+			share := 0.0
+			if d.Spent > 0 {
+				share = r.Spent / d.Spent * 100
+			}
+			reqs := ""
+			if r.Requests > 0 {
+				reqs = fmt.Sprintf("%d req", r.Requests)
+			}
+			fmt.Fprintf(w, "  %s\t%s\t%.0f%%\t%s\n", r.Route, money.Format(r.Spent, cur), share, reqs)
+		}
+		w.Flush()
+	}
+
+	if len(d.History) > 0 {
+		values := make([]float64, len(d.History))
+		total, peak := 0.0, d.History[0]
+		for i, h := range d.History {
+			values[i] = h.Spent
+			total += h.Spent
+			if h.Spent > peak.Spent {
+				peak = h
+			}
+		}
+		fmt.Printf("\nDaily spend (%s → %s)\n", d.History[0].Date, d.History[len(d.History)-1].Date)
+		fmt.Printf("  %s\n", sparkline(values))
+		fmt.Printf("  total %s, avg %s/day, peak %s on %s\n", money.Format(total, cur), money.Format(total/float64(len(d.History)), cur), money.Format(peak.Spent, cur), peak.Date)
+	}
+
+	if len(d.Children) > 0 {
+		fmt.Printf("\nChildren (%d)\n", len(d.Children))
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, ch := range d.Children {
+			fmt.Fprintf(w, "  %s (%s)\t%s\t%s\n", ch.Name, truncate(ch.ID, 16), ch.Status, describeRemaining(ch))
+		}
+		w.Flush()
+	}
+}
+
+func describeRemaining(s tokenSummary) string {
+	if s.Name == "" && s.Budget == 0 && s.Spent == 0 {
+		return "" // bare ID from the detail's delegation chain
+	}
+	if s.Remaining < 0 {
+		return money.Format(s.Spent, s.Currency) + " spent, unlimited"
+	}
+	return fmt.Sprintf("%s of %s left", money.Format(s.Remaining, s.Currency), money.Format(s.Budget, s.Currency))
+}
+
+// relativeTime formats t relative to now, e.g. "3h ago" or "in 12d"
+func relativeTime(t, now time.Time) string {
+	d := now.Sub(t)
+	suffix := " ago"
+	if d < 0 {
+		d, suffix = -d, ""
+	}
+	var s string
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		s = fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		s = fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		s = fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+	if suffix == "" {
+		return "in " + s
+	}
+	return s + suffix
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func firstRaw(values ...json.RawMessage) json.RawMessage {
+	for _, v := range values {
+		if len(v) > 0 && string(v) != "null" {
+			return v
+		}
+	}
+	return nil
+}
@@ -7,23 +7,45 @@ import (
 	"text/tabwriter"
 
 	"github.com/SatGate-io/satgate-cli/internal/client"
+	"github.com/SatGate-io/satgate-cli/internal/money"
 	"github.com/spf13/cobra"
 )
 
 var tokensCmd = &cobra.Command{
 	Use:   "tokens",
 	Short: "List all tokens with status, spend, and budget remaining",
+	Long: `List all tokens with status, spend, and budget remaining.
+
+Tokens are ordered by delegation hierarchy on both surfaces. Use --tree to
+draw the delegation tree with each subtree's rolled-up spend measured
+against the node's budget, and --format dot|mermaid to export it. Sats and
+msats roll up into each other; spend in a currency that needs an exchange
+rate is listed separately as "not rolled up" unless --currency is set.
+A token whose ancestry loops back on itself is drawn as a root and marked.
+
+-l filters by label (team=support,env!=dev,owner,!deprecated). With --tree
+only matching tokens are drawn; a match whose parent does not match
+becomes a root.
+
+--currency shows every amount in one currency, converting sats and fiat
+with the rate source from --rates; the rates used are printed below.
+
+--json prints the same tokens as a flat {"tokens": [...]} list in display
+units (cloud credits as dollars) whatever the surface; --raw prints the
+API response unchanged.`,
+	Example: `  satgate tokens --tree --depth 2
+  satgate tokens -l team=support,env=prod
+  satgate tokens --tree --root tok_abc123
+  satgate tokens --currency USD --rates file:rates.yaml
+  satgate tokens --format mermaid > delegation.mmd
+  satgate tokens --format dot | dot -Tsvg > delegation.svg`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		c, err := client.New()
 		if err != nil {
 			return err
 		}
 
-		path := "/admin/tokens"
-		if c.Surface() == "cloud" {
-			path = "/cloud/delegation-v2/tree"
-		}
-		data, code, err := c.Get(path)
+		data, code, err := c.Get(tokensPath(c))
 		if err != nil {
 			return err
 		}
@@ -31,150 +53,256 @@ var tokensCmd = &cobra.Command{
 			return fmt.Errorf("API returned HTTP %d: %s", code, string(data))
 		}
 
-		if flagJSON {
+		cv, err := newConverter(c)
+		if err != nil {
+			return err
+		}
+		sel, err := optionalSelector(tokensSelector)
+		if err != nil {
+			return err
+		}
+		if tokensRaw {
+			if cv != nil || sel != nil || tokensRoot != "" {
+				return fmt.Errorf("--raw prints the API response unchanged and cannot be combined with -l, --root or --currency")
+			}
 			fmt.Println(string(data))
 			return nil
 		}
 
-		type tokenInfo struct {
-			ID        string  `json:"id"`
-			Name      string  `json:"name"`
-			Status    string  `json:"status"`
-			Spent     float64 `json:"spent"`
-			Budget    float64 `json:"budget"`
-			BudgetLim float64 `json:"budget_limit_credits"`
-			BudgetSp  float64 `json:"budget_spent_credits"`
-			ExpiresAt string  `json:"expires_at"`
-			Depth     int     `json:"depth"`
-			Children  []tokenInfo `json:"children"`
-		}
-
-		var tokens []tokenInfo
-
-		// Try cloud tree format: {"tree": [...]}
-		var treeResp struct {
-			Tree []tokenInfo `json:"tree"`
-		}
-		if err := json.Unmarshal(data, &treeResp); err == nil && len(treeResp.Tree) > 0 {
-			// Flatten tree
-			var flatten func(nodes []tokenInfo)
-			flatten = func(nodes []tokenInfo) {
-				for _, n := range nodes {
-					// Convert credits to dollars (credits are cents)
-					if n.BudgetLim > 0 && n.Budget == 0 {
-						n.Budget = n.BudgetLim / 100
-					}
-					if n.BudgetSp > 0 && n.Spent == 0 {
-						n.Spent = n.BudgetSp / 100
-					}
-					tokens = append(tokens, n)
-					if len(n.Children) > 0 {
-						flatten(n.Children)
-					}
-				}
+		all, err := cv.tokens(parseTokens(data))
+		if err != nil {
+			return err
+		}
+		roots := buildTokenTree(sel.selectTokens(all))
+		if tokensRoot != "" {
+			id, err := matchTokenPrefix(all, tokensRoot)
+			if err != nil {
+				return err
 			}
-			flatten(treeResp.Tree)
-		} else {
-			// Try admin format: {"tokens": [...]} or raw array
-			var resp struct {
-				Tokens []tokenInfo `json:"tokens"`
+			n := findTokenNode(roots, id.ID)
+			if n == nil && sel != nil && findTokenNode(buildTokenTree(all), id.ID) != nil {
+				return fmt.Errorf("token %s does not match -l %s", id.ID, sel)
 			}
-			if err := json.Unmarshal(data, &resp); err == nil {
-				tokens = resp.Tokens
-			} else {
-				json.Unmarshal(data, &tokens)
+			if n == nil {
+				return fmt.Errorf("token %s not found", tokensRoot)
 			}
+			roots = []*tokenNode{n}
 		}
+		tokens := flattenTokenTree(roots)
 
-		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-		fmt.Fprintln(w, "ID\tNAME\tSTATUS\tSPENT\tBUDGET\tEXPIRES")
-		fmt.Fprintln(w, "──\t────\t──────\t─────\t──────\t───────")
-		for _, t := range tokens {
-			status := t.Status
-			if status == "revoked" {
-				status = "⛔ revoked"
-			} else if status == "active" {
-				status = "✓ active"
-			}
-			remaining := ""
-			if t.Budget > 0 {
-				remaining = fmt.Sprintf("$%.2f", t.Budget)
-			} else {
-				remaining = "unlimited"
-			}
-			// Indent name by depth for tree visualization
-			indent := ""
-			for i := 0; i < t.Depth; i++ {
-				indent += "  "
+		if flagJSON {
+			// A flat list in hierarchy order; parent_id links the tree.
+			// The cloud's credits, scope, cost center and department are
+			// already in budget, spent, routes and labels.
+			list := make([]tokenInfo, len(tokens))
+			for i, t := range tokens {
+				t.Children, t.Scope, t.BudgetLim, t.BudgetSp = nil, nil, 0, 0
+				t.CostCenter, t.Department = "", ""
+				t.Currency = currencyCode(t.Currency)
+				list[i] = t
 			}
-			if t.Depth > 0 {
-				indent += "└ "
+			out := map[string]interface{}{"tokens": list}
+			if cv != nil {
+				out["conversion"] = cv.meta()
 			}
-			name := indent + t.Name
-			fmt.Fprintf(w, "%s\t%s\t%s\t$%.2f\t%s\t%s\n",
-				truncate(t.ID, 16), name, status, t.Spent, remaining, truncate(t.ExpiresAt, 10))
+			data, _ := json.MarshalIndent(out, "", "  ")
+			fmt.Println(string(data))
+			return nil
 		}
-		w.Flush()
 
+		switch tokensFormat {
+		case "dot":
+			writeTokenDot(os.Stdout, roots, tokensDepth)
+			return nil
+		case "mermaid":
+			writeTokenMermaid(os.Stdout, roots, tokensDepth)
+			return nil
+		case "":
+		default:
+			return fmt.Errorf("invalid --format %q (use dot or mermaid)", tokensFormat)
+		}
+
+		if tokensTree {
+			printTokenTree(os.Stdout, roots, tokensDepth)
+		} else {
+			printTokenTable(tokens, true)
+		}
+		cv.note(os.Stdout)
 		fmt.Fprintf(os.Stderr, "\n%d tokens total\n", len(tokens))
 		return nil
 	},
 }
 
-var tokenCmd = &cobra.Command{
-	Use:   "token [id]",
-	Short: "Show token detail: caveats, delegation chain, spend history",
-	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		c, err := client.New()
-		if err != nil {
-			return err
+// printTokenTable prints the token table, indenting names by delegation
+// depth when the list is in hierarchy order
+func printTokenTable(tokens []tokenInfo, indentDepth bool) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	showLabels := false
+	for _, t := range tokens {
+		showLabels = showLabels || len(t.Labels) > 0
+	}
+	if showLabels {
+		fmt.Fprintln(w, "ID\tNAME\tSTATUS\tSPENT\tBUDGET\tEXPIRES\tLABELS")
+		fmt.Fprintln(w, "──\t────\t──────\t─────\t──────\t───────\t──────")
+	} else {
+		fmt.Fprintln(w, "ID\tNAME\tSTATUS\tSPENT\tBUDGET\tEXPIRES")
+		fmt.Fprintln(w, "──\t────\t──────\t─────\t──────\t───────")
+	}
+	for _, t := range tokens {
+		status := t.Status
+		if status == "revoked" {
+			status = "⛔ revoked"
+		} else if status == "active" {
+			status = "✓ active"
 		}
-
-		path := "/admin/tokens/" + args[0]
-		if c.Surface() == "cloud" {
-			path = "/cloud/delegation-v2/token/" + args[0]
+		remaining := ""
+		if t.Budget > 0 {
+			remaining = money.Format(t.Budget, t.Currency)
+		} else {
+			remaining = "unlimited"
 		}
-		data, code, err := c.Get(path)
-		if err != nil {
-			return err
+		// Indent name by depth for tree visualization
+		indent := ""
+		for i := 0; indentDepth && i < t.Depth; i++ {
+			indent += "  "
 		}
-		if code == 404 {
-			return fmt.Errorf("token %s not found", args[0])
+		if indentDepth && t.Depth > 0 {
+			indent += "└ "
 		}
-		if code != 200 {
-			return fmt.Errorf("API returned HTTP %d: %s", code, string(data))
+		name := indent + t.Name
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s",
+			truncate(t.ID, 16), name, status, money.Format(t.Spent, t.Currency), remaining, truncate(t.ExpiresAt, 10))
+		if showLabels {
+			fmt.Fprintf(w, "\t%s", labelColumn(t.Labels))
 		}
+		fmt.Fprintln(w)
+	}
+	w.Flush()
+}
 
-		if flagJSON {
-			fmt.Println(string(data))
-			return nil
-		}
+var tokensRaw bool
 
-		// Pretty-print the token detail
-		var resp map[string]interface{}
-		json.Unmarshal(data, &resp)
-
-		fmt.Println("Token Detail")
-		fmt.Println("─────────────────────────────")
-		for _, key := range []string{"id", "name", "status", "spent", "budget", "created_at", "expires_at", "parent_id", "caveats", "routes", "delegation_chain"} {
-			if v, ok := resp[key]; ok {
-				switch val := v.(type) {
-				case []interface{}, map[string]interface{}:
-					pretty, _ := json.MarshalIndent(val, "  ", "  ")
-					fmt.Printf("  %-18s %s\n", key+":", string(pretty))
-				default:
-					fmt.Printf("  %-18s %v\n", key+":", val)
+func init() {
+	tokensCmd.Flags().BoolVar(&tokensRaw, "raw", false, "print the API response unchanged")
+	rootCmd.AddCommand(tokensCmd)
+	rootCmd.AddCommand(tokenCmd)
+}
+
+// tokenInfo is a single token as returned by either surface. Amounts are
+// in display units of Currency. Cloud budgets arrive as credits (cents)
+// and cloud routes live under scope; parseTokens normalizes both so
+// callers can treat the surfaces alike.
+type tokenInfo struct {
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`
+	Status     string            `json:"status"`
+	Spent      float64           `json:"spent"`
+	Budget     float64           `json:"budget"`
+	Currency   string            `json:"currency,omitempty"` // blank = USD; cloud is always USD
+	BudgetLim  float64           `json:"budget_limit_credits,omitempty"`
+	BudgetSp   float64           `json:"budget_spent_credits,omitempty"`
+	ExpiresAt  string            `json:"expires_at"`
+	Depth      int               `json:"depth"`
+	Children   []tokenInfo       `json:"children,omitempty"`
+	Routes     []string          `json:"routes"`
+	ParentID   string            `json:"parent_id"`
+	CreatedAt  string            `json:"created_at"`
+	LastUsedAt string            `json:"last_used_at"`
+	RevokedAt  string            `json:"revoked_at"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	CostCenter string            `json:"costCenter,omitempty"`
+	Department string            `json:"department,omitempty"`
+	Scope      *struct {
+		Routes []string `json:"routes"`
+	} `json:"scope,omitempty"`
+}
+
+// parseTokens accepts the cloud tree ({"tree": [...]}), the admin list
+// ({"tokens": [...]}) or a raw array and returns a flat token list.
+func parseTokens(data []byte) []tokenInfo {
+	var tokens []tokenInfo
+
+	// Try cloud tree format: {"tree": [...]}
+	var treeResp struct {
+		Tree []tokenInfo `json:"tree"`
+	}
+	if err := json.Unmarshal(data, &treeResp); err == nil && len(treeResp.Tree) > 0 {
+		// Flatten tree
+		var flatten func(nodes []tokenInfo, parentID string)
+		flatten = func(nodes []tokenInfo, parentID string) {
+			for _, n := range nodes {
+				n = creditsToDollars(n)
+				if n.ParentID == "" {
+					n.ParentID = parentID
+				}
+				tokens = append(tokens, n)
+				if len(n.Children) > 0 {
+					flatten(n.Children, n.ID)
 				}
 			}
 		}
-		return nil
-	},
+		flatten(treeResp.Tree, "")
+		return normalizeTokens(tokens)
+	}
+
+	// Try admin format: {"tokens": [...]} or raw array
+	var resp struct {
+		Tokens []tokenInfo `json:"tokens"`
+	}
+	if err := json.Unmarshal(data, &resp); err == nil {
+		return normalizeTokens(resp.Tokens)
+	}
+	json.Unmarshal(data, &tokens)
+	return normalizeTokens(tokens)
 }
 
-func init() {
-	rootCmd.AddCommand(tokensCmd)
-	rootCmd.AddCommand(tokenCmd)
+// normalizeTokens fills fields that only one surface reports directly:
+// routes from the cloud scope, depth from the parent chain, and the cloud
+// cost center and department as labels.
+func normalizeTokens(tokens []tokenInfo) []tokenInfo {
+	parents := map[string]string{}
+	for _, t := range tokens {
+		parents[t.ID] = t.ParentID
+	}
+	for i := range tokens {
+		t := &tokens[i]
+		if len(t.Routes) == 0 && t.Scope != nil {
+			t.Routes = t.Scope.Routes
+		}
+		for key, v := range map[string]string{labelCostCenter: t.CostCenter, labelDepartment: t.Department} {
+			if v == "" {
+				continue
+			}
+			if t.Labels == nil {
+				t.Labels = map[string]string{}
+			}
+			if _, ok := t.Labels[key]; !ok {
+				t.Labels[key] = v
+			}
+		}
+		if t.Depth == 0 && t.ParentID != "" {
+			for id := t.ParentID; id != "" && t.Depth < len(tokens); id = parents[id] {
+				t.Depth++
+			}
+		}
+	}
+	return tokens
+}
+
+// tokensPath returns the token listing endpoint for the client's surface
+func tokensPath(c *client.Client) string {
+	if c.Surface() == "cloud" {
+		return "/cloud/delegation-v2/tree"
+	}
+	return "/admin/tokens"
+}
+
+// tokenDetailPath returns the detail endpoint for a token on the client's surface
+func tokenDetailPath(c *client.Client, id string) string {
+	if c.Surface() == "cloud" {
+		return "/cloud/delegation-v2/token/" + id
+	}
+	return "/admin/tokens/" + id
 }
 
 func truncate(s string, n int) string {
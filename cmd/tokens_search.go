@@ -0,0 +1,274 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SatGate-io/satgate-cli/internal/client"
+	"github.com/spf13/cobra"
+)
+
+var tokensSearchCmd = &cobra.Command{
+	Use:   "search <query>...",
+	Short: "Find tokens by name, ID prefix, route, status or spend",
+	Long: `Search tokens with a small query language. All terms must match. Quote
+terms containing <, >, *, ? or !, which the shell would otherwise treat as
+redirections, globs or history.
+
+  'name:cs-*'            name glob (* and ?, case-insensitive)
+  id:tok_abc1            ID prefix; truncated IDs from the table work, "…" included
+  'route:/api/openai/*'  a scoped route matches the glob, or covers the given path
+  status:active          status glob
+  parent:tok_abc1        parent ID prefix ("none" for root tokens)
+  label:team=support     label requirement (also label:team, 'label:env!=dev')
+  'spent>100'            numeric: spent, budget, remaining, depth, util (percent)
+  'expires<7d'           expires within 7 days (or before a date, e.g. 2026-12-01)
+  '!status:revoked'      a leading "!" (or "-" after --) negates a term
+  cs-bot                 a bare word matches the name glob or the ID prefix
+
+Budgets of 0 are unlimited; they never match remaining or util comparisons.`,
+	Example: `  satgate tokens search 'name:cs-*' status:active 'spent>100'
+  satgate tokens search route:/api/openai/chat '!status:revoked'
+  satgate tokens search -- -status:revoked 'depth>=1'
+  satgate tokens search tok_abc123456789…
+  satgate tokens search 'util>=80' 'expires<7d' --json`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		q, err := parseTokenQuery(strings.Join(args, " "), clock())
+		if err != nil {
+			return err
+		}
+
+		c, err := client.New()
+		if err != nil {
+			return err
+		}
+		data, code, err := c.Get(tokensPath(c))
+		if err != nil {
+			return err
+		}
+		if code != 200 {
+			return fmt.Errorf("API returned HTTP %d: %s", code, string(data))
+		}
+
+		var matches []tokenInfo
+		for _, t := range flattenTokenTree(buildTokenTree(parseTokens(data))) {
+			if q.match(t) {
+				matches = append(matches, t)
+			}
+		}
+
+		if flagJSON {
+			if matches == nil {
+				matches = []tokenInfo{}
+			}
+			out, _ := json.MarshalIndent(matches, "", "  ")
+			fmt.Println(string(out))
+			return nil
+		}
+
+		if len(matches) == 0 {
+			fmt.Fprintln(os.Stderr, "No tokens match.")
+			return nil
+		}
+		printTokenTable(matches, false)
+		fmt.Fprintf(os.Stderr, "\n%d matching tokens\n", len(matches))
+		return nil
+	},
+}
+
+func init() {
+	tokensCmd.AddCommand(tokensSearchCmd)
+}
+
+// tokenQuery is a parsed search: every term must match
+type tokenQuery []tokenTerm
+
+type tokenTerm struct {
+	negate bool
+	match  func(tokenInfo) bool
+}
+
+var numericTerm = regexp.MustCompile(`^([a-z]+)(>=|<=|!=|>|<|=)(.+)$`)
+
+// parseTokenQuery parses a space-separated query; now anchors relative
+// expiry comparisons
+func parseTokenQuery(query string, now time.Time) (tokenQuery, error) {
+	var q tokenQuery
+	for _, word := range strings.Fields(query) {
+		term := tokenTerm{}
+		if (strings.HasPrefix(word, "-") || strings.HasPrefix(word, "!")) && len(word) > 1 {
+			term.negate = true
+			word = word[1:]
+		}
+
+		if m := numericTerm.FindStringSubmatch(word); m != nil {
+			fn, err := comparisonTerm(m[1], m[2], m[3], now)
+			if err != nil {
+				return nil, err
+			}
+			term.match = fn
+			q = append(q, term)
+			continue
+		}
+
+		field, value, ok := strings.Cut(word, ":")
+		if !ok {
+			pattern := word
+			term.match = func(t tokenInfo) bool {
+				return globMatch(pattern, t.Name) || idPrefixMatch(pattern, t.ID)
+			}
+			q = append(q, term)
+			continue
+		}
+		if value == "" {
+			return nil, fmt.Errorf("empty value in search term %q", word)
+		}
+
+		switch field {
+		case "name":
+			term.match = func(t tokenInfo) bool { return globMatch(value, t.Name) }
+		case "id":
+			term.match = func(t tokenInfo) bool { return idPrefixMatch(value, t.ID) }
+		case "status":
+			term.match = func(t tokenInfo) bool { return globMatch(value, t.Status) }
+		case "parent":
+			term.match = func(t tokenInfo) bool {
+				if value == "none" {
+					return t.ParentID == ""
+				}
+				return t.ParentID != "" && idPrefixMatch(value, t.ParentID)
+			}
+		case "label":
+			r, err := parseRequirement(value)
+			if err != nil {
+				return nil, err
+			}
+			term.match = func(t tokenInfo) bool { return r.matches(t.Labels) }
+		case "route":
+			term.match = func(t tokenInfo) bool {
+				if len(t.Routes) == 0 {
+					return value == "*" // unscoped tokens reach every route
+				}
+				for _, r := range t.Routes {
+					if globMatch(value, r) || globMatch(r, value) {
+						return true
+					}
+				}
+				return false
+			}
+		default:
+			return nil, fmt.Errorf("unknown search field %q (use name, id, route, status, parent or label)", field)
+		}
+		q = append(q, term)
+	}
+	return q, nil
+}
+
+// comparisonTerm builds a numeric or expiry comparison
+func comparisonTerm(field, op, value string, now time.Time) (func(tokenInfo) bool, error) {
+	if field == "expires" {
+		limit, ok := parseTimestamp(value)
+		if !ok {
+			if t, err := time.Parse("2006-01-02", value); err == nil {
+				limit, ok = t, true
+			} else if d, err := parseDuration(value); err == nil {
+				limit, ok = now.Add(d), true
+			}
+		}
+		if !ok {
+			return nil, fmt.Errorf("invalid expiry %q in search (use 7d, 2026-12-01 or RFC 3339)", value)
+		}
+		return func(t tokenInfo) bool {
+			exp, ok := parseTimestamp(t.ExpiresAt)
+			if !ok {
+				return false // never expires
+			}
+			return compareFloat(float64(exp.Unix()), op, float64(limit.Unix()))
+		}, nil
+	}
+
+	n, err := strconv.ParseFloat(strings.TrimPrefix(strings.TrimSuffix(value, "%"), "$"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid number %q in search term %s%s%s", value, field, op, value)
+	}
+	var get func(tokenInfo) (float64, bool)
+	switch field {
+	case "spent":
+		get = func(t tokenInfo) (float64, bool) { return t.Spent, true }
+	case "budget":
+		get = func(t tokenInfo) (float64, bool) { return t.Budget, true }
+	case "remaining":
+		get = func(t tokenInfo) (float64, bool) { return t.Budget - t.Spent, t.Budget > 0 }
+	case "util":
+		get = func(t tokenInfo) (float64, bool) { return t.Spent / t.Budget * 100, t.Budget > 0 }
+	case "depth":
+		get = func(t tokenInfo) (float64, bool) { return float64(t.Depth), true }
+	default:
+		return nil, fmt.Errorf("unknown numeric field %q (use spent, budget, remaining, util, depth or expires)", field)
+	}
+	return func(t tokenInfo) bool {
+		v, ok := get(t)
+		return ok && compareFloat(v, op, n)
+	}, nil
+}
+
+func compareFloat(a float64, op string, b float64) bool {
+	switch op {
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case "!=":
+		return a != b
+	}
+	return a == b
+}
+
+func (q tokenQuery) match(t tokenInfo) bool {
+	for _, term := range q {
+		if term.match(t) == term.negate {
+			return false
+		}
+	}
+	return true
+}
+
+// globMatch matches * and ? case-insensitively; unlike path.Match, * also
+// crosses "/" so route globs behave as they do on the gateway
+func globMatch(pattern, s string) bool {
+	var b strings.Builder
+	b.WriteString("(?i)^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	re, err := regexp.Compile(b.String())
+	return err == nil && re.MatchString(s)
+}
+
+// idPrefixMatch matches an ID prefix, accepting the "…" the table appends
+// to truncated IDs
+func idPrefixMatch(prefix, id string) bool {
+	prefix = strings.TrimSuffix(prefix, "…")
+	if strings.ContainsAny(prefix, "*?") {
+		return globMatch(prefix, id)
+	}
+	return prefix != "" && strings.HasPrefix(id, prefix)
+}
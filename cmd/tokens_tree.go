@@ -0,0 +1,312 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/SatGate-io/satgate-cli/internal/money"
+)
+
+var (
+	tokensTree     bool
+	tokensDepth    int
+	tokensRoot     string
+	tokensFormat   string
+	tokensSelector string
+)
+
+func init() {
+	tokensCmd.Flags().BoolVar(&tokensTree, "tree", false, "show the delegation tree with rolled-up subtree spend")
+	tokensCmd.Flags().IntVar(&tokensDepth, "depth", 0, "collapse the tree below this depth (0 = show all)")
+	tokensCmd.Flags().StringVar(&tokensRoot, "root", "", "only show the subtree under this token ID or unique prefix")
+	tokensCmd.Flags().StringVarP(&tokensSelector, "selector", "l", "", "filter by labels, e.g. team=support,env!=dev")
+	tokensCmd.Flags().StringVar(&tokensFormat, "format", "", "export the delegation tree as dot (Graphviz) or mermaid")
+	addCurrencyFlags(tokensCmd.Flags())
+}
+
+// tokenNode is a token with its delegated children and subtree totals.
+// Children carve their budgets out of the parent's, so the parent's budget
+// is the allocation the whole subtree's spend is measured against.
+type tokenNode struct {
+	Token    tokenInfo
+	Children []*tokenNode
+
+	SubtreeSpent float64      // own spend plus every descendant's, in the token's currency
+	Unrolled     money.Totals // descendant spend in currencies that cannot be converted to the token's
+	Descendants  int
+	Cycle        bool // surfaced as a root because its ancestry loops back on itself
+}
+
+// buildTokenTree links tokens by ParentID. Tokens whose parent is missing
+// from the list become roots, as does one token of each parent cycle so
+// no token is lost. Siblings are ordered by name.
+func buildTokenTree(tokens []tokenInfo) []*tokenNode {
+	nodes := make(map[string]*tokenNode, len(tokens))
+	var order []*tokenNode
+	for _, t := range tokens {
+		if _, dup := nodes[t.ID]; dup {
+			continue
+		}
+		t.Children = nil // hierarchy is rebuilt from ParentID
+		n := &tokenNode{Token: t}
+		nodes[t.ID] = n
+		order = append(order, n)
+	}
+
+	var roots []*tokenNode
+	for _, n := range order {
+		t := n.Token
+		if parent, ok := nodes[t.ParentID]; ok && t.ParentID != "" && t.ParentID != t.ID {
+			parent.Children = append(parent.Children, n)
+		} else {
+			roots = append(roots, n)
+		}
+	}
+
+	seen := map[*tokenNode]bool{}
+	var rollup func(n *tokenNode, depth int)
+	rollup = func(n *tokenNode, depth int) {
+		seen[n] = true
+		n.Token.Depth = depth
+		n.SubtreeSpent = n.Token.Spent
+		sortTokenNodes(n.Children)
+		for _, c := range n.Children {
+			rollup(c, depth+1)
+			n.addSpent(c.SubtreeSpent, c.Token.Currency)
+			for code, v := range c.Unrolled {
+				n.addSpent(v, code)
+			}
+			n.Descendants += 1 + c.Descendants
+		}
+	}
+	for _, r := range roots {
+		rollup(r, 0)
+	}
+
+	// Tokens never reached sit on a parent cycle or hang below one. Follow
+	// the parents until a token repeats to find the cycle itself, cut it at
+	// its first member in listing order and show that member as a root;
+	// tokens below the cycle keep their real parents.
+	index := make(map[*tokenNode]int, len(order))
+	for i, n := range order {
+		index[n] = i
+	}
+	for _, start := range order {
+		if seen[start] {
+			continue
+		}
+		onPath := map[*tokenNode]bool{}
+		n := start
+		for !onPath[n] {
+			onPath[n] = true
+			n = nodes[n.Token.ParentID]
+		}
+		for m := nodes[n.Token.ParentID]; m != n; m = nodes[m.Token.ParentID] {
+			if index[m] < index[n] {
+				n = m
+			}
+		}
+		parent := nodes[n.Token.ParentID]
+		for i, c := range parent.Children {
+			if c == n {
+				parent.Children = append(parent.Children[:i:i], parent.Children[i+1:]...)
+				break
+			}
+		}
+		n.Cycle = true
+		roots = append(roots, n)
+		rollup(n, 0)
+	}
+	sortTokenNodes(roots)
+	return roots
+}
+
+// addSpent adds descendant spend to the subtree total, converting between
+// sats and msats. Spend in a currency that needs an exchange rate is kept
+// apart in Unrolled rather than added as if it were the same unit.
+func (n *tokenNode) addSpent(v float64, code string) {
+	to, toErr := money.Lookup(n.Token.Currency)
+	from, fromErr := money.Lookup(code)
+	switch {
+	case toErr != nil || fromErr != nil:
+		if strings.EqualFold(n.Token.Currency, code) {
+			n.SubtreeSpent += v
+			return
+		}
+	case from == to:
+		n.SubtreeSpent += v
+		return
+	default:
+		if amt, err := money.FromFloat(v, from).In(to); err == nil {
+			n.SubtreeSpent += amt.Float()
+			return
+		}
+	}
+	if n.Unrolled == nil {
+		n.Unrolled = money.Totals{}
+	}
+	n.Unrolled.Add(v, code)
+}
+
+func sortTokenNodes(nodes []*tokenNode) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return nodes[i].Token.Name < nodes[j].Token.Name
+	})
+}
+
+// findTokenNode returns the node with the given ID anywhere in the forest
+func findTokenNode(roots []*tokenNode, id string) *tokenNode {
+	for _, r := range roots {
+		if r.Token.ID == id {
+			return r
+		}
+		if n := findTokenNode(r.Children, id); n != nil {
+			return n
+		}
+	}
+	return nil
+}
+
+// flattenTokenTree lists tokens depth-first so tables keep the hierarchy
+func flattenTokenTree(roots []*tokenNode) []tokenInfo {
+	var out []tokenInfo
+	var walk func(nodes []*tokenNode)
+	walk = func(nodes []*tokenNode) {
+		for _, n := range nodes {
+			out = append(out, n.Token)
+			walk(n.Children)
+		}
+	}
+	walk(roots)
+	return out
+}
+
+// usage formats rolled-up spend against the node's budget, followed by
+// any descendant spend in other currencies
+func (n *tokenNode) usage() string {
+	cur := n.Token.Currency
+	out := money.Format(n.SubtreeSpent, cur) + " / unlimited"
+	if n.Token.Budget > 0 {
+		out = fmt.Sprintf("%s / %s (%.1f%%)", money.Format(n.SubtreeSpent, cur), money.Format(n.Token.Budget, cur), n.SubtreeSpent/n.Token.Budget*100)
+	}
+	if len(n.Unrolled) > 0 {
+		out += fmt.Sprintf(" + %s not rolled up", n.Unrolled)
+	}
+	return out
+}
+
+// printTokenTree renders the forest with box-drawing connectors, collapsing
+// anything deeper than maxDepth (0 = unlimited)
+func printTokenTree(w io.Writer, roots []*tokenNode, maxDepth int) {
+	var walk func(n *tokenNode, prefix string, last bool, depth int)
+	walk = func(n *tokenNode, prefix string, last bool, depth int) {
+		connector, childPrefix := "", ""
+		if depth > 0 {
+			connector, childPrefix = "├── ", prefix+"│   "
+			if last {
+				connector, childPrefix = "└── ", prefix+"    "
+			}
+		}
+
+		status := ""
+		switch n.Token.Status {
+		case "revoked":
+			status = " ⛔ revoked"
+		case "active", "":
+		default:
+			status = " " + n.Token.Status
+		}
+		if n.Cycle {
+			status += " ⚠ parent cycle"
+		}
+		self := ""
+		if len(n.Children) > 0 {
+			self = fmt.Sprintf("  [self %s]", money.Format(n.Token.Spent, n.Token.Currency))
+		}
+		fmt.Fprintf(w, "%s%s%s (%s)%s  %s%s\n", prefix, connector, n.Token.Name, truncate(n.Token.ID, 16), status, n.usage(), self)
+
+		if len(n.Children) == 0 {
+			return
+		}
+		if maxDepth > 0 && depth+1 >= maxDepth {
+			hiddenSpent := n.SubtreeSpent - n.Token.Spent
+			fmt.Fprintf(w, "%s└── … %d descendant(s) collapsed, %s spent\n", childPrefix, n.Descendants, money.Format(hiddenSpent, n.Token.Currency))
+			return
+		}
+		for i, c := range n.Children {
+			walk(c, childPrefix, i == len(n.Children)-1, depth+1)
+		}
+	}
+	for _, r := range roots {
+		walk(r, "", true, 0)
+	}
+}
+
+// writeTokenDot exports the forest as a Graphviz digraph
+func writeTokenDot(w io.Writer, roots []*tokenNode, maxDepth int) {
+	fmt.Fprintln(w, "digraph delegation {")
+	fmt.Fprintln(w, "  rankdir=TB;")
+	fmt.Fprintln(w, `  node [shape=box, style="rounded,filled", fillcolor="#f6f8fa", fontname="Helvetica"];`)
+	walkTokenEdges(roots, maxDepth, func(n *tokenNode) {
+		fill := ""
+		if n.Token.Status == "revoked" {
+			fill = `, fillcolor="#ffebe9"`
+		}
+		fmt.Fprintf(w, "  %q [label=%q%s];\n", n.Token.ID, n.Token.Name+"\n"+n.usage(), fill)
+	}, func(parent, child *tokenNode) {
+		fmt.Fprintf(w, "  %q -> %q;\n", parent.Token.ID, child.Token.ID)
+	}, func(n *tokenNode) {
+		id := n.Token.ID + "__collapsed"
+		fmt.Fprintf(w, "  %q [label=%q, shape=note];\n", id, fmt.Sprintf("… %d more", n.Descendants))
+		fmt.Fprintf(w, "  %q -> %q [style=dashed];\n", n.Token.ID, id)
+	})
+	fmt.Fprintln(w, "}")
+}
+
+var mermaidUnsafe = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// writeTokenMermaid exports the forest as a Mermaid flowchart
+func writeTokenMermaid(w io.Writer, roots []*tokenNode, maxDepth int) {
+	id := func(s string) string { return "t_" + mermaidUnsafe.ReplaceAllString(s, "_") }
+	label := func(s string) string { return strings.ReplaceAll(s, `"`, "#quot;") }
+
+	fmt.Fprintln(w, "flowchart TD")
+	walkTokenEdges(roots, maxDepth, func(n *tokenNode) {
+		fmt.Fprintf(w, "  %s[\"%s<br/>%s\"]\n", id(n.Token.ID), label(n.Token.Name), label(n.usage()))
+		if n.Token.Status == "revoked" {
+			fmt.Fprintf(w, "  class %s revoked\n", id(n.Token.ID))
+		}
+	}, func(parent, child *tokenNode) {
+		fmt.Fprintf(w, "  %s --> %s\n", id(parent.Token.ID), id(child.Token.ID))
+	}, func(n *tokenNode) {
+		fmt.Fprintf(w, "  %s_more>\"… %d more\"]\n", id(n.Token.ID), n.Descendants)
+		fmt.Fprintf(w, "  %s -.-> %s_more\n", id(n.Token.ID), id(n.Token.ID))
+	})
+	fmt.Fprintln(w, "  classDef revoked fill:#ffebe9,stroke:#cf222e")
+}
+
+// walkTokenEdges visits nodes and parent→child edges down to maxDepth,
+// calling collapsed for nodes whose children are cut off
+func walkTokenEdges(roots []*tokenNode, maxDepth int, node func(*tokenNode), edge func(parent, child *tokenNode), collapsed func(*tokenNode)) {
+	var walk func(n *tokenNode, depth int)
+	walk = func(n *tokenNode, depth int) {
+		node(n)
+		if len(n.Children) == 0 {
+			return
+		}
+		if maxDepth > 0 && depth+1 >= maxDepth {
+			collapsed(n)
+			return
+		}
+		for _, c := range n.Children {
+			walk(c, depth+1)
+			edge(n, c)
+		}
+	}
+	for _, r := range roots {
+		walk(r, 0)
+	}
+}
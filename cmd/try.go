@@ -0,0 +1,439 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SatGate-io/satgate-cli/internal/client"
+	"github.com/SatGate-io/satgate-cli/internal/config"
+	"github.com/SatGate-io/satgate-cli/internal/l402"
+	"github.com/SatGate-io/satgate-cli/internal/money"
+	"github.com/spf13/cobra"
+)
+
+var (
+	tryMacaroon string
+	tryPreimage string
+	tryAuth     string
+	tryToken    string
+	tryMethod   string
+	tryHeaders  []string
+	tryData     string
+	tryTimeout  time.Duration
+	trySettle   time.Duration
+	tryBody     bool
+)
+
+var tryCmd = &cobra.Command{
+	Use:   "try <url>",
+	Short: "Send a request with a token and report the gateway's decision",
+	Long: `Send one request to a protected route with a macaroon, as an agent
+would, and report what the gateway decided: allowed or denied and why,
+the charge applied, the remaining budget and any caveat that failed.
+
+The credential comes from --macaroon (a value, @file, or - for stdin),
+falling back to $SATGATE_MACAROON. It is sent as a Bearer token, or as
+"L402 <macaroon>:<preimage>" with --preimage. --auth sends an
+Authorization value verbatim instead.
+
+With --token, or when the macaroon names its token, the token's spend is
+read from the admin API before and after the request and the difference
+reported as the charge. A path is resolved against the configured
+gateway. The command exits non-zero when the request is not allowed.`,
+	Example: `  satgate try /api/openai/v1/models --macaroon @bot.macaroon
+  satgate try /api/premium/search --macaroon "$MAC" --preimage "$PREIMAGE"
+  satgate try https://api.example.com/v1/chat -X POST -d @req.json --token tok_abc1 --show-body`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		target := args[0]
+		if strings.HasPrefix(target, "/") {
+			target = strings.TrimRight(config.Get().Gateway, "/") + target
+		}
+		u, err := url.Parse(target)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("invalid URL %q", args[0])
+		}
+		auth, macaroon, err := tryAuthorization()
+		if err != nil {
+			return err
+		}
+		headers := http.Header{}
+		for _, h := range tryHeaders {
+			k, v, ok := strings.Cut(h, ":")
+			if !ok {
+				return fmt.Errorf("invalid --header %q (use Name: value)", h)
+			}
+			headers.Add(strings.TrimSpace(k), strings.TrimSpace(v))
+		}
+		headers.Set("Authorization", auth)
+		body, err := readFlagValue(tryData)
+		if err != nil {
+			return fmt.Errorf("--data: %w", err)
+		}
+		method := strings.ToUpper(tryMethod)
+		if method == "GET" && body != "" && !cmd.Flags().Changed("method") {
+			method = "POST"
+		}
+		if flagDry {
+			fmt.Printf("Would send %s %s\n", method, u)
+			fmt.Printf("  Authorization: %s\n", redactAuth(auth))
+			for _, k := range sortedKeys(headers) {
+				if k != "Authorization" {
+					fmt.Printf("  %s: %s\n", k, strings.Join(headers[k], ", "))
+				}
+			}
+			if body != "" {
+				fmt.Printf("  Body: %d bytes\n", len(body))
+			}
+			return nil
+		}
+
+		// The spend comparison needs the admin API; without it the request
+		// is still sent and only the gateway's own response is reported
+		var c *client.Client
+		tokenID := firstNonEmpty(tryToken, macaroonTokenID(macaroon))
+		if tokenID != "" {
+			if c, err = client.New(); err != nil {
+				fmt.Fprintf(os.Stderr, "⚠️  Not comparing spend: %v\n", err)
+				tokenID = ""
+			} else if id, err := resolveTokenID(c, tokenID); err == nil {
+				tokenID = id.ID
+			} else if tryToken != "" {
+				return err
+			}
+		}
+
+		result := tryResult{Method: method, URL: u.String(), Credential: redactAuth(auth), TokenID: tokenID}
+		var before *tokenDetail
+		if tokenID != "" {
+			if before, err = fetchTryDetail(c, tokenID); err != nil {
+				fmt.Fprintf(os.Stderr, "⚠️  Not comparing spend: %v\n", err)
+				tokenID = ""
+			}
+		}
+
+		if err := result.send(headers, body); err != nil {
+			return err
+		}
+
+		if tokenID != "" {
+			after := waitForSpend(c, tokenID, before, result.allowed(), trySettle)
+			if after != nil {
+				result.compare(before, after)
+			}
+		}
+
+		if flagJSON {
+			out, _ := json.MarshalIndent(result, "", "  ")
+			fmt.Println(string(out))
+		} else {
+			result.print()
+		}
+		if !result.allowed() {
+			return fmt.Errorf("request was not allowed: HTTP %d %s", result.Status, http.StatusText(result.Status))
+		}
+		return nil
+	},
+}
+
+func init() {
+	tryCmd.Flags().StringVar(&tryMacaroon, "macaroon", "", "macaroon to present: value, @file or - for stdin (default $SATGATE_MACAROON)")
+	tryCmd.Flags().StringVar(&tryPreimage, "preimage", "", "payment preimage (hex); sends Authorization: L402 <macaroon>:<preimage>")
+	tryCmd.Flags().StringVar(&tryAuth, "auth", "", "Authorization header value to send verbatim, e.g. \"L402 AgEE...:abcd...\"")
+	tryCmd.Flags().StringVar(&tryToken, "token", "", "token ID or prefix to compare spend before and after")
+	tryCmd.Flags().StringVarP(&tryMethod, "method", "X", "GET", "HTTP method (POST when --data is given)")
+	tryCmd.Flags().StringArrayVarP(&tryHeaders, "header", "H", nil, "extra request header, e.g. \"Content-Type: application/json\" (repeatable)")
+	tryCmd.Flags().StringVarP(&tryData, "data", "d", "", "request body, or @file")
+	tryCmd.Flags().DurationVar(&tryTimeout, "timeout", 30*time.Second, "request timeout")
+	tryCmd.Flags().DurationVar(&trySettle, "settle", 2*time.Second, "how long to wait for the spend to be recorded")
+	tryCmd.Flags().BoolVar(&tryBody, "show-body", false, "print the start of the response body")
+	rootCmd.AddCommand(tryCmd)
+}
+
+// tryAuthorization builds the Authorization header from the flags and
+// returns it with the macaroon it carries, if any
+func tryAuthorization() (string, string, error) {
+	if tryAuth != "" {
+		if tryMacaroon != "" || tryPreimage != "" {
+			return "", "", fmt.Errorf("--auth cannot be combined with --macaroon or --preimage")
+		}
+		mac := tryAuth
+		if _, rest, ok := strings.Cut(tryAuth, " "); ok {
+			mac, _, _ = strings.Cut(rest, ":")
+		}
+		return tryAuth, mac, nil
+	}
+	mac, err := readFlagValue(tryMacaroon)
+	if err != nil {
+		return "", "", fmt.Errorf("--macaroon: %w", err)
+	}
+	mac = strings.TrimSpace(firstNonEmpty(mac, os.Getenv("SATGATE_MACAROON")))
+	if mac == "" {
+		return "", "", fmt.Errorf("no credential: pass --macaroon, --auth or set SATGATE_MACAROON")
+	}
+	if tryPreimage != "" {
+		return "L402 " + mac + ":" + strings.TrimSpace(tryPreimage), mac, nil
+	}
+	return "Bearer " + mac, mac, nil
+}
+
+// readFlagValue expands @file and - (stdin) flag values
+func readFlagValue(v string) (string, error) {
+	switch {
+	case v == "-":
+		data, err := io.ReadAll(bufio.NewReader(os.Stdin))
+		return strings.TrimRight(string(data), "\n"), err
+	case strings.HasPrefix(v, "@"):
+		data, err := os.ReadFile(v[1:])
+		return strings.TrimRight(string(data), "\n"), err
+	}
+	return v, nil
+}
+
+// redactAuth keeps the scheme and the start of the credential
+func redactAuth(auth string) string {
+	scheme, cred, ok := strings.Cut(auth, " ")
+	if !ok {
+		scheme, cred = "", auth
+	}
+	if len(cred) > 12 {
+		cred = cred[:8] + "…[redacted]"
+	}
+	return strings.TrimSpace(scheme + " " + cred)
+}
+
+// macaroonTokenID finds a SatGate token ID in a macaroon's identifier or
+// a token_id caveat, so --token can usually be left out
+func macaroonTokenID(s string) string {
+	if s == "" {
+		return ""
+	}
+	mac, err := l402.DecodeMacaroon(s)
+	if err != nil {
+		return ""
+	}
+	if strings.HasPrefix(mac.Identifier, "tok_") {
+		return mac.Identifier
+	}
+	for _, c := range mac.Caveats {
+		key, _, value := splitCaveat(c.Condition)
+		if key == "token_id" || key == "token" {
+			return value
+		}
+	}
+	return ""
+}
+
+func fetchTryDetail(c *client.Client, id string) (*tokenDetail, error) {
+	data, code, err := c.Get(tokenDetailPath(c, id))
+	if err != nil {
+		return nil, fmt.Errorf("cannot reach gateway at %s: %w", config.Get().Gateway, err)
+	}
+	if code != 200 {
+		return nil, fmt.Errorf("token detail returned HTTP %d", code)
+	}
+	d := parseTokenDetail(data)
+	return &d, nil
+}
+
+// waitForSpend re-reads the token until its spend moves or settle runs
+// out, since gateways may record spend just after responding. A denied
+// request is read once.
+func waitForSpend(c *client.Client, id string, before *tokenDetail, allowed bool, settle time.Duration) *tokenDetail {
+	deadline := time.Now().Add(settle)
+	for {
+		after, err := fetchTryDetail(c, id)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  Cannot read spend after the request: %v\n", err)
+			return nil
+		}
+		if !allowed || after.Spent != before.Spent || time.Now().After(deadline) {
+			return after
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+}
+
+// tryResult is the request and the gateway's answer, also the --json output
+type tryResult struct {
+	Method     string            `json:"method"`
+	URL        string            `json:"url"`
+	Credential string            `json:"credential"`
+	Status     int               `json:"status"`
+	Latency    string            `json:"latency"`
+	Decision   string            `json:"decision"`
+	Reason     string            `json:"reason,omitempty"`
+	Caveats    []string          `json:"failed_caveats,omitempty"`
+	Gateway    map[string]string `json:"gateway_headers,omitempty"`
+	Body       string            `json:"body,omitempty"`
+
+	TokenID   string   `json:"token_id,omitempty"`
+	Currency  string   `json:"currency,omitempty"`
+	Charge    *float64 `json:"charge,omitempty"`
+	Before    *float64 `json:"spent_before,omitempty"`
+	After     *float64 `json:"spent_after,omitempty"`
+	Budget    float64  `json:"budget,omitempty"`
+	Remaining *float64 `json:"remaining,omitempty"` // absent when unlimited
+}
+
+// tryDecisions names the gateway's decision for each status it uses
+var tryDecisions = map[int]string{
+	401: "unauthenticated",
+	402: "payment required",
+	403: "denied",
+	429: "rate limited",
+}
+
+func (r tryResult) allowed() bool { return r.Status >= 200 && r.Status < 400 }
+
+func (r *tryResult) send(headers http.Header, body string) error {
+	req, err := http.NewRequest(r.Method, r.URL, strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header = headers
+	req.Header.Set("User-Agent", "satgate-cli/"+version+" (try)")
+	httpClient := &http.Client{Timeout: tryTimeout}
+	start := time.Now()
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	r.Status = resp.StatusCode
+	r.Latency = time.Since(start).Round(time.Millisecond).String()
+
+	r.Decision = "allowed"
+	if !r.allowed() {
+		r.Decision = firstNonEmpty(tryDecisions[r.Status], "error")
+	}
+
+	// Gateway decision headers, e.g. X-SatGate-Charge, X-SatGate-Caveat-Failed
+	for k, v := range resp.Header {
+		if strings.HasPrefix(strings.ToLower(k), "x-satgate-") {
+			if r.Gateway == nil {
+				r.Gateway = map[string]string{}
+			}
+			r.Gateway[k] = strings.Join(v, ", ")
+		}
+	}
+	if v := resp.Header.Get("X-SatGate-Caveat-Failed"); v != "" {
+		r.Caveats = append(r.Caveats, v)
+	}
+	if v := resp.Header.Get("X-SatGate-Reason"); v != "" {
+		r.Reason = v
+	}
+	if v := resp.Header.Get("X-SatGate-Charge"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			r.Charge = &f
+		}
+	}
+
+	if !r.allowed() {
+		r.readDenial(data)
+	}
+	if tryBody {
+		r.Body = string(data)
+	}
+	return nil
+}
+
+// readDenial picks the reason and failed caveats out of a JSON error body
+func (r *tryResult) readDenial(data []byte) {
+	var e struct {
+		Error         string   `json:"error"`
+		Message       string   `json:"message"`
+		Reason        string   `json:"reason"`
+		Caveat        string   `json:"caveat"`
+		FailedCaveats []string `json:"failed_caveats"`
+	}
+	if json.Unmarshal(data, &e) != nil {
+		if r.Reason == "" {
+			r.Reason = truncate(strings.TrimSpace(string(data)), 200)
+		}
+		return
+	}
+	r.Reason = firstNonEmpty(r.Reason, e.Reason, e.Message, e.Error)
+	if e.Caveat != "" {
+		r.Caveats = append(r.Caveats, e.Caveat)
+	}
+	r.Caveats = append(r.Caveats, e.FailedCaveats...)
+}
+
+// compare fills the charge and remaining budget from the token's spend
+// before and after the request. A charge the gateway reported in a header
+// is kept.
+func (r *tryResult) compare(before, after *tokenDetail) {
+	r.Currency = after.Currency
+	b, a := before.Spent, after.Spent
+	r.Before, r.After = &b, &a
+	if r.Charge == nil {
+		charge := a - b
+		r.Charge = &charge
+	}
+	r.Budget = after.Budget
+	if rem := remainingBudget(after.Budget, after.Spent); rem >= 0 {
+		r.Remaining = &rem
+	}
+}
+
+func (r tryResult) print() {
+	fmt.Printf("Try — %s %s\n", r.Method, r.URL)
+	fmt.Println("─────────────────────────────")
+	fmt.Printf("  Credential:  %s\n", r.Credential)
+	fmt.Printf("  Response:    HTTP %d %s (%s)\n", r.Status, http.StatusText(r.Status), r.Latency)
+	icon := "✓"
+	if !r.allowed() {
+		icon = "✗"
+	}
+	fmt.Printf("  Decision:    %s %s\n", icon, r.Decision)
+	if r.Reason != "" {
+		fmt.Printf("  Reason:      %s\n", r.Reason)
+	}
+	for i, c := range r.Caveats {
+		label := ""
+		if i == 0 {
+			label = "Failed:"
+		}
+		fmt.Printf("  %-12s %s\n", label, c)
+	}
+
+	cur := r.Currency
+	if r.Charge != nil {
+		fmt.Printf("  Charge:      %s\n", money.Format(*r.Charge, cur))
+	}
+	if r.Before != nil {
+		fmt.Printf("  Spent:       %s → %s", money.Format(*r.Before, cur), money.Format(*r.After, cur))
+		if r.Budget > 0 {
+			fmt.Printf(" of %s", money.Format(r.Budget, cur))
+		}
+		fmt.Println()
+		if r.Remaining != nil {
+			fmt.Printf("  Remaining:   %s\n", money.Format(*r.Remaining, cur))
+		} else {
+			fmt.Println("  Remaining:   unlimited")
+		}
+	} else if r.TokenID == "" {
+		fmt.Println("  Spend:       not compared (pass --token to read the token's spend)")
+	}
+
+	if len(r.Gateway) > 0 {
+		fmt.Println("\nGateway headers")
+		for _, k := range sortedKeys(r.Gateway) {
+			fmt.Printf("  %s: %s\n", k, r.Gateway[k])
+		}
+	}
+	if r.Body != "" {
+		fmt.Println("\nBody")
+		fmt.Println(truncate(r.Body, 2048))
+	}
+}
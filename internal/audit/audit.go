@@ -0,0 +1,415 @@
+// Package audit keeps an append-only, hash-chained JSON-lines log of every
+// mutating API call the CLI makes. Each entry's hash covers the previous
+// entry's hash, so editing or deleting a line breaks the chain from that
+// point on and Verify reports it.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SatGate-io/satgate-cli/internal/redact"
+)
+
+// FileName is the log file inside the audit directory
+const FileName = "audit.jsonl"
+
+// Entry is one audited API call
+type Entry struct {
+	Seq         int64           `json:"seq"`
+	Time        string          `json:"time"`
+	User        string          `json:"user"`
+	Profile     string          `json:"profile"`
+	Gateway     string          `json:"gateway"`
+	Surface     string          `json:"surface"`
+	Tenant      string          `json:"tenant,omitempty"`
+	Command     string          `json:"command"`
+	Args        []string        `json:"args"`
+	RequestID   string          `json:"request_id,omitempty"`
+	Method      string          `json:"method"`
+	Path        string          `json:"path"`
+	RequestBody json.RawMessage `json:"request_body,omitempty"`
+	Status      int             `json:"status"`
+	TokenIDs    []string        `json:"token_ids,omitempty"`
+	Override    *PolicyOverride `json:"policy_override,omitempty"`
+	Error       string          `json:"error,omitempty"`
+	PrevHash    string          `json:"prev_hash"`
+	Hash        string          `json:"hash"`
+}
+
+// PolicyOverride records a mint that went ahead despite policy violations
+type PolicyOverride struct {
+	Reason     string   `json:"reason"`
+	Violations []string `json:"violations"`
+}
+
+// Context describes the invocation that subsequent calls belong to
+type Context struct {
+	Dir     string // audit directory, e.g. ~/.satgate/audit
+	Profile string // config file in use
+	Gateway string
+	Surface string
+	Tenant  string
+	Command string
+	Args    []string
+
+	override *PolicyOverride
+}
+
+var (
+	mu      sync.Mutex
+	current *Context
+)
+
+// Begin enables auditing for this process
+func Begin(ctx Context) {
+	mu.Lock()
+	defer mu.Unlock()
+	ctx.Args = RedactArgs(ctx.Args)
+	current = &ctx
+}
+
+// SetPolicyOverride attaches an override to every entry recorded from now on
+// in this invocation
+func SetPolicyOverride(o PolicyOverride) {
+	mu.Lock()
+	defer mu.Unlock()
+	if current != nil {
+		current.override = &o
+	}
+}
+
+// DefaultDir returns ~/.satgate/audit
+func DefaultDir() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".satgate", "audit")
+}
+
+// RecordRequest appends an entry for a mutating API call. It is a no-op
+// until Begin is called. Failures to write are returned for the caller to
+// report; they never block the API call itself.
+func RecordRequest(requestID, method, path string, body []byte, status int, resp []byte, callErr error) error {
+	mu.Lock()
+	defer mu.Unlock()
+	if current == nil {
+		return nil
+	}
+
+	e := Entry{
+		Time:      time.Now().UTC().Format(time.RFC3339Nano),
+		User:      CurrentUser(),
+		Profile:   current.Profile,
+		Gateway:   current.Gateway,
+		Surface:   current.Surface,
+		Tenant:    current.Tenant,
+		Command:   current.Command,
+		Args:      current.Args,
+		RequestID: requestID,
+		Method:    method,
+		Path:      path,
+		Status:    status,
+		TokenIDs:  tokenIDs(path, resp),
+		Override:  current.override,
+	}
+	if len(body) > 0 {
+		e.RequestBody = RedactJSON(body)
+	}
+	if callErr != nil {
+		e.Error = callErr.Error()
+	}
+	return appendEntry(current.Dir, e)
+}
+
+// appendEntry chains e onto the last entry and appends it. The file stays
+// locked from reading the last entry to writing the new one, so concurrent
+// invocations cannot both link to the same predecessor.
+func appendEntry(dir string, e Entry) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("creating audit dir: %w", err)
+	}
+	f, err := os.OpenFile(filepath.Join(dir, FileName), os.O_CREATE|os.O_APPEND|os.O_RDWR, 0600)
+	if err != nil {
+		return fmt.Errorf("opening audit log: %w", err)
+	}
+	defer f.Close()
+	if err := lockFile(f); err != nil {
+		return fmt.Errorf("locking audit log: %w", err)
+	}
+
+	last, err := lastEntry(f)
+	if err != nil {
+		return err
+	}
+	if last != nil {
+		e.Seq = last.Seq + 1
+		e.PrevHash = last.Hash
+	} else {
+		e.Seq = 1
+	}
+	e.Hash = hashEntry(e)
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("writing audit log: %w", err)
+	}
+	return nil
+}
+
+// lastEntry reads backwards from the end of f to the last non-empty line,
+// so appending does not get slower as the log grows. It returns nil for an
+// empty log.
+func lastEntry(f *os.File) (*Entry, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("reading audit log: %w", err)
+	}
+	const chunk = 4096
+	var tail []byte
+	for end := info.Size(); end > 0; {
+		start := end - chunk
+		if start < 0 {
+			start = 0
+		}
+		buf := make([]byte, end-start)
+		if _, err := f.ReadAt(buf, start); err != nil {
+			return nil, fmt.Errorf("reading audit log: %w", err)
+		}
+		tail = append(buf, tail...)
+		end = start
+
+		trimmed := bytes.TrimRight(tail, " \t\r\n")
+		if len(trimmed) == 0 {
+			continue
+		}
+		if i := bytes.LastIndexByte(trimmed, '\n'); i >= 0 || end == 0 {
+			var e Entry
+			if err := json.Unmarshal(trimmed[i+1:], &e); err != nil {
+				return nil, fmt.Errorf("audit log last line: %w", err)
+			}
+			return &e, nil
+		}
+	}
+	return nil, nil
+}
+
+// Read returns every entry in the log. A missing log is empty, not an error.
+func Read(dir string) ([]Entry, error) {
+	f, err := os.Open(filepath.Join(dir, FileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("opening audit log: %w", err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 1024*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("audit log line %d: %w", line, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// Problem is a chain integrity failure found by Verify
+type Problem struct {
+	Seq    int64
+	Reason string
+}
+
+// Verify recomputes every hash and checks each entry links to the one
+// before it. It returns nil problems for an intact chain.
+func Verify(entries []Entry) []Problem {
+	var problems []Problem
+	prev := ""
+	var prevSeq int64
+	for _, e := range entries {
+		if e.PrevHash != prev {
+			problems = append(problems, Problem{e.Seq, "prev_hash does not match the preceding entry (entry removed or reordered)"})
+		}
+		if e.Seq != prevSeq+1 {
+			problems = append(problems, Problem{e.Seq, fmt.Sprintf("sequence jumps from %d to %d", prevSeq, e.Seq)})
+		}
+		if hashEntry(e) != e.Hash {
+			problems = append(problems, Problem{e.Seq, "hash mismatch (entry modified)"})
+		}
+		prev = e.Hash
+		prevSeq = e.Seq
+	}
+	return problems
+}
+
+// hashEntry is sha256 over the entry's JSON with Hash cleared. PrevHash is
+// part of that JSON, which is what chains entries together.
+func hashEntry(e Entry) string {
+	e.Hash = ""
+	data, _ := json.Marshal(e)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// CurrentUser returns the OS user running the CLI
+func CurrentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+// isSecretKey reports whether a flag or JSON key name holds a credential
+func isSecretKey(name string) bool {
+	name = strings.ToLower(name)
+	for _, s := range []string{"token", "secret", "macaroon", "password", "preimage", "key", "auth"} {
+		if strings.Contains(name, s) && !strings.HasSuffix(name, "_id") && !strings.HasSuffix(name, "-id") {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactArgs hides the values of credential-looking flags, in both
+// "--flag value" and "--flag=value" form, and of -H/--header values that
+// set a credential header such as "Authorization: L402 ..."
+func RedactArgs(args []string) []string {
+	out := make([]string, len(args))
+	redactNext, headerNext := false, false
+	for i, a := range args {
+		switch {
+		case redactNext:
+			out[i] = "[REDACTED]"
+			redactNext = false
+		case headerNext:
+			out[i] = redactHeaderArg(a)
+			headerNext = false
+		case strings.HasPrefix(a, "-"):
+			name, value, hasValue := strings.Cut(strings.TrimLeft(a, "-"), "=")
+			if name == "H" || name == "header" {
+				if hasValue {
+					out[i] = a[:strings.Index(a, "=")+1] + redactHeaderArg(value)
+				} else {
+					out[i] = a
+					headerNext = true
+				}
+				continue
+			}
+			if isSecretKey(name) {
+				if hasValue {
+					out[i] = a[:strings.Index(a, "=")+1] + "[REDACTED]"
+				} else {
+					out[i] = a
+					redactNext = true
+				}
+				continue
+			}
+			out[i] = a
+		default:
+			out[i] = a
+		}
+	}
+	return out
+}
+
+// redactHeaderArg hides the value of a "Name: value" header argument when
+// the header carries a credential
+func redactHeaderArg(h string) string {
+	name, _, ok := strings.Cut(h, ":")
+	if ok && (redact.SecretHeader(strings.TrimSpace(name)) || isSecretKey(name)) {
+		return name + ": [REDACTED]"
+	}
+	return h
+}
+
+// RedactJSON replaces credential-looking values anywhere in a JSON document.
+// Non-JSON bodies are dropped entirely rather than stored unredacted.
+func RedactJSON(data []byte) json.RawMessage {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		out, _ := json.Marshal("[non-JSON body omitted]")
+		return out
+	}
+	out, _ := json.Marshal(redactValue(v))
+	return out
+}
+
+func redactValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, child := range val {
+			if _, isObj := child.(map[string]interface{}); !isObj && isSecretKey(k) {
+				val[k] = "[REDACTED]"
+			} else {
+				val[k] = redactValue(child)
+			}
+		}
+		return val
+	case []interface{}:
+		for i := range val {
+			val[i] = redactValue(val[i])
+		}
+		return val
+	}
+	return v
+}
+
+// tokenIDs extracts the affected token IDs: a top-level id/token_id or
+// nested token.id in the response, or the ID in the path (revocations),
+// each listed once
+func tokenIDs(path string, resp []byte) []string {
+	var ids []string
+	add := func(id string) {
+		for _, existing := range ids {
+			if existing == id {
+				return
+			}
+		}
+		ids = append(ids, id)
+	}
+	segments := strings.Split(strings.SplitN(path, "?", 2)[0], "/")
+	for i := 1; i < len(segments); i++ {
+		switch segments[i-1] {
+		case "tokens", "token", "revoke":
+			if segments[i] != "" && segments[i] != "mint" {
+				add(segments[i])
+			}
+		}
+	}
+
+	var body map[string]interface{}
+	if json.Unmarshal(resp, &body) != nil {
+		return ids
+	}
+	for _, key := range []string{"id", "token_id"} {
+		if id, ok := body[key].(string); ok && id != "" {
+			add(id)
+		}
+	}
+	if nested, ok := body["token"].(map[string]interface{}); ok {
+		if id, ok := nested["id"].(string); ok && id != "" {
+			add(id)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,97 @@
+package audit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestConcurrentAppendsKeepChain(t *testing.T) {
+	dir := t.TempDir()
+	var wg sync.WaitGroup
+	errs := make(chan error, 40)
+	for i := 0; i < 40; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- appendEntry(dir, Entry{Command: "satgate mint", Method: "POST", Path: fmt.Sprintf("/admin/tokens/mint?n=%d", i)})
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := Read(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 40 {
+		t.Fatalf("got %d entries, want 40", len(entries))
+	}
+	if problems := Verify(entries); problems != nil {
+		t.Errorf("chain broken by concurrent appends: %+v", problems)
+	}
+}
+
+func TestAppendChainsOntoLastLine(t *testing.T) {
+	dir := t.TempDir()
+	if err := appendEntry(dir, Entry{Path: "/admin/tokens/mint", Command: "satgate mint"}); err != nil {
+		t.Fatal(err)
+	}
+	// A long entry spanning several read chunks, then trailing blank lines
+	big := Entry{Path: "/admin/tokens/mint", Args: []string{strings.Repeat("x", 10000)}}
+	if err := appendEntry(dir, big); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.OpenFile(filepath.Join(dir, FileName), os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("\n\n")
+	f.Close()
+	if err := appendEntry(dir, Entry{Path: "/admin/tokens/tok_1/revoke"}); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := Read(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 || entries[2].Seq != 3 {
+		t.Fatalf("got %d entries, last seq %d", len(entries), entries[len(entries)-1].Seq)
+	}
+	if problems := Verify(entries); problems != nil {
+		t.Errorf("chain broken: %+v", problems)
+	}
+}
+
+func TestTokenIDsListsEachOnce(t *testing.T) {
+	got := tokenIDs("/admin/tokens/tok_1/revoke", []byte(`{"id":"tok_1","token_id":"tok_1","token":{"id":"tok_2"}}`))
+	if want := []string{"tok_1", "tok_2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("tokenIDs = %v; want %v", got, want)
+	}
+}
+
+func TestRedactArgs(t *testing.T) {
+	got := RedactArgs([]string{
+		"try", "/api/x", "--auth", "L402 mac:pre", "--admin-token=sgk_1",
+		"-H", "Authorization: L402 mac:pre", "--header=X-Admin-Token: sgk_2",
+		"-H", "Accept: application/json", "--token-id", "tok_1",
+	})
+	want := []string{
+		"try", "/api/x", "--auth", "[REDACTED]", "--admin-token=[REDACTED]",
+		"-H", "Authorization: [REDACTED]", "--header=X-Admin-Token: [REDACTED]",
+		"-H", "Accept: application/json", "--token-id", "tok_1",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("RedactArgs =\n %q\nwant\n %q", got, want)
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package audit
+
+import "os"
+
+// lockFile is a no-op where flock is unavailable; appends within one
+// process are still serialised by mu
+func lockFile(f *os.File) error {
+	return nil
+}
@@ -0,0 +1,333 @@
+// Package cassette records the CLI's HTTP exchanges with the gateway or
+// cloud API to a JSON file and replays them offline, so a misbehaving
+// command can be attached to a support ticket and reproduced without
+// access to the tenant. Credentials and macaroons are redacted before
+// anything is written.
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SatGate-io/satgate-cli/internal/redact"
+)
+
+// FormatVersion is the cassette file format written by Record
+const FormatVersion = 1
+
+// Redacted replaces every credential in a cassette
+const Redacted = redact.Placeholder
+
+// Cassette is a recorded CLI invocation
+type Cassette struct {
+	Version      int           `json:"version"`
+	RecordedAt   time.Time     `json:"recorded_at"`
+	CLIVersion   string        `json:"cli_version,omitempty"`
+	Command      string        `json:"command"`
+	Args         []string      `json:"args"`
+	Gateway      string        `json:"gateway"`
+	Surface      string        `json:"surface"`
+	Tenant       string        `json:"tenant,omitempty"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is one HTTP exchange
+type Interaction struct {
+	Request    Request   `json:"request"`
+	Response   *Response `json:"response,omitempty"`
+	Error      string    `json:"error,omitempty"` // network error instead of a response
+	DurationMS int64     `json:"duration_ms"`
+}
+
+// Request is the recorded request, relative to the cassette's gateway
+type Request struct {
+	Method string            `json:"method"`
+	Path   string            `json:"path"`
+	Header map[string]string `json:"header,omitempty"`
+	Body   json.RawMessage   `json:"body,omitempty"`
+	Text   string            `json:"body_text,omitempty"` // non-JSON body
+}
+
+// Response is the recorded response
+type Response struct {
+	Status int               `json:"status"`
+	Header map[string]string `json:"header,omitempty"`
+	Body   json.RawMessage   `json:"body,omitempty"`
+	Text   string            `json:"body_text,omitempty"` // non-JSON body
+}
+
+// Meta describes the invocation being recorded
+type Meta struct {
+	CLIVersion string
+	Command    string
+	Args       []string // credential flags already redacted; Record also scrubs Secrets and macaroons
+	Gateway    string
+	Surface    string
+	Tenant     string
+	Secrets    []string // credential values to scrub wherever they appear
+}
+
+type recorder struct {
+	*redact.Redactor
+	mu   sync.Mutex
+	path string
+	c    Cassette
+}
+
+type player struct {
+	mu   sync.Mutex
+	c    *Cassette
+	used []bool
+}
+
+var (
+	mu        sync.Mutex
+	recording *recorder
+	replaying *player
+)
+
+// Record starts recording every exchange made through Transport to path.
+// The file is rewritten after each exchange, so it is complete even if
+// the command exits early.
+func Record(path string, meta Meta) error {
+	r := &recorder{
+		Redactor: redact.New(meta.Secrets...),
+		path:     path,
+		c: Cassette{
+			Version:      FormatVersion,
+			RecordedAt:   time.Now().UTC().Truncate(time.Second),
+			CLIVersion:   meta.CLIVersion,
+			Command:      meta.Command,
+			Args:         make([]string, len(meta.Args)),
+			Gateway:      meta.Gateway,
+			Surface:      meta.Surface,
+			Tenant:       meta.Tenant,
+			Interactions: []Interaction{},
+		},
+	}
+	for i, a := range meta.Args {
+		r.c.Args[i] = r.Text(a)
+	}
+	if err := r.save(); err != nil {
+		return err
+	}
+	mu.Lock()
+	recording, replaying = r, nil
+	mu.Unlock()
+	return nil
+}
+
+// Replay loads a cassette and serves its responses through Transport
+// instead of the network
+func Replay(path string) (*Cassette, error) {
+	c, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	mu.Lock()
+	replaying, recording = &player{c: c, used: make([]bool, len(c.Interactions))}, nil
+	mu.Unlock()
+	return c, nil
+}
+
+// Load reads a cassette file
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading cassette: %w", err)
+	}
+	var c Cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("parsing cassette %s: %w", path, err)
+	}
+	if c.Version != FormatVersion {
+		return nil, fmt.Errorf("cassette %s has format version %d; this CLI reads version %d", path, c.Version, FormatVersion)
+	}
+	return &c, nil
+}
+
+// Stop ends recording or replay. For a recording it returns the file and
+// the number of exchanges written.
+func Stop() (path string, n int) {
+	mu.Lock()
+	defer mu.Unlock()
+	if recording != nil {
+		recording.mu.Lock()
+		path, n = recording.path, len(recording.c.Interactions)
+		recording.mu.Unlock()
+	}
+	recording, replaying = nil, nil
+	return path, n
+}
+
+// Transport wraps base so exchanges are recorded or replayed. It returns
+// base unchanged when neither is active.
+func Transport(base http.RoundTripper) http.RoundTripper {
+	mu.Lock()
+	defer mu.Unlock()
+	switch {
+	case replaying != nil:
+		return replaying
+	case recording != nil:
+		return &recordingTransport{base: base, r: recording}
+	}
+	return base
+}
+
+type recordingTransport struct {
+	base http.RoundTripper
+	r    *recorder
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		reqBody, _ = io.ReadAll(req.Body)
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	in := Interaction{
+		Request: Request{
+			Method: req.Method,
+			Path:   req.URL.RequestURI(),
+			Header: t.r.headers(req.Header),
+		},
+		DurationMS: time.Since(start).Milliseconds(),
+	}
+	in.Request.Body, in.Request.Text = t.r.body(reqBody)
+	if err != nil {
+		in.Error = t.r.Text(err.Error())
+		t.r.add(in)
+		return nil, err
+	}
+
+	respBody, rerr := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	in.Response = &Response{Status: resp.StatusCode, Header: t.r.headers(resp.Header)}
+	in.Response.Body, in.Response.Text = t.r.body(respBody)
+	in.DurationMS = time.Since(start).Milliseconds()
+	if rerr != nil {
+		in.Error = t.r.Text(rerr.Error())
+	}
+	t.r.add(in)
+	return resp, rerr
+}
+
+func (r *recorder) add(in Interaction) {
+	r.mu.Lock()
+	r.c.Interactions = append(r.c.Interactions, in)
+	r.mu.Unlock()
+	if err := r.save(); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  %v\n", err)
+	}
+}
+
+// save rewrites the file; the lock keeps concurrent mints from writing an
+// older snapshot over a newer one
+func (r *recorder) save() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	data, err := json.MarshalIndent(r.c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding cassette: %w", err)
+	}
+	if err := os.WriteFile(r.path, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("writing cassette: %w", err)
+	}
+	return nil
+}
+
+func (r *recorder) headers(h http.Header) map[string]string {
+	if len(h) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(h))
+	for k, v := range h {
+		out[k] = r.Header(k, strings.Join(v, ", "))
+	}
+	return out
+}
+
+// body stores JSON as JSON so cassettes stay readable, and anything else
+// as text
+func (r *recorder) body(data []byte) (json.RawMessage, string) {
+	if len(data) == 0 {
+		return nil, ""
+	}
+	if out, ok := r.JSON(data); ok {
+		return out, ""
+	}
+	return nil, r.Text(string(data))
+}
+
+// RoundTrip serves the first unused interaction with the same method,
+// path and query, falling back to the same method and path when the
+// query differs (e.g. a time window computed from the current time).
+func (p *player) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	uri := req.URL.RequestURI()
+	path := req.URL.Path
+
+	p.mu.Lock()
+	i := p.find(func(r Request) bool { return r.Method == req.Method && r.Path == uri })
+	if i < 0 {
+		i = p.find(func(r Request) bool { return r.Method == req.Method && strings.SplitN(r.Path, "?", 2)[0] == path })
+	}
+	if i >= 0 {
+		p.used[i] = true
+	}
+	p.mu.Unlock()
+
+	if i < 0 {
+		return nil, fmt.Errorf("no recorded response for %s %s in the cassette", req.Method, uri)
+	}
+	in := p.c.Interactions[i]
+	if in.Response == nil {
+		return nil, errors.New(in.Error)
+	}
+
+	body := []byte(in.Response.Text)
+	if len(in.Response.Body) > 0 {
+		var buf bytes.Buffer
+		json.Compact(&buf, in.Response.Body)
+		body = buf.Bytes()
+	}
+	header := http.Header{}
+	for k, v := range in.Response.Header {
+		header.Set(k, v)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", in.Response.Status, http.StatusText(in.Response.Status)),
+		StatusCode:    in.Response.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+func (p *player) find(match func(Request) bool) int {
+	for i, in := range p.c.Interactions {
+		if !p.used[i] && match(in.Request) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,147 @@
+package cassette
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testMacaroon = "AgEHc2F0Z2F0ZQJCAACrq6urq6urq6urq6urq6urq6urq6urq6urq6ur"
+
+func newGateway(t *testing.T) *httptest.Server {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/admin/tokens/mint":
+			w.Header().Set("Set-Cookie", "satgate_session=abc")
+			w.WriteHeader(201)
+			io.WriteString(w, `{"id":"tok_new","name":"ci","macaroon":"`+testMacaroon+`","token":"`+testMacaroon+`","tokenCount":1}`)
+		case "/admin/spend":
+			io.WriteString(w, `{"agents":[{"name":"cs-bot","token_id":"tok_1","spent":`+r.URL.Query().Get("n")+`}]}`)
+		default:
+			w.WriteHeader(500)
+			io.WriteString(w, "upstream sgk_secret failed")
+		}
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func do(t *testing.T, method, url, body string) (int, string, error) {
+	t.Helper()
+	client := &http.Client{Transport: Transport(http.DefaultTransport)}
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("X-Admin-Token", "sgk_secret")
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(data), nil
+}
+
+func TestRecordRedactsAndReplays(t *testing.T) {
+	ts := newGateway(t)
+	path := filepath.Join(t.TempDir(), "ticket.json")
+	if err := Record(path, Meta{
+		Command: "satgate mint", Args: []string{"mint", "--note", "key sgk_secret", "--parent-macaroon", testMacaroon},
+		Gateway: ts.URL, Surface: "gateway", Secrets: []string{"sgk_secret"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	live := map[string]string{}
+	for _, req := range [][2]string{
+		{"POST", "/admin/tokens/mint"},
+		{"GET", "/admin/spend?n=1"},
+		{"GET", "/admin/spend?n=2"},
+		{"GET", "/admin/broken"},
+	} {
+		_, body, err := do(t, req[0], ts.URL+req[1], `{"name":"ci"}`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		live[req[1]] = body
+	}
+	if gotPath, n := Stop(); gotPath != path || n != 4 {
+		t.Fatalf("Stop() = %q, %d; want %q, 4", gotPath, n, path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := string(data)
+	for _, secret := range []string{"sgk_secret", testMacaroon, "satgate_session=abc"} {
+		if strings.Contains(file, secret) {
+			t.Errorf("cassette contains %q:\n%s", secret, file)
+		}
+	}
+	for _, kept := range []string{`"id": "tok_new"`, `"tokenCount": 1`, `"token_id": "tok_1"`, `"body_text": "upstream [REDACTED] failed"`, `"key [REDACTED]"`} {
+		if !strings.Contains(file, kept) {
+			t.Errorf("cassette is missing %s:\n%s", kept, file)
+		}
+	}
+
+	ts.Close()
+	c, err := Replay(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer Stop()
+	if c.Gateway != ts.URL || len(c.Interactions) != 4 {
+		t.Fatalf("loaded gateway %q with %d interactions", c.Gateway, len(c.Interactions))
+	}
+
+	code, body, err := do(t, "POST", ts.URL+"/admin/tokens/mint", "")
+	if err != nil || code != 201 || !strings.Contains(body, `"macaroon":"[REDACTED]"`) {
+		t.Errorf("replayed mint = %d %s, %v", code, body, err)
+	}
+	// Exact queries first, then the remaining exchanges for the path in order
+	if _, body, _ := do(t, "GET", ts.URL+"/admin/spend?n=2", ""); !sameJSON(body, live["/admin/spend?n=2"]) {
+		t.Errorf("replayed ?n=2 = %s; want %s", body, live["/admin/spend?n=2"])
+	}
+	if _, body, _ := do(t, "GET", ts.URL+"/admin/spend?n=9", ""); !sameJSON(body, live["/admin/spend?n=1"]) {
+		t.Errorf("replayed ?n=9 = %s; want the ?n=1 response %s", body, live["/admin/spend?n=1"])
+	}
+	if code, body, _ := do(t, "GET", ts.URL+"/admin/broken", ""); code != 500 || body != "upstream [REDACTED] failed" {
+		t.Errorf("replayed error = %d %q", code, body)
+	}
+	if _, _, err := do(t, "GET", ts.URL+"/admin/spend", ""); err == nil || !strings.Contains(err.Error(), "no recorded response for GET /admin/spend") {
+		t.Errorf("exhausted cassette: err = %v", err)
+	}
+}
+
+func sameJSON(a, b string) bool {
+	var va, vb interface{}
+	if json.Unmarshal([]byte(a), &va) != nil || json.Unmarshal([]byte(b), &vb) != nil {
+		return false
+	}
+	ja, _ := json.Marshal(va)
+	jb, _ := json.Marshal(vb)
+	return string(ja) == string(jb)
+}
+
+func TestTransportInactive(t *testing.T) {
+	Stop()
+	if Transport(http.DefaultTransport) != http.DefaultTransport {
+		t.Error("Transport wrapped the base transport with no cassette active")
+	}
+}
+
+func TestLoadRejectsOtherVersions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "old.json")
+	os.WriteFile(path, []byte(`{"version": 99, "interactions": []}`), 0600)
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "format version 99") {
+		t.Errorf("Load = %v", err)
+	}
+}
@@ -1,14 +1,20 @@
 package client
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
 	"strings"
 	"time"
 
+	"github.com/SatGate-io/satgate-cli/internal/audit"
+	"github.com/SatGate-io/satgate-cli/internal/cassette"
 	"github.com/SatGate-io/satgate-cli/internal/config"
+	"github.com/SatGate-io/satgate-cli/internal/telemetry"
 )
 
 // Client wraps HTTP calls to the SatGate Admin API
@@ -31,7 +37,8 @@ func New() (*Client, error) {
 	return &Client{
 		cfg: cfg,
 		http: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   30 * time.Second,
+			Transport: cassette.Transport(http.DefaultTransport),
 		},
 	}, nil
 }
@@ -50,6 +57,15 @@ func (c *Client) Post(path string, body interface{}) ([]byte, int, error) {
 	return c.do("POST", path, string(data))
 }
 
+// Patch performs a PATCH request with a JSON body
+func (c *Client) Patch(path string, body interface{}) ([]byte, int, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("marshaling request: %w", err)
+	}
+	return c.do("PATCH", path, string(data))
+}
+
 // Delete performs a DELETE request
 func (c *Client) Delete(path string) ([]byte, int, error) {
 	return c.do("DELETE", path, "")
@@ -57,7 +73,44 @@ func (c *Client) Delete(path string) ([]byte, int, error) {
 
 func (c *Client) do(method, path, body string) ([]byte, int, error) {
 	url := strings.TrimRight(c.cfg.Gateway, "/") + path
+	template := pathTemplate(path)
+	requestID := newRequestID()
+
+	span := telemetry.StartSpan(method + " " + template)
+	defer span.End()
+	span.SetAttr("http.request.method", method)
+	span.SetAttr("url.template", template)
+	span.SetAttr("server.address", c.cfg.Gateway)
+	span.SetAttr("satgate.surface", c.cfg.Surface)
+	span.SetAttr("satgate.request_id", requestID)
+	if c.cfg.Tenant != "" {
+		span.SetAttr("satgate.tenant", c.cfg.Tenant)
+	}
+
+	start := time.Now()
+	data, code, err := c.send(method, url, body, requestID, span)
+
+	// Calls are never retried, so every span reports zero retries
+	span.SetAttr("satgate.retries", 0)
+	span.SetAttr("http.response.status_code", code)
+	if err != nil {
+		span.SetError(err.Error())
+	} else if code >= 500 {
+		span.SetError(fmt.Sprintf("HTTP %d", code))
+	}
+	telemetry.RecordRequest(method, template, code, c.cfg.Surface, time.Since(start))
+
+	if method != "GET" {
+		if aerr := audit.RecordRequest(requestID, method, path, []byte(body), code, data, err); aerr != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  audit log: %v\n", aerr)
+		}
+	}
+
+	return data, code, err
+}
 
+// send performs the HTTP exchange
+func (c *Client) send(method, url, body, requestID string, span *telemetry.Span) ([]byte, int, error) {
 	var bodyReader io.Reader
 	if body != "" {
 		bodyReader = strings.NewReader(body)
@@ -81,16 +134,54 @@ func (c *Client) do(method, path, body string) ([]byte, int, error) {
 		req.Header.Set("X-SatGate-Tenant", c.cfg.Tenant)
 	}
 
+	// Request ID lets server-side audit events be matched to the local log
+	req.Header.Set("X-Request-Id", requestID)
+
+	// Propagate W3C trace context so gateway spans join the CLI trace
+	if tp := span.Traceparent(); tp != "" {
+		req.Header.Set("traceparent", tp)
+	}
+
+	c.logRequest(req, body)
+	start := time.Now()
 	resp, err := c.http.Do(req)
 	if err != nil {
+		c.logResponse(nil, nil, err, time.Since(start))
 		return nil, 0, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	data, err := io.ReadAll(resp.Body)
+	c.logResponse(resp, data, err, time.Since(start))
 	if err != nil {
 		return nil, resp.StatusCode, fmt.Errorf("reading response: %w", err)
 	}
 
 	return data, resp.StatusCode, nil
 }
+
+// newRequestID returns a random request identifier, e.g. req_4f1c…
+func newRequestID() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return "req_" + hex.EncodeToString(b)
+}
+
+// pathTemplate replaces token IDs and query strings in an API path so spans
+// and metrics group by endpoint rather than by token,
+// e.g. /admin/tokens/tok_123/revoke → /admin/tokens/{id}/revoke
+func pathTemplate(path string) string {
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+	segments := strings.Split(path, "/")
+	for i := 1; i < len(segments); i++ {
+		switch segments[i-1] {
+		case "tokens", "token", "revoke":
+			if segments[i] != "" && segments[i] != "mint" {
+				segments[i] = "{id}"
+			}
+		}
+	}
+	return strings.Join(segments, "/")
+}
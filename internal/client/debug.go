@@ -0,0 +1,173 @@
+package client
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SatGate-io/satgate-cli/internal/redact"
+)
+
+// LogLevel controls how much of each HTTP exchange is logged
+type LogLevel int
+
+const (
+	LogOff     LogLevel = iota
+	LogVerbose          // method, URL, status, latency and response size
+	LogDebug            // also headers and bodies
+)
+
+// maxLoggedBody caps how much of a body --debug prints
+const maxLoggedBody = 4096
+
+// DebugOptions configures request logging
+type DebugOptions struct {
+	Level LogLevel
+	Curl  bool      // print an equivalent curl command for each request
+	Out   io.Writer // default os.Stderr
+}
+
+var (
+	debugMu sync.Mutex
+	debug   DebugOptions
+)
+
+// SetDebug enables or, with the zero value, disables request logging
+func SetDebug(opts DebugOptions) {
+	if opts.Out == nil {
+		opts.Out = os.Stderr
+	}
+	debugMu.Lock()
+	debug = opts
+	debugMu.Unlock()
+}
+
+func debugOptions() DebugOptions {
+	debugMu.Lock()
+	defer debugMu.Unlock()
+	return debug
+}
+
+// logRequest prints the request about to be sent, and a curl equivalent
+// when asked
+func (c *Client) logRequest(req *http.Request, body string) {
+	opts := debugOptions()
+	if opts.Level == LogOff && !opts.Curl {
+		return
+	}
+	r := c.redactor()
+	var b strings.Builder
+	if opts.Curl {
+		b.WriteString(c.curl(req, body) + "\n")
+	}
+	if opts.Level >= LogVerbose {
+		fmt.Fprintf(&b, "→ %s %s\n", req.Method, req.URL)
+	}
+	if opts.Level >= LogDebug {
+		writeHeaders(&b, r, req.Header)
+		writeBody(&b, r, []byte(body))
+	}
+	io.WriteString(opts.Out, b.String())
+}
+
+// logResponse prints the outcome of an exchange
+func (c *Client) logResponse(resp *http.Response, data []byte, err error, elapsed time.Duration) {
+	opts := debugOptions()
+	if opts.Level == LogOff {
+		return
+	}
+	var b strings.Builder
+	elapsed = elapsed.Round(time.Millisecond)
+	switch {
+	case resp == nil:
+		fmt.Fprintf(&b, "← %v (%s)\n", err, elapsed)
+	default:
+		fmt.Fprintf(&b, "← %s (%s, %d bytes)\n", resp.Status, elapsed, len(data))
+		if opts.Level >= LogDebug {
+			r := c.redactor()
+			writeHeaders(&b, r, resp.Header)
+			writeBody(&b, r, data)
+		}
+		if err != nil {
+			fmt.Fprintf(&b, "  %v\n", err)
+		}
+	}
+	io.WriteString(opts.Out, b.String())
+}
+
+func (c *Client) redactor() *redact.Redactor {
+	return redact.New(c.cfg.AdminToken, c.cfg.BearerToken, c.cfg.SessionToken)
+}
+
+func writeHeaders(b *strings.Builder, r *redact.Redactor, h http.Header) {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(b, "  %s: %s\n", k, r.Header(k, strings.Join(h[k], ", ")))
+	}
+}
+
+func writeBody(b *strings.Builder, r *redact.Redactor, data []byte) {
+	if len(data) == 0 {
+		return
+	}
+	body := r.Body(data)
+	if len(body) > maxLoggedBody {
+		body = fmt.Sprintf("%s… (%d more bytes)", body[:maxLoggedBody], len(body)-maxLoggedBody)
+	}
+	b.WriteString("\n  " + strings.ReplaceAll(body, "\n", "\n  ") + "\n\n")
+}
+
+// curl returns a shell command that repeats req. Credentials are read
+// from the SATGATE_* environment variables instead of being printed.
+func (c *Client) curl(req *http.Request, body string) string {
+	parts := []string{"curl"}
+	if req.Method != "GET" {
+		parts = append(parts, "-X", req.Method)
+	}
+	parts = append(parts, shellQuote(req.URL.String()))
+
+	keys := make([]string, 0, len(req.Header))
+	for k := range req.Header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if redact.SecretHeader(k) {
+			parts = append(parts, "-H", c.curlAuth(k))
+			continue
+		}
+		parts = append(parts, "-H", shellQuote(k+": "+strings.Join(req.Header[k], ", ")))
+	}
+	if body != "" {
+		parts = append(parts, "--data", shellQuote(body))
+	}
+	return strings.Join(parts, " ")
+}
+
+// curlAuth refers to the credential by environment variable, so the
+// command can be shared and still runs where the variable is set
+func (c *Client) curlAuth(header string) string {
+	switch http.CanonicalHeaderKey(header) {
+	case "X-Admin-Token":
+		return `"X-Admin-Token: $SATGATE_ADMIN_TOKEN"`
+	case "Cookie":
+		return `"Cookie: satgate_session=$SATGATE_SESSION_TOKEN"`
+	case "Authorization":
+		return `"Authorization: Bearer $SATGATE_BEARER_TOKEN"`
+	}
+	return shellQuote(header + ": " + redact.Placeholder)
+}
+
+// shellQuote single-quotes s for POSIX shells
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
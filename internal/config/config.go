@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/SatGate-io/satgate-cli/internal/templates"
 	"gopkg.in/yaml.v3"
 )
 
@@ -18,6 +19,27 @@ type Config struct {
 	SessionToken string `yaml:"session_token"` // Session JWT (cloud surface, from magic link)
 	Tenant       string `yaml:"tenant"`        // tenant slug (cloud surface)
 	Format       string `yaml:"format"`        // table | json | yaml
+	PolicyFile   string `yaml:"policy_file"`   // org mint policy (default ~/.satgate/policy.yaml)
+	TemplatesDir string `yaml:"templates_dir"` // mint templates (default ~/.satgate/templates)
+	Rates        string `yaml:"rates"`         // rate source for --currency: static, file:PATH, gateway or a URL
+
+	Templates map[string]templates.Template `yaml:"templates"`
+
+	Telemetry TelemetryConfig `yaml:"telemetry"`
+	Audit     AuditConfig     `yaml:"audit"`
+}
+
+// AuditConfig controls the local audit log of mutating commands
+type AuditConfig struct {
+	Disabled bool   `yaml:"disabled"`
+	Dir      string `yaml:"dir"` // default ~/.satgate/audit
+}
+
+// TelemetryConfig controls OpenTelemetry export of CLI API calls
+type TelemetryConfig struct {
+	Enabled  bool   `yaml:"enabled"`
+	Endpoint string `yaml:"otlp_endpoint"` // OTLP/HTTP collector, e.g. http://localhost:4318
+	File     string `yaml:"file"`          // write OTLP JSON lines here instead of a collector
 }
 
 var current *Config
@@ -70,6 +92,24 @@ func Load(cfgFile string) {
 	if v := os.Getenv("SATGATE_FORMAT"); v != "" {
 		cfg.Format = v
 	}
+	if v := os.Getenv("SATGATE_POLICY_FILE"); v != "" {
+		cfg.PolicyFile = v
+	}
+	if v := os.Getenv("SATGATE_TEMPLATES_DIR"); v != "" {
+		cfg.TemplatesDir = v
+	}
+	if v := os.Getenv("SATGATE_RATES"); v != "" {
+		cfg.Rates = v
+	}
+	if v := os.Getenv("SATGATE_TRACE"); v == "1" || v == "true" {
+		cfg.Telemetry.Enabled = true
+	}
+	if v := os.Getenv("SATGATE_TRACE_FILE"); v != "" {
+		cfg.Telemetry.File = v
+	}
+	if v := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); v != "" {
+		cfg.Telemetry.Endpoint = v
+	}
 
 	// 3. Auto-detect surface from gateway URL
 	if cfg.Surface == "" {
@@ -87,6 +127,9 @@ func Load(cfgFile string) {
 	if cfg.Format == "" {
 		cfg.Format = "table"
 	}
+	if cfg.Telemetry.Endpoint == "" {
+		cfg.Telemetry.Endpoint = "http://localhost:4318"
+	}
 
 	current = cfg
 }
@@ -0,0 +1,222 @@
+package l402
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Invoice is the decoded content of a BOLT11 payment request. The
+// signature is not checked, so Payee is only known when the invoice
+// carries an explicit n field.
+type Invoice struct {
+	Network         string    `json:"network"` // mainnet, testnet, signet, regtest
+	AmountMsat      int64     `json:"amount_msat"`
+	HasAmount       bool      `json:"has_amount"`
+	Timestamp       time.Time `json:"timestamp"`
+	Expiry          Duration  `json:"expiry"`
+	Description     string    `json:"description,omitempty"`
+	DescriptionHash string    `json:"description_hash,omitempty"`
+	PaymentHash     string    `json:"payment_hash"`
+	PaymentSecret   string    `json:"payment_secret,omitempty"`
+	Payee           string    `json:"payee,omitempty"`
+	MinFinalCLTV    int64     `json:"min_final_cltv_expiry"`
+}
+
+// Duration marshals as seconds
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.FormatInt(int64(time.Duration(d)/time.Second), 10)), nil
+}
+
+// ExpiresAt is when the invoice can no longer be paid
+func (inv Invoice) ExpiresAt() time.Time {
+	return inv.Timestamp.Add(time.Duration(inv.Expiry))
+}
+
+// AmountSats returns the amount in sats, which may be fractional
+func (inv Invoice) AmountSats() float64 {
+	return float64(inv.AmountMsat) / 1000
+}
+
+// networks maps the BOLT11 prefix after "ln" to a network name, longest
+// prefixes first so "bcrt" is not read as "bc"
+var networks = []struct{ prefix, name string }{
+	{"bcrt", "regtest"},
+	{"tbs", "signet"},
+	{"tb", "testnet"},
+	{"bc", "mainnet"},
+	{"sb", "simnet"},
+}
+
+// msats per unit of each amount multiplier; no multiplier means whole BTC
+var multipliers = map[byte]float64{'m': 1e8, 'u': 1e5, 'n': 100, 'p': 0.1}
+
+// DecodeInvoice decodes a BOLT11 payment request, with or without a
+// "lightning:" prefix
+func DecodeInvoice(s string) (Invoice, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = strings.TrimPrefix(s, "lightning:")
+	hrp, data, err := bech32Decode(s)
+	if err != nil {
+		return Invoice{}, fmt.Errorf("invalid invoice: %w", err)
+	}
+	inv := Invoice{Expiry: Duration(time.Hour), MinFinalCLTV: 18}
+
+	if !strings.HasPrefix(hrp, "ln") {
+		return inv, fmt.Errorf("invalid invoice: prefix %q is not ln", hrp)
+	}
+	rest := hrp[2:]
+	for _, n := range networks {
+		if strings.HasPrefix(rest, n.prefix) {
+			inv.Network, rest = n.name, rest[len(n.prefix):]
+			break
+		}
+	}
+	if inv.Network == "" {
+		return inv, fmt.Errorf("invalid invoice: unknown network in %q", hrp)
+	}
+	if rest != "" {
+		if inv.AmountMsat, err = parseInvoiceAmount(rest); err != nil {
+			return inv, err
+		}
+		inv.HasAmount = true
+	}
+
+	// 35-bit timestamp, tagged fields, then a 520-bit signature
+	if len(data) < 7+104 {
+		return inv, fmt.Errorf("invalid invoice: too short")
+	}
+	inv.Timestamp = time.Unix(int64(readUint(data[:7])), 0).UTC()
+	fields := data[7 : len(data)-104]
+	for len(fields) >= 3 {
+		tag, n := fields[0], int(fields[1])<<5|int(fields[2])
+		if len(fields) < 3+n {
+			return inv, fmt.Errorf("invalid invoice: truncated field")
+		}
+		value := fields[3 : 3+n]
+		fields = fields[3+n:]
+		switch tag {
+		case 1: // p
+			if n == 52 {
+				inv.PaymentHash = hex.EncodeToString(toBytes(value))
+			}
+		case 16: // s
+			if n == 52 {
+				inv.PaymentSecret = hex.EncodeToString(toBytes(value))
+			}
+		case 13: // d
+			inv.Description = string(toBytes(value))
+		case 23: // h
+			if n == 52 {
+				inv.DescriptionHash = hex.EncodeToString(toBytes(value))
+			}
+		case 6: // x
+			inv.Expiry = Duration(time.Duration(readUint(value)) * time.Second)
+		case 19: // n
+			if n == 53 {
+				inv.Payee = hex.EncodeToString(toBytes(value))
+			}
+		case 24: // c
+			inv.MinFinalCLTV = int64(readUint(value))
+		}
+	}
+	if inv.PaymentHash == "" {
+		return inv, fmt.Errorf("invalid invoice: no payment hash")
+	}
+	return inv, nil
+}
+
+func parseInvoiceAmount(s string) (int64, error) {
+	perUnit := 1e11 // msats per BTC
+	if m, ok := multipliers[s[len(s)-1]]; ok {
+		perUnit, s = m, s[:len(s)-1]
+	}
+	n, err := strconv.ParseUint(s, 10, 63)
+	if err != nil || s == "" || s[0] == '0' {
+		return 0, fmt.Errorf("invalid invoice amount %q", s)
+	}
+	msat := float64(n) * perUnit
+	if msat != float64(int64(msat)) {
+		return 0, fmt.Errorf("invalid invoice amount %q: not a whole msat", s)
+	}
+	return int64(msat), nil
+}
+
+func readUint(groups []byte) uint64 {
+	var v uint64
+	for _, g := range groups {
+		v = v<<5 | uint64(g)
+	}
+	return v
+}
+
+// toBytes regroups 5-bit values into bytes, dropping trailing padding
+func toBytes(groups []byte) []byte {
+	var out []byte
+	acc, bits := 0, 0
+	for _, g := range groups {
+		acc = acc<<5 | int(g)
+		bits += 5
+		for bits >= 8 {
+			bits -= 8
+			out = append(out, byte(acc>>bits))
+			acc &= 1<<bits - 1
+		}
+	}
+	return out
+}
+
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// bech32Decode splits and checksums a bech32 string, returning the 5-bit
+// data without the checksum. BOLT11 lifts bech32's 90 character limit.
+func bech32Decode(s string) (string, []byte, error) {
+	pos := strings.LastIndexByte(s, '1')
+	if pos < 1 || pos+7 > len(s) {
+		return "", nil, fmt.Errorf("not bech32")
+	}
+	hrp := s[:pos]
+	data := make([]byte, 0, len(s)-pos-1)
+	for _, r := range s[pos+1:] {
+		i := strings.IndexRune(bech32Charset, r)
+		if i < 0 {
+			return "", nil, fmt.Errorf("invalid bech32 character %q", r)
+		}
+		data = append(data, byte(i))
+	}
+	if bech32Polymod(append(hrpExpand(hrp), data...)) != 1 {
+		return "", nil, fmt.Errorf("bad checksum")
+	}
+	return hrp, data[:len(data)-6], nil
+}
+
+func hrpExpand(hrp string) []byte {
+	out := make([]byte, 0, len(hrp)*2+1)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]>>5)
+	}
+	out = append(out, 0)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]&31)
+	}
+	return out
+}
+
+func bech32Polymod(values []byte) uint32 {
+	gen := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>i)&1 == 1 {
+				chk ^= gen[i]
+			}
+		}
+	}
+	return chk
+}
@@ -0,0 +1,180 @@
+package l402
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Test vectors from BOLT #11
+const (
+	specDonation = "lnbc1pvjluezsp5zyg3zyg3zyg3zyg3zyg3zyg3zyg3zyg3zyg3zyg3zyg3zyg3zygspp5qqqsyqcyq5rqwzqfqqqsyqcyq5rqwzqfqqqsyqcyq5rqwzqfqypqdpl2pkx2ctnv5sxxmmwwd5kgetjypeh2ursdae8g6twvus8g6rfwvs8qun0dfjkxaq9qrsgq357wnc5r2ueh7ck6q93dj32dlqnls087fxdwk8qakdyafkq3yap9us6v52vjjsrvywa6rt52cm9r9zqt8r2t7mlcwspyetp5h2tztugp9lfyql"
+	specCoffee   = "lnbc2500u1pvjluezpp5qqqsyqcyq5rqwzqfqqqsyqcyq5rqwzqfqqqsyqcyq5rqwzqfqypqdq5xysxxatsyp3k7enxv4jsxqzpuaztrnwngzn3kdzw5hydlzf03qdgm2hdq27cqv3agm2awhz5se903vruatfhq77w3ls4evs3ch9zw97j25emudupq63nyw24cg27h2rspfj9srp"
+	specHashed   = "lnbc20m1pvjluezpp5qqqsyqcyq5rqwzqfqqqsyqcyq5rqwzqfqqqsyqcyq5rqwzqfqypqhp58yjmdan79s6qqdhdzgynm4zwqd5d7xmw5fk98klysy043l2ahrqscc6gd6ql3jrc5yzme8v4ntcewwz5cnw92tz0pc8qcuufvq7khhr8wpald05e92xw006sq94mg8v2ndf4sefvf9sygkshp5zfem29trqq2yxxz7"
+
+	specPaymentHash = "0001020304050607080900010203040506070809000102030405060708090102"
+)
+
+var specTimestamp = time.Unix(1496314658, 0).UTC()
+
+func TestDecodeInvoiceSpecVectors(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		in   string
+		want Invoice
+	}{
+		{"donation without amount", specDonation, Invoice{
+			Network: "mainnet", Timestamp: specTimestamp, Expiry: Duration(time.Hour),
+			Description: "Please consider supporting this project", PaymentHash: specPaymentHash,
+			PaymentSecret: strings.Repeat("11", 32), MinFinalCLTV: 18,
+		}},
+		{"2500u with one minute expiry", specCoffee, Invoice{
+			Network: "mainnet", AmountMsat: 250000000, HasAmount: true, Timestamp: specTimestamp, Expiry: Duration(time.Minute),
+			Description: "1 cup coffee", PaymentHash: specPaymentHash, MinFinalCLTV: 18,
+		}},
+		{"20m with description hash", specHashed, Invoice{
+			Network: "mainnet", AmountMsat: 2000000000, HasAmount: true, Timestamp: specTimestamp, Expiry: Duration(time.Hour),
+			DescriptionHash: "3925b6f67e2c340036ed12093dd44e0368df1b6ea26c53dbe4811f58fd5db8c1", PaymentHash: specPaymentHash, MinFinalCLTV: 18,
+		}},
+		{"uppercase with lightning: prefix", "LIGHTNING:" + strings.ToUpper(specCoffee), Invoice{
+			Network: "mainnet", AmountMsat: 250000000, HasAmount: true, Timestamp: specTimestamp, Expiry: Duration(time.Minute),
+			Description: "1 cup coffee", PaymentHash: specPaymentHash, MinFinalCLTV: 18,
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := DecodeInvoice(tc.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("DecodeInvoice =\n  %+v\nwant\n  %+v", got, tc.want)
+			}
+		})
+	}
+
+	inv, _ := DecodeInvoice(specCoffee)
+	if want := specTimestamp.Add(time.Minute); !inv.ExpiresAt().Equal(want) {
+		t.Errorf("ExpiresAt = %v; want %v", inv.ExpiresAt(), want)
+	}
+	if inv.AmountSats() != 250000 {
+		t.Errorf("AmountSats = %v", inv.AmountSats())
+	}
+}
+
+func TestDecodeInvoiceNetworks(t *testing.T) {
+	for _, tc := range []struct {
+		hrp        string
+		network    string
+		amountMsat int64
+	}{
+		{"lnbcrt500u", "regtest", 50000000},
+		{"lntbs10n", "signet", 1000},
+		{"lntb20m", "testnet", 2000000000},
+		{"lnbc9678785340p", "mainnet", 967878534},
+		{"lnsb1", "simnet", 100000000000},
+	} {
+		inv, err := DecodeInvoice(encodeTestInvoice(tc.hrp))
+		if err != nil {
+			t.Errorf("%s: %v", tc.hrp, err)
+			continue
+		}
+		if inv.Network != tc.network || inv.AmountMsat != tc.amountMsat || !inv.HasAmount {
+			t.Errorf("%s: network %q, %d msat; want %q, %d msat", tc.hrp, inv.Network, inv.AmountMsat, tc.network, tc.amountMsat)
+		}
+		if inv.PaymentHash != specPaymentHash || inv.Expiry != Duration(90*time.Second) || inv.MinFinalCLTV != 40 {
+			t.Errorf("%s: tagged fields %+v", tc.hrp, inv)
+		}
+	}
+}
+
+func TestParseInvoiceAmount(t *testing.T) {
+	for in, want := range map[string]int64{
+		"1":           100000000000,
+		"20m":         2000000000,
+		"2500u":       250000000,
+		"10n":         1000,
+		"10p":         1,
+		"9678785340p": 967878534,
+	} {
+		if got, err := parseInvoiceAmount(in); err != nil || got != want {
+			t.Errorf("parseInvoiceAmount(%q) = %d, %v; want %d", in, got, err, want)
+		}
+	}
+	// p amounts must be whole msats, and amounts have no leading zeros
+	for _, in := range []string{"1p", "15p", "0100u", "m", "1x"} {
+		if got, err := parseInvoiceAmount(in); err == nil {
+			t.Errorf("parseInvoiceAmount(%q) = %d; want an error", in, got)
+		}
+	}
+}
+
+func TestDecodeInvoiceErrors(t *testing.T) {
+	for _, tc := range []struct{ in, want string }{
+		{specCoffee[:len(specCoffee)-1] + "q", "bad checksum"},
+		{"lnxx1" + specCoffee[len("lnbc2500u1"):], "bad checksum"},
+		{encodeTestInvoice("lnxy"), "unknown network"},
+		{encodeTestInvoice("bc"), "is not ln"},
+		{"not an invoice", "not bech32"},
+	} {
+		if _, err := DecodeInvoice(tc.in); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("DecodeInvoice(%.20q…) = %v; want %q", tc.in, err, tc.want)
+		}
+	}
+}
+
+// encodeTestInvoice builds an invoice with the spec's timestamp and
+// payment hash, a 90 second expiry, a min_final_cltv_expiry of 40 and a
+// zero signature, which DecodeInvoice does not check
+func encodeTestInvoice(hrp string) string {
+	data := fromUint(uint64(specTimestamp.Unix()), 7)
+	raw, _ := hex.DecodeString(specPaymentHash)
+	hash := fromBytes(raw)
+	data = append(data, 1, byte(len(hash)>>5), byte(len(hash)&31))
+	data = append(data, hash...)
+	data = append(data, 6, 0, 2)
+	data = append(data, fromUint(90, 2)...)
+	data = append(data, 24, 0, 2)
+	data = append(data, fromUint(40, 2)...)
+	data = append(data, make([]byte, 104)...)
+
+	values := append(hrpExpand(hrp), data...)
+	mod := bech32Polymod(append(values, 0, 0, 0, 0, 0, 0)) ^ 1
+	for i := 0; i < 6; i++ {
+		data = append(data, byte(mod>>(5*(5-i))&31))
+	}
+	var b strings.Builder
+	b.WriteString(hrp + "1")
+	for _, g := range data {
+		b.WriteByte(bech32Charset[g])
+	}
+	return b.String()
+}
+
+// fromUint writes v big-endian as n 5-bit groups
+func fromUint(v uint64, n int) []byte {
+	out := make([]byte, n)
+	for i := n - 1; i >= 0; i-- {
+		out[i] = byte(v & 31)
+		v >>= 5
+	}
+	return out
+}
+
+// fromBytes regroups bytes into 5-bit values, zero-padding the last one
+func fromBytes(data []byte) []byte {
+	var out []byte
+	acc, bits := 0, 0
+	for _, b := range data {
+		acc = acc<<8 | int(b)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			out = append(out, byte(acc>>bits&31))
+		}
+	}
+	if bits > 0 {
+		out = append(out, byte(acc<<(5-bits)&31))
+	}
+	return out
+}
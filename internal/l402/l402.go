@@ -0,0 +1,92 @@
+// Package l402 decodes what an L402 (formerly LSAT) gateway hands an
+// unauthenticated client: the WWW-Authenticate challenge, the BOLT11
+// invoice to pay and the macaroon that the preimage unlocks. Nothing here
+// pays or verifies signatures; it only reads.
+package l402
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Challenge is one L402 WWW-Authenticate challenge
+type Challenge struct {
+	Scheme   string `json:"scheme"` // L402, or LSAT from older gateways
+	Macaroon string `json:"macaroon"`
+	Invoice  string `json:"invoice"`
+}
+
+// ParseChallenge finds the L402 or LSAT challenge among WWW-Authenticate
+// header values, e.g. `L402 macaroon="AgEE...", invoice="lnbc..."`. A
+// header may carry several comma-separated challenges; L402 wins over LSAT.
+func ParseChallenge(headers []string) (Challenge, error) {
+	var found []Challenge
+	for _, h := range headers {
+		found = append(found, parseHeader(h)...)
+	}
+	for _, scheme := range []string{"L402", "LSAT"} {
+		for _, c := range found {
+			if c.Scheme != scheme {
+				continue
+			}
+			if c.Macaroon == "" || c.Invoice == "" {
+				return c, fmt.Errorf("%s challenge is missing the macaroon or invoice", scheme)
+			}
+			return c, nil
+		}
+	}
+	if len(headers) == 0 {
+		return Challenge{}, fmt.Errorf("no WWW-Authenticate header")
+	}
+	return Challenge{}, fmt.Errorf("no L402 challenge in WWW-Authenticate: %s", strings.Join(headers, "; "))
+}
+
+// parseHeader splits a header into challenges. A token followed by a space
+// starts a new challenge; key=value pairs belong to the current one.
+func parseHeader(h string) []Challenge {
+	var out []Challenge
+	var cur *Challenge
+	for _, part := range splitParams(h) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if scheme, rest, ok := strings.Cut(part, " "); ok && !strings.Contains(scheme, "=") {
+			out = append(out, Challenge{Scheme: strings.ToUpper(scheme)})
+			cur = &out[len(out)-1]
+			part = strings.TrimSpace(rest)
+		} else if !strings.Contains(part, "=") {
+			out = append(out, Challenge{Scheme: strings.ToUpper(part)})
+			cur = &out[len(out)-1]
+			continue
+		}
+		if cur == nil {
+			continue
+		}
+		key, value, _ := strings.Cut(part, "=")
+		value = strings.Trim(strings.TrimSpace(value), `"`)
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "macaroon", "token":
+			cur.Macaroon = value
+		case "invoice":
+			cur.Invoice = value
+		}
+	}
+	return out
+}
+
+// splitParams splits on commas outside double quotes
+func splitParams(s string) []string {
+	var parts []string
+	quoted, start := false, 0
+	for i, r := range s {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == ',' && !quoted:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
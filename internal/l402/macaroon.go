@@ -0,0 +1,219 @@
+package l402
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Macaroon is a decoded macaroon. The signature is not verified; that
+// needs the gateway's root key.
+type Macaroon struct {
+	Version    int      `json:"version"`
+	Location   string   `json:"location,omitempty"`
+	Identifier string   `json:"identifier"` // text, or hex when binary
+	Caveats    []Caveat `json:"caveats"`
+	Signature  string   `json:"signature"`
+
+	// Decoded from an L402 identifier (version, payment hash, token ID)
+	PaymentHash string `json:"payment_hash,omitempty"`
+	TokenID     string `json:"token_id,omitempty"`
+}
+
+// Caveat is one first-party condition, or a third-party caveat when it
+// has a verification ID
+type Caveat struct {
+	Condition  string `json:"condition"`
+	Location   string `json:"location,omitempty"`
+	ThirdParty bool   `json:"third_party,omitempty"`
+}
+
+// DecodeMacaroon decodes a base64 (standard or URL, padded or not)
+// macaroon in the v2 binary or v1 text format
+func DecodeMacaroon(s string) (Macaroon, error) {
+	raw, err := decodeBase64(strings.TrimSpace(s))
+	if err != nil {
+		return Macaroon{}, fmt.Errorf("invalid macaroon: not base64")
+	}
+	if len(raw) == 0 {
+		return Macaroon{}, fmt.Errorf("invalid macaroon: empty")
+	}
+	var m Macaroon
+	if raw[0] == 2 {
+		m, err = decodeV2(raw)
+	} else {
+		m, err = decodeV1(raw)
+	}
+	if err != nil {
+		return m, fmt.Errorf("invalid macaroon: %w", err)
+	}
+	return m, nil
+}
+
+func decodeBase64(s string) ([]byte, error) {
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.RawURLEncoding} {
+		if b, err := enc.DecodeString(s); err == nil {
+			return b, nil
+		}
+	}
+	return nil, fmt.Errorf("not base64")
+}
+
+// v2 field types
+const (
+	fieldEOS        = 0
+	fieldLocation   = 1
+	fieldIdentifier = 2
+	fieldVID        = 4
+	fieldSignature  = 6
+)
+
+type v2Reader struct{ b []byte }
+
+// field reads one type/length/value field; EOS has no length or value
+func (r *v2Reader) field() (byte, []byte, error) {
+	if len(r.b) == 0 {
+		return 0, nil, fmt.Errorf("truncated")
+	}
+	t := r.b[0]
+	r.b = r.b[1:]
+	if t == fieldEOS {
+		return t, nil, nil
+	}
+	n, size := binary.Uvarint(r.b)
+	if size <= 0 || uint64(len(r.b)-size) < n {
+		return 0, nil, fmt.Errorf("truncated field")
+	}
+	v := r.b[size : size+int(n)]
+	r.b = r.b[size+int(n):]
+	return t, v, nil
+}
+
+func decodeV2(raw []byte) (Macaroon, error) {
+	m := Macaroon{Version: 2}
+	r := &v2Reader{b: raw[1:]}
+	var id []byte
+	for {
+		t, v, err := r.field()
+		if err != nil {
+			return m, err
+		}
+		if t == fieldEOS {
+			break
+		}
+		switch t {
+		case fieldLocation:
+			m.Location = string(v)
+		case fieldIdentifier:
+			id = v
+		}
+	}
+	m.setIdentifier(id)
+
+	for {
+		var c Caveat
+		t, v, err := r.field()
+		if err != nil {
+			return m, err
+		}
+		if t == fieldEOS {
+			break // end of caveats
+		}
+		for t != fieldEOS {
+			switch t {
+			case fieldLocation:
+				c.Location = string(v)
+			case fieldIdentifier:
+				c.Condition = printable(v)
+			case fieldVID:
+				c.ThirdParty = true
+			}
+			if t, v, err = r.field(); err != nil {
+				return m, err
+			}
+		}
+		m.Caveats = append(m.Caveats, c)
+	}
+
+	t, v, err := r.field()
+	if err != nil || t != fieldSignature {
+		return m, fmt.Errorf("missing signature")
+	}
+	m.Signature = hex.EncodeToString(v)
+	return m, nil
+}
+
+// decodeV1 reads the original text format: packets of a 4 hex digit length
+// followed by "key value\n"
+func decodeV1(raw []byte) (Macaroon, error) {
+	m := Macaroon{Version: 1}
+	for len(raw) > 0 {
+		if len(raw) < 4 {
+			return m, fmt.Errorf("truncated packet")
+		}
+		n, err := strconv.ParseUint(string(raw[:4]), 16, 16)
+		if err != nil || n < 5 || int(n) > len(raw) {
+			return m, fmt.Errorf("bad packet length")
+		}
+		packet := strings.TrimSuffix(string(raw[4:n]), "\n")
+		raw = raw[n:]
+		key, value, _ := strings.Cut(packet, " ")
+		switch key {
+		case "location":
+			if len(m.Caveats) > 0 {
+				m.Caveats[len(m.Caveats)-1].Location = value
+			} else {
+				m.Location = value
+			}
+		case "identifier":
+			m.setIdentifier([]byte(value))
+		case "cid":
+			m.Caveats = append(m.Caveats, Caveat{Condition: printable([]byte(value))})
+		case "vid":
+			if len(m.Caveats) > 0 {
+				m.Caveats[len(m.Caveats)-1].ThirdParty = true
+			}
+		case "cl":
+			if len(m.Caveats) > 0 {
+				m.Caveats[len(m.Caveats)-1].Location = value
+			}
+		case "signature":
+			m.Signature = hex.EncodeToString([]byte(value))
+		}
+	}
+	if m.Signature == "" {
+		return m, fmt.Errorf("missing signature")
+	}
+	return m, nil
+}
+
+// setIdentifier records the identifier and, for the 66-byte L402 layout
+// (uint16 version 0, payment hash, token ID), the parts it holds
+func (m *Macaroon) setIdentifier(id []byte) {
+	m.Identifier = printable(id)
+	if len(id) == 66 && binary.BigEndian.Uint16(id[:2]) == 0 {
+		m.PaymentHash = hex.EncodeToString(id[2:34])
+		m.TokenID = hex.EncodeToString(id[34:])
+	}
+}
+
+// printable returns text as is and anything else as hex
+func printable(b []byte) string {
+	if utf8.Valid(b) {
+		ok := true
+		for _, r := range string(b) {
+			if r < 0x20 && r != '\t' {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return string(b)
+		}
+	}
+	return hex.EncodeToString(b)
+}
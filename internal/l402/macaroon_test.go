@@ -0,0 +1,130 @@
+package l402
+
+import (
+	"encoding/base64"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Macaroon vectors. The v1 one is from the libmacaroons README (root key
+// "this is our super secret key; only we should know it") and the plain v2
+// one from the macaroon v2 format spec (root key "this is the key"). The
+// third-party and L402 ones were built the same way, with a stand-in
+// verification ID; only their structure matters here.
+const (
+	libmacaroonsV1 = "MDAxY2xvY2F0aW9uIGh0dHA6Ly9teWJhbmsvCjAwMjZpZGVudGlmaWVyIHdlIHVzZWQgb3VyIHNlY3JldCBrZXkKMDAyZnNpZ25hdHVyZSDj2eApCFJsTAA5rhURQRXZf91ovyujebNCqvD2F9BVLwo"
+	specV2         = "AgETaHR0cDovL2V4YW1wbGUub3JnLwIFa2V5aWQAAhRhY2NvdW50ID0gMzczNTkyODU1OQACDHVzZXIgPSBhbGljZQAABiBL6WfNHqDGsmuvakqU7psFsViG2guoXoxCqTyNDhJe_A=="
+	thirdPartyV1   = "MDAxY2xvY2F0aW9uIGh0dHA6Ly9teWJhbmsvCjAwMjZpZGVudGlmaWVyIHdlIHVzZWQgb3VyIHNlY3JldCBrZXkKMDAxZGNpZCBhY2NvdW50ID0gMzczNTkyODU1OQowMDMwY2lkIHRoaXMgd2FzIGhvdyB3ZSByZW1pbmQgYXV0aCBvZiBrZXkvcHJlZAowMDM5dmlkIJ0fnR-dH50fnR-dH50fnR-dH50fnR-dH50fnR-dH50fnR-dH50fnR-dH50fnR-dHwowMDFiY2wgaHR0cDovL2F1dGgubXliYW5rLwowMDJmc2lnbmF0dXJlIFqooKiTHqPFCN_63bCq_axTZebp7Jk7titAxzlkSQ9eCg"
+	thirdPartyV2   = "AgETaHR0cDovL2V4YW1wbGUub3JnLwIFa2V5aWQAAhRhY2NvdW50ID0gMzczNTkyODU1OQABGGh0dHBzOi8vYXV0aC5leGFtcGxlLm9yZwINdXNlciBpcyBhbGljZQRIAAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8gISIjJCUmJygpKissLS4vMDEyMzQ1Njc4OTo7PD0-P0BBQkNERUZHAAAGIOlDY8GTg3MLKBrI78iE29Jna9W0MFlwsKqvyF8FJ8Xj"
+	l402V2         = "AgEHc2F0Z2F0ZQJCAAAAAQIDBAUGBwgJAAECAwQFBgcICQABAgMEBQYHCAkBAqurq6urq6urq6urq6urq6urq6urq6urq6urq6urq6urAAISc2VydmljZXM9cHJlbWl1bTowAAAGILh6VuOoyuYng5aQAdkly0KFBX9sEVm5zzjF5ZL2nSL8"
+)
+
+func TestDecodeMacaroon(t *testing.T) {
+	account := Caveat{Condition: "account = 3735928559"}
+	for _, tc := range []struct {
+		name string
+		in   string
+		want Macaroon
+	}{
+		{"libmacaroons v1", libmacaroonsV1, Macaroon{
+			Version: 1, Location: "http://mybank/", Identifier: "we used our secret key",
+			Signature: "e3d9e02908526c4c0039ae15114115d97fdd68bf2ba379b342aaf0f617d0552f",
+		}},
+		{"spec v2", specV2, Macaroon{
+			Version: 2, Location: "http://example.org/", Identifier: "keyid",
+			Caveats:   []Caveat{account, {Condition: "user = alice"}},
+			Signature: "4be967cd1ea0c6b26baf6a4a94ee9b05b15886da0ba85e8c42a93c8d0e125efc",
+		}},
+		{"v1 third-party caveat", thirdPartyV1, Macaroon{
+			Version: 1, Location: "http://mybank/", Identifier: "we used our secret key",
+			Caveats: []Caveat{account, {
+				Condition: "this was how we remind auth of key/pred", Location: "http://auth.mybank/", ThirdParty: true,
+			}},
+			Signature: "5aa8a0a8931ea3c508dffaddb0aafdac5365e6e9ec993bb62b40c73964490f5e",
+		}},
+		{"v2 third-party caveat", thirdPartyV2, Macaroon{
+			Version: 2, Location: "http://example.org/", Identifier: "keyid",
+			Caveats:   []Caveat{account, {Condition: "user is alice", Location: "https://auth.example.org", ThirdParty: true}},
+			Signature: "e94363c19383730b281ac8efc884dbd2676bd5b4305970b0aaafc85f0527c5e3",
+		}},
+		{"L402 identifier", l402V2, Macaroon{
+			Version: 2, Location: "satgate",
+			Identifier:  "0000" + specPaymentHash + strings.Repeat("ab", 32),
+			Caveats:     []Caveat{{Condition: "services=premium:0"}},
+			Signature:   "b87a56e3a8cae62783969001d925cb4285057f6c1159b9cf38c5e592f69d22fc",
+			PaymentHash: specPaymentHash,
+			TokenID:     strings.Repeat("ab", 32),
+		}},
+	} {
+		got, err := DecodeMacaroon(tc.in)
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s:\n got %+v\nwant %+v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestDecodeMacaroonEncodings(t *testing.T) {
+	raw, err := base64.URLEncoding.DecodeString(specV2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.RawURLEncoding} {
+		s := "  " + enc.EncodeToString(raw) + "\n"
+		if m, err := DecodeMacaroon(s); err != nil || m.Identifier != "keyid" {
+			t.Errorf("DecodeMacaroon(%q) = %+v, %v", s, m, err)
+		}
+	}
+}
+
+func TestDecodeMacaroonNotL402(t *testing.T) {
+	// A 66-byte identifier with a non-zero version is not split
+	id := make([]byte, 66)
+	id[1] = 1
+	m := Macaroon{}
+	m.setIdentifier(id)
+	if m.PaymentHash != "" || m.TokenID != "" {
+		t.Errorf("version 1 identifier split into %s / %s", m.PaymentHash, m.TokenID)
+	}
+	m.setIdentifier(id[:65])
+	if m.PaymentHash != "" {
+		t.Errorf("65-byte identifier split into %s", m.PaymentHash)
+	}
+}
+
+func TestDecodeMacaroonTruncated(t *testing.T) {
+	for _, vector := range []string{libmacaroonsV1, specV2, thirdPartyV1, thirdPartyV2, l402V2} {
+		raw, err := decodeBase64(vector)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for n := 0; n < len(raw); n++ {
+			in := base64.StdEncoding.EncodeToString(raw[:n])
+			if m, err := DecodeMacaroon(in); err == nil {
+				t.Errorf("%d of %d bytes of %.16s… decoded: %+v", n, len(raw), vector, m)
+			} else if !strings.HasPrefix(err.Error(), "invalid macaroon: ") {
+				t.Errorf("%d bytes: error %q", n, err)
+			}
+		}
+	}
+}
+
+func TestDecodeMacaroonInvalid(t *testing.T) {
+	for in, want := range map[string]string{
+		"":                        "empty",
+		"not a macaroon":          "not base64",
+		"MDAxeg":                  "bad packet length", // "001z"
+		"AgEFbG9j":                "truncated field",   // v2 location longer than the data
+		"AgACAWEAAAEA":            "missing signature", // a location where the signature belongs
+		"MDAxMWlkZW50aWZpZXIgeAo": "missing signature", // v1 identifier only
+	} {
+		_, err := DecodeMacaroon(in)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("DecodeMacaroon(%q) = %v; want error containing %q", in, err, want)
+		}
+	}
+}
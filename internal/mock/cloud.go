@@ -0,0 +1,227 @@
+package mock
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+)
+
+// Cost center and department are first-class fields on the cloud surface
+// and labels on the gateway
+const (
+	labelCostCenter = "cost-center"
+	labelDepartment = "department"
+)
+
+// cloudToken renders t in the delegation-v2 format, with budgets in
+// credits (cents)
+func (s *Server) cloudToken(t *Token, depth int) map[string]interface{} {
+	snap := s.snapshot(t)
+	labels := map[string]string{}
+	for k, v := range snap.Labels {
+		if k != labelCostCenter && k != labelDepartment {
+			labels[k] = v
+		}
+	}
+	out := map[string]interface{}{
+		"id":                   snap.ID,
+		"name":                 snap.Name,
+		"status":               snap.Status,
+		"budget_limit_credits": credits(snap.Budget),
+		"budget_spent_credits": credits(snap.Spent),
+		"scope":                map[string]interface{}{"routes": snap.Routes},
+		"depth":                depth,
+		"created_at":           snap.CreatedAt,
+	}
+	optional := map[string]string{
+		"parent_id":    snap.ParentID,
+		"costCenter":   snap.Labels[labelCostCenter],
+		"department":   snap.Labels[labelDepartment],
+		"expires_at":   snap.ExpiresAt,
+		"last_used_at": snap.LastUsedAt,
+		"revoked_at":   snap.RevokedAt,
+	}
+	for k, v := range optional {
+		if v != "" {
+			out[k] = v
+		}
+	}
+	if len(labels) > 0 {
+		out["labels"] = labels
+	}
+	return out
+}
+
+// credits converts dollars to whole credits
+func credits(v float64) int64 {
+	if v < 0 {
+		return int64(v*100 - 0.5)
+	}
+	return int64(v*100 + 0.5)
+}
+
+func (s *Server) handleTree(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var build func(id string, depth int) map[string]interface{}
+	build = func(id string, depth int) map[string]interface{} {
+		node := s.cloudToken(s.tokens[id], depth)
+		children := []map[string]interface{}{}
+		for _, cid := range s.children(id) {
+			children = append(children, build(cid, depth+1))
+		}
+		node["children"] = children
+		return node
+	}
+	tree := []map[string]interface{}{}
+	for _, id := range s.order {
+		t := s.tokens[id]
+		if _, ok := s.tokens[t.ParentID]; t.ParentID == "" || !ok {
+			tree = append(tree, build(id, 0))
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"tree": tree})
+}
+
+func (s *Server) handleDelegate(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Name               string            `json:"name"`
+		BudgetLimitCredits float64           `json:"budget_limit_credits"`
+		ParentID           string            `json:"parent_id"`
+		Labels             map[string]string `json:"labels"`
+		CostCenter         string            `json:"costCenter"`
+		Department         string            `json:"department"`
+		Expiry             string            `json:"expiry"`
+		Scope              struct {
+			Routes []string `json:"routes"`
+		} `json:"scope"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	labels := copyLabels(body.Labels)
+	for k, v := range map[string]string{labelCostCenter: body.CostCenter, labelDepartment: body.Department} {
+		if v != "" {
+			if labels == nil {
+				labels = map[string]string{}
+			}
+			labels[k] = v
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, apiErr := s.mint(r, mintRequest{
+		Name: body.Name, Budget: body.BudgetLimitCredits / 100, Currency: "USD", Routes: body.Scope.Routes,
+		Labels: labels, Expiry: body.Expiry, ParentID: body.ParentID,
+	})
+	if apiErr != nil {
+		writeError(w, apiErr.code, apiErr.msg)
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"token":          s.cloudToken(t, s.depth(t)),
+		"macaroon_token": t.Macaroon,
+	})
+}
+
+// depth is t's distance from its root
+func (s *Server) depth(t *Token) int {
+	d := 0
+	for p, ok := s.tokens[t.ParentID]; ok && d < len(s.order); p, ok = s.tokens[p.ParentID] {
+		d++
+	}
+	return d
+}
+
+func (s *Server) handleCloudToken(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if t := s.lookup(w, r); t != nil {
+		writeJSON(w, http.StatusOK, s.cloudToken(t, s.depth(t)))
+	}
+}
+
+// handleCloudPatch applies a merge patch of labels, costCenter and
+// department; null removes a value
+func (s *Server) handleCloudPatch(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Labels     map[string]*string `json:"labels"`
+		CostCenter json.RawMessage    `json:"costCenter"`
+		Department json.RawMessage    `json:"department"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	patch := body.Labels
+	for k, raw := range map[string]json.RawMessage{labelCostCenter: body.CostCenter, labelDepartment: body.Department} {
+		if len(raw) == 0 {
+			continue
+		}
+		if patch == nil {
+			patch = map[string]*string{}
+		}
+		var v *string
+		if err := json.Unmarshal(raw, &v); err != nil {
+			writeError(w, http.StatusBadRequest, k+" must be a string or null")
+			return
+		}
+		patch[k] = v
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := s.lookup(w, r)
+	if t == nil {
+		return
+	}
+	s.setLabels(r, t, patch)
+	writeJSON(w, http.StatusOK, s.cloudToken(t, s.depth(t)))
+}
+
+// handleRollups totals credits per cost center and department
+func (s *Server) handleRollups(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	since, until := timeRange(q.Get("from"), q.Get("to"))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	type rollup struct {
+		CostCenter     string  `json:"costCenter"`
+		Department     string  `json:"department"`
+		TotalAllocated int64   `json:"totalAllocated"`
+		TotalConsumed  int64   `json:"totalConsumed"`
+		TokenCount     int     `json:"tokenCount"`
+		PercentUsed    float64 `json:"percentUsed"`
+	}
+	byKey := map[[2]string]*rollup{}
+	for _, id := range s.order {
+		t := s.tokens[id]
+		key := [2]string{t.Labels[labelCostCenter], t.Labels[labelDepartment]}
+		ru, ok := byKey[key]
+		if !ok {
+			ru = &rollup{CostCenter: key[0], Department: key[1]}
+			byKey[key] = ru
+		}
+		ru.TotalAllocated += credits(t.Budget)
+		ru.TotalConsumed += credits(spentIn(t, since, until))
+		ru.TokenCount++
+	}
+	rollups := []rollup{}
+	for _, ru := range byKey {
+		if ru.TotalAllocated > 0 {
+			ru.PercentUsed = float64(int64(float64(ru.TotalConsumed)/float64(ru.TotalAllocated)*1000+0.5)) / 10
+		}
+		rollups = append(rollups, *ru)
+	}
+	sort.Slice(rollups, func(i, j int) bool {
+		if rollups[i].CostCenter != rollups[j].CostCenter {
+			return rollups[i].CostCenter < rollups[j].CostCenter
+		}
+		return rollups[i].Department < rollups[j].Department
+	})
+	writeJSON(w, http.StatusOK, map[string]interface{}{"rollups": rollups})
+}
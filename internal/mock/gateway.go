@@ -0,0 +1,533 @@
+package mock
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// mintRequest is a new token on either surface, in display units
+type mintRequest struct {
+	Name     string
+	Budget   float64
+	Currency string
+	Routes   []string
+	Labels   map[string]string
+	Expiry   string
+	ParentID string
+}
+
+// apiError is a failure with the HTTP status to report it under
+type apiError struct {
+	code int
+	msg  string
+}
+
+func (e *apiError) Error() string { return e.msg }
+
+func fail(code int, format string, args ...interface{}) *apiError {
+	return &apiError{code: code, msg: fmt.Sprintf(format, args...)}
+}
+
+// mint validates req against its parent and stores the new token. A child
+// must be active, stay within the parent's unallocated budget and only
+// narrow its routes.
+func (s *Server) mint(r *http.Request, req mintRequest) (*Token, *apiError) {
+	if strings.TrimSpace(req.Name) == "" {
+		return nil, fail(http.StatusBadRequest, "name is required")
+	}
+	if req.Budget < 0 {
+		return nil, fail(http.StatusBadRequest, "budget must not be negative")
+	}
+	expires, err := parseExpiry(req.Expiry, s.Now())
+	if err != nil {
+		return nil, fail(http.StatusBadRequest, "%v", err)
+	}
+	if len(req.Routes) == 0 {
+		req.Routes = []string{"*"}
+	}
+	if req.ParentID != "" {
+		p, ok := s.tokens[req.ParentID]
+		if !ok {
+			return nil, fail(http.StatusNotFound, "parent token %s not found", req.ParentID)
+		}
+		if st := s.snapshot(p).Status; st != "active" {
+			return nil, fail(http.StatusConflict, "parent token %s is %s", p.ID, st)
+		}
+		if req.Currency == "" {
+			req.Currency = p.Currency
+		}
+		if !strings.EqualFold(currency(req.Currency), currency(p.Currency)) {
+			return nil, fail(http.StatusBadRequest, "budget currency %s does not match parent's %s", currency(req.Currency), currency(p.Currency))
+		}
+		if p.Budget > 0 {
+			if free := s.unallocated(p); req.Budget == 0 || req.Budget > free {
+				return nil, fail(http.StatusBadRequest, "budget exceeds parent's unallocated %.2f %s", free, currency(p.Currency))
+			}
+		}
+		for _, route := range req.Routes {
+			if !covered(route, p.Routes) {
+				return nil, fail(http.StatusForbidden, "route %s is outside the parent's scope", route)
+			}
+		}
+	}
+
+	t := &Token{
+		ID:        s.newID(),
+		Name:      req.Name,
+		Status:    "active",
+		ParentID:  req.ParentID,
+		Budget:    req.Budget,
+		Currency:  strings.ToUpper(req.Currency),
+		Routes:    req.Routes,
+		Labels:    copyLabels(req.Labels),
+		CreatedAt: s.timestamp(),
+		ExpiresAt: expires,
+	}
+	t.Macaroon = encodeMacaroon(*t)
+	s.tokens[t.ID] = t
+	s.order = append(s.order, t.ID)
+	s.audit(r, "mint", t, map[string]interface{}{"budget": t.Budget, "routes": t.Routes})
+	return t, nil
+}
+
+// unallocated is what a parent can still delegate: its budget less its own
+// spend and its active children's budgets
+func (s *Server) unallocated(p *Token) float64 {
+	free := p.Budget - p.Spent
+	for _, id := range s.children(p.ID) {
+		if c := s.tokens[id]; c.Status == "active" {
+			free -= c.Budget
+		}
+	}
+	if free < 0 {
+		return 0
+	}
+	return free
+}
+
+// revoke revokes t and, since its credentials derive from it, every
+// descendant
+func (s *Server) revoke(r *http.Request, t *Token) []string {
+	var revoked []string
+	var walk func(*Token)
+	walk = func(t *Token) {
+		if t.Status != "revoked" {
+			t.Status = "revoked"
+			t.RevokedAt = s.timestamp()
+			revoked = append(revoked, t.ID)
+		}
+		for _, id := range s.children(t.ID) {
+			walk(s.tokens[id])
+		}
+	}
+	walk(t)
+	s.audit(r, "revoke", t, map[string]interface{}{"revoked": revoked})
+	return revoked
+}
+
+// setLabels applies a merge patch; nil values remove the label
+func (s *Server) setLabels(r *http.Request, t *Token, patch map[string]*string) {
+	if len(patch) == 0 {
+		return
+	}
+	if t.Labels == nil {
+		t.Labels = map[string]string{}
+	}
+	changes := map[string]interface{}{}
+	for k, v := range patch {
+		if v == nil {
+			delete(t.Labels, k)
+			changes[k] = nil
+		} else {
+			t.Labels[k] = *v
+			changes[k] = *v
+		}
+	}
+	if len(t.Labels) == 0 {
+		t.Labels = nil
+	}
+	s.audit(r, "label", t, changes)
+}
+
+func (s *Server) handleListTokens(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tokens := []Token{}
+	for _, id := range s.order {
+		tokens = append(tokens, s.snapshot(s.tokens[id]))
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"tokens": tokens})
+}
+
+func (s *Server) handleMint(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Name     string            `json:"name"`
+		Budget   float64           `json:"budget"`
+		Currency string            `json:"currency"`
+		Routes   []string          `json:"routes"`
+		Labels   map[string]string `json:"labels"`
+		Expiry   string            `json:"expiry"`
+		ParentID string            `json:"parent_id"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, apiErr := s.mint(r, mintRequest{
+		Name: body.Name, Budget: body.Budget, Currency: body.Currency, Routes: body.Routes,
+		Labels: body.Labels, Expiry: body.Expiry, ParentID: body.ParentID,
+	})
+	if apiErr != nil {
+		writeError(w, apiErr.code, apiErr.msg)
+		return
+	}
+	resp := map[string]interface{}{
+		"id":       t.ID,
+		"name":     t.Name,
+		"status":   t.Status,
+		"budget":   t.Budget,
+		"currency": currency(t.Currency),
+		"scope":    map[string]interface{}{"routes": t.Routes},
+		"macaroon": t.Macaroon,
+	}
+	if t.ExpiresAt != "" {
+		resp["expires_at"] = t.ExpiresAt
+	}
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+// lookup finds the {id} token or writes a 404
+func (s *Server) lookup(w http.ResponseWriter, r *http.Request) *Token {
+	t, ok := s.tokens[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "token not found")
+		return nil
+	}
+	return t
+}
+
+func (s *Server) handleGetToken(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if t := s.lookup(w, r); t != nil {
+		writeJSON(w, http.StatusOK, s.snapshot(t))
+	}
+}
+
+func (s *Server) handlePatchToken(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Labels map[string]*string `json:"labels"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := s.lookup(w, r)
+	if t == nil {
+		return
+	}
+	s.setLabels(r, t, body.Labels)
+	writeJSON(w, http.StatusOK, s.snapshot(t))
+}
+
+// handleUsage serves per-route and daily spend in display units on both
+// surfaces
+func (s *Server) handleUsage(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := s.lookup(w, r)
+	if t == nil {
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"spend_by_route": nonNilMap(t.RouteSpend),
+		"daily_spend":    nonNilMap(t.DailySpend),
+	})
+}
+
+func (s *Server) handleRevoke(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := s.lookup(w, r)
+	if t == nil {
+		return
+	}
+	revoked := s.revoke(r, t)
+	writeJSON(w, http.StatusOK, map[string]interface{}{"id": t.ID, "status": t.Status, "revoked": nonNil(revoked)})
+}
+
+// spentIn is t's spend in [since, until). Tokens without daily spend in
+// the fixture book their total on the day they were last used, or created.
+func spentIn(t *Token, since, until time.Time) float64 {
+	if since.IsZero() && until.IsZero() {
+		return t.Spent
+	}
+	daily := t.DailySpend
+	if len(daily) == 0 {
+		day := firstNonEmpty(t.LastUsedAt, t.CreatedAt)
+		if len(day) < 10 {
+			return t.Spent
+		}
+		daily = map[string]float64{day[:10]: t.Spent}
+	}
+	var sum float64
+	for day, v := range daily {
+		d, err := time.Parse("2006-01-02", day)
+		if err != nil {
+			continue
+		}
+		if (since.IsZero() || !d.Before(since.Truncate(24*time.Hour))) && (until.IsZero() || d.Before(until)) {
+			sum += v
+		}
+	}
+	return sum
+}
+
+func (s *Server) handleSpend(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	since, until := timeRange(q.Get("since"), q.Get("until"))
+	if p := q.Get("period"); p != "" && since.IsZero() {
+		if d, err := parseSpan(p); err == nil {
+			since = s.Now().Add(-d)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if groupBy := q.Get("group_by"); groupBy != "" {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"group_by": groupBy, "groups": s.spendGroups(groupBy, since, until)})
+		return
+	}
+
+	type agent struct {
+		Name     string  `json:"name"`
+		Spent    float64 `json:"spent"`
+		Budget   float64 `json:"budget"`
+		Currency string  `json:"currency"`
+	}
+	agents := []agent{}
+	var allocated, consumed float64
+	currencies := map[string]bool{}
+	for _, id := range s.order {
+		t := s.tokens[id]
+		if a := q.Get("agent"); a != "" && t.Name != a && t.ID != a {
+			continue
+		}
+		spent := spentIn(t, since, until)
+		agents = append(agents, agent{Name: t.Name, Spent: spent, Budget: t.Budget, Currency: currency(t.Currency)})
+		currencies[currency(t.Currency)] = true
+		// Children's budgets are carved out of their parents', so only roots
+		// count towards the org totals
+		if t.ParentID == "" || q.Get("agent") != "" {
+			allocated += t.Budget
+			consumed += spent
+		}
+	}
+	resp := map[string]interface{}{"agents": agents}
+	if len(currencies) <= 1 {
+		cur := "USD"
+		for c := range currencies {
+			cur = c
+		}
+		resp["currency"] = cur
+		resp["total_allocated"] = allocated
+		resp["total_consumed"] = consumed
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+type spendGroup struct {
+	Key       string  `json:"key"`
+	Consumed  float64 `json:"consumed"`
+	Allocated float64 `json:"allocated"`
+	Tokens    int     `json:"tokens"`
+	Currency  string  `json:"currency"`
+}
+
+// spendGroups groups spend by agent, cost center, department or route.
+// Groups are split by currency so amounts are never mixed.
+func (s *Server) spendGroups(groupBy string, since, until time.Time) []spendGroup {
+	byKey := map[string]*spendGroup{}
+	var order []string
+	add := func(key, cur string, consumed, allocated float64) {
+		if key == "" {
+			key = "(unassigned)"
+		}
+		id := key + "\x00" + cur
+		g, ok := byKey[id]
+		if !ok {
+			g = &spendGroup{Key: key, Currency: cur}
+			byKey[id] = g
+			order = append(order, id)
+		}
+		g.Consumed += consumed
+		g.Allocated += allocated
+		g.Tokens++
+	}
+	for _, id := range s.order {
+		t := s.tokens[id]
+		cur := currency(t.Currency)
+		switch groupBy {
+		case "route":
+			for route, v := range t.RouteSpend {
+				add(route, cur, v, 0)
+			}
+		case "costCenter", "cost-center":
+			add(t.Labels["cost-center"], cur, spentIn(t, since, until), t.Budget)
+		case "department":
+			add(t.Labels["department"], cur, spentIn(t, since, until), t.Budget)
+		default:
+			add(t.Name, cur, spentIn(t, since, until), t.Budget)
+		}
+	}
+	groups := []spendGroup{}
+	for _, id := range order {
+		groups = append(groups, *byKey[id])
+	}
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].Consumed > groups[j].Consumed })
+	return groups
+}
+
+func (s *Server) handleRoutes(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, http.StatusOK, map[string]interface{}{"routes": nonNil(s.fx.Routes)})
+}
+
+func (s *Server) handleThreats(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	since, until := timeRange(q.Get("since"), q.Get("until"))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	threats := []Threat{}
+	counts := map[string]int{}
+	for _, t := range s.fx.Threats {
+		if !inRange(t.Time, since, until) ||
+			(q.Get("agent") != "" && t.Agent != q.Get("agent") && t.TokenID != q.Get("agent")) ||
+			(q.Get("route") != "" && !matchRoute(q.Get("route"), t.Route)) ||
+			(q.Get("category") != "" && t.Type != q.Get("category")) {
+			continue
+		}
+		threats = append(threats, t)
+		counts[t.Type]++
+	}
+	sort.SliceStable(threats, func(i, j int) bool { return threats[i].Time > threats[j].Time })
+
+	type category struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+	categories := []category{}
+	for name, n := range counts {
+		categories = append(categories, category{name, n})
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		if categories[i].Count != categories[j].Count {
+			return categories[i].Count > categories[j].Count
+		}
+		return categories[i].Name < categories[j].Name
+	})
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"total_blocked":  len(threats),
+		"categories":     categories,
+		"recent_threats": threats,
+	})
+}
+
+// handlePayments lists invoices. since and until select by settlement time,
+// or creation time for invoices that were never paid, so an invoice issued
+// before the window but paid inside it counts in the window.
+func (s *Server) handlePayments(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	since, until := timeRange(q.Get("since"), q.Get("until"))
+	s.mu.Lock()
+	var payments []Payment
+	for _, p := range s.fx.Payments {
+		at := p.CreatedAt
+		if p.Status == "settled" && p.SettledAt != "" {
+			at = p.SettledAt
+		}
+		if !inRange(at, since, until) ||
+			(q.Get("route") != "" && !matchRoute(q.Get("route"), p.Route)) ||
+			(q.Get("client") != "" && p.Client != q.Get("client") && p.ClientName != q.Get("client")) ||
+			(q.Get("status") != "" && p.Status != q.Get("status")) {
+			continue
+		}
+		payments = append(payments, p)
+	}
+	s.mu.Unlock()
+
+	page, next := paginate(len(payments), q.Get("cursor"), q.Get("limit"))
+	writeJSON(w, http.StatusOK, map[string]interface{}{"payments": nonNil(payments[page[0]:page[1]]), "next_cursor": next})
+}
+
+func (s *Server) handleRates(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.fx.Rates) == 0 {
+		writeError(w, http.StatusNotFound, "no rates configured")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"rates":      s.fx.Rates,
+		"source":     "mock",
+		"updated_at": s.timestamp(),
+	})
+}
+
+// currency defaults a blank code to USD
+func currency(code string) string {
+	if code == "" {
+		return "USD"
+	}
+	return strings.ToUpper(code)
+}
+
+// matchRoute reports whether path matches pattern; * alone matches
+// everything and a trailing * is a prefix match
+func matchRoute(pattern, path string) bool {
+	if pattern == "*" || pattern == path {
+		return true
+	}
+	if strings.HasSuffix(pattern, "*") {
+		return strings.HasPrefix(path, strings.TrimSuffix(pattern, "*"))
+	}
+	return false
+}
+
+// covered reports whether route is within one of the scope patterns
+func covered(route string, scope []string) bool {
+	if len(scope) == 0 {
+		return true
+	}
+	for _, p := range scope {
+		if matchRoute(p, strings.TrimSuffix(route, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+func firstNonEmpty(vals ...string) string {
+	for _, v := range vals {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func nonNilMap(m map[string]float64) map[string]float64 {
+	if m == nil {
+		return map[string]float64{}
+	}
+	return m
+}
@@ -0,0 +1,520 @@
+// Package mock is an in-memory SatGate gateway and cloud API. It serves the
+// admin and delegation endpoints the CLI uses from state seeded by a fixture
+// file, so integration tests and local scripts can run without a gateway.
+package mock
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Fixture is the seed state. It loads from YAML or JSON.
+type Fixture struct {
+	AdminToken   string             `yaml:"admin_token" json:"admin_token"`
+	BearerToken  string             `yaml:"bearer_token" json:"bearer_token"`
+	SessionToken string             `yaml:"session_token" json:"session_token"`
+	Version      string             `yaml:"version" json:"version"`
+	Routes       []Route            `yaml:"routes" json:"routes"`
+	Tokens       []Token            `yaml:"tokens" json:"tokens"`
+	Threats      []Threat           `yaml:"threats" json:"threats"`
+	Payments     []Payment          `yaml:"payments" json:"payments"`
+	Audit        []AuditEvent       `yaml:"audit" json:"audit"`
+	Rates        map[string]float64 `yaml:"rates" json:"rates"`
+}
+
+// Route is a protected route. Cost is charged to the calling token per
+// request on control and observe routes; charge routes have a sats price.
+type Route struct {
+	Path      string  `yaml:"path" json:"path"`
+	Policy    string  `yaml:"policy" json:"policy"`
+	Name      string  `yaml:"name" json:"name,omitempty"`
+	PriceSats int64   `yaml:"price_sats" json:"price_sats,omitempty"`
+	Cost      float64 `yaml:"cost" json:"-"`
+}
+
+// Token is a capability token. Budget and Spent are in Currency (USD when
+// blank); the cloud surface reports them as credits.
+type Token struct {
+	ID         string             `yaml:"id" json:"id"`
+	Name       string             `yaml:"name" json:"name"`
+	Status     string             `yaml:"status" json:"status"`
+	ParentID   string             `yaml:"parent_id" json:"parent_id,omitempty"`
+	Budget     float64            `yaml:"budget" json:"budget"`
+	Spent      float64            `yaml:"spent" json:"spent"`
+	Currency   string             `yaml:"currency" json:"currency,omitempty"`
+	Routes     []string           `yaml:"routes" json:"routes,omitempty"`
+	Labels     map[string]string  `yaml:"labels" json:"labels,omitempty"`
+	CreatedAt  string             `yaml:"created_at" json:"created_at,omitempty"`
+	ExpiresAt  string             `yaml:"expires_at" json:"expires_at,omitempty"`
+	LastUsedAt string             `yaml:"last_used_at" json:"last_used_at,omitempty"`
+	RevokedAt  string             `yaml:"revoked_at" json:"revoked_at,omitempty"`
+	Macaroon   string             `yaml:"macaroon" json:"-"`
+	RouteSpend map[string]float64 `yaml:"route_spend" json:"-"`
+	DailySpend map[string]float64 `yaml:"daily_spend" json:"-"`
+}
+
+// Threat is a blocked request in the threat report
+type Threat struct {
+	Time    string `yaml:"time" json:"time"`
+	Type    string `yaml:"type" json:"type"`
+	Agent   string `yaml:"agent" json:"agent"`
+	TokenID string `yaml:"token_id" json:"token_id"`
+	Route   string `yaml:"route" json:"route"`
+	IP      string `yaml:"ip" json:"ip"`
+	Action  string `yaml:"action" json:"action"`
+}
+
+// Payment is an L402 invoice
+type Payment struct {
+	PaymentHash string `yaml:"payment_hash" json:"payment_hash"`
+	Route       string `yaml:"route" json:"route"`
+	Client      string `yaml:"client" json:"client"`
+	ClientName  string `yaml:"client_name" json:"client_name,omitempty"`
+	AmountSats  int64  `yaml:"amount_sats" json:"amount_sats"`
+	Status      string `yaml:"status" json:"status"`
+	CreatedAt   string `yaml:"created_at" json:"created_at"`
+	SettledAt   string `yaml:"settled_at" json:"settled_at,omitempty"`
+}
+
+// AuditEvent is a server-side audit record. Mint, revoke and label
+// changes made against the mock are appended automatically.
+type AuditEvent struct {
+	ID        string                 `yaml:"id" json:"id"`
+	Time      string                 `yaml:"time" json:"time"`
+	Actor     string                 `yaml:"actor" json:"actor"`
+	Source    string                 `yaml:"source" json:"source"`
+	Action    string                 `yaml:"action" json:"action"`
+	TokenID   string                 `yaml:"token_id" json:"token_id,omitempty"`
+	TokenName string                 `yaml:"token_name" json:"token_name,omitempty"`
+	RequestID string                 `yaml:"request_id" json:"request_id,omitempty"`
+	Details   map[string]interface{} `yaml:"details" json:"details,omitempty"`
+}
+
+// Example is a small fixture covering every endpoint, printed by
+// satgate mock seed
+//
+//go:embed example.yaml
+var Example []byte
+
+// Load reads a fixture file
+func Load(path string) (Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Fixture{}, err
+	}
+	f, err := Parse(data)
+	if err != nil {
+		return f, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return f, nil
+}
+
+// Parse decodes a YAML or JSON fixture
+func Parse(data []byte) (Fixture, error) {
+	var f Fixture
+	// YAML is a superset of JSON, so one decoder reads both
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return f, err
+	}
+	ids := map[string]bool{}
+	for _, t := range f.Tokens {
+		if t.ID == "" {
+			return f, fmt.Errorf("token %q has no id", t.Name)
+		}
+		if ids[t.ID] {
+			return f, fmt.Errorf("duplicate token id %s", t.ID)
+		}
+		ids[t.ID] = true
+	}
+	for _, t := range f.Tokens {
+		if t.ParentID != "" && !ids[t.ParentID] {
+			return f, fmt.Errorf("token %s: parent %s not in fixture", t.ID, t.ParentID)
+		}
+	}
+	return f, nil
+}
+
+// Server is the mock API. It is an http.Handler, so tests can wrap it in
+// httptest.NewServer.
+type Server struct {
+	// Now is the clock used for timestamps and expiry; defaults to time.Now
+	Now func() time.Time
+
+	mu       sync.Mutex
+	fx       Fixture
+	tokens   map[string]*Token
+	order    []string
+	seq      int
+	started  time.Time
+	requests []Request
+	mux      *http.ServeMux
+}
+
+// Request is one call the mock received, for assertions
+type Request struct {
+	Method string
+	Path   string
+	Query  string
+	Body   string
+}
+
+// New returns a server seeded with f. The fixture is copied; later changes
+// to it do not affect the server.
+func New(f Fixture) *Server {
+	s := &Server{Now: time.Now, fx: f, tokens: map[string]*Token{}}
+	s.fx.Routes = append([]Route(nil), f.Routes...)
+	s.fx.Threats = append([]Threat(nil), f.Threats...)
+	s.fx.Payments = append([]Payment(nil), f.Payments...)
+	s.fx.Audit = append([]AuditEvent(nil), f.Audit...)
+	s.fx.Tokens = nil
+	for _, t := range f.Tokens {
+		t := t
+		t.Labels = copyLabels(t.Labels)
+		if t.Status == "" {
+			t.Status = "active"
+		}
+		if t.Macaroon == "" {
+			t.Macaroon = encodeMacaroon(t)
+		}
+		s.tokens[t.ID] = &t
+		s.order = append(s.order, t.ID)
+	}
+	s.routes()
+	return s
+}
+
+// ServeHTTP records the request and dispatches it
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body := readBody(r)
+	s.mu.Lock()
+	if s.started.IsZero() {
+		s.started = s.Now()
+	}
+	s.requests = append(s.requests, Request{Method: r.Method, Path: r.URL.Path, Query: r.URL.RawQuery, Body: string(body)})
+	s.mu.Unlock()
+	s.mux.ServeHTTP(w, r)
+}
+
+// Requests returns the calls received so far, oldest first
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// Tokens returns a snapshot of every token in creation order
+func (s *Server) Tokens() []Token {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Token, 0, len(s.order))
+	for _, id := range s.order {
+		out = append(out, s.snapshot(s.tokens[id]))
+	}
+	return out
+}
+
+// Token returns a snapshot of one token
+func (s *Server) Token(id string) (Token, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.tokens[id]
+	if !ok {
+		return Token{}, false
+	}
+	return s.snapshot(t), true
+}
+
+func (s *Server) routes() {
+	m := http.NewServeMux()
+
+	m.HandleFunc("GET /healthz", s.handleHealth)
+	m.HandleFunc("GET /admin/ping", s.admin(s.handlePing))
+	m.HandleFunc("GET /admin/tokens", s.admin(s.handleListTokens))
+	m.HandleFunc("POST /admin/tokens/mint", s.admin(s.handleMint))
+	m.HandleFunc("GET /admin/tokens/{id}", s.admin(s.handleGetToken))
+	m.HandleFunc("PATCH /admin/tokens/{id}", s.admin(s.handlePatchToken))
+	m.HandleFunc("GET /admin/tokens/{id}/usage", s.admin(s.handleUsage))
+	m.HandleFunc("DELETE /admin/tokens/{id}/revoke", s.admin(s.handleRevoke))
+	m.HandleFunc("GET /admin/spend", s.admin(s.handleSpend))
+	m.HandleFunc("GET /admin/routes", s.admin(s.handleRoutes))
+	m.HandleFunc("GET /admin/reports/threats", s.admin(s.handleThreats))
+	m.HandleFunc("GET /admin/audit/events", s.admin(s.handleAudit))
+	m.HandleFunc("GET /admin/payments", s.admin(s.handlePayments))
+	m.HandleFunc("GET /admin/rates", s.admin(s.handleRates))
+
+	m.HandleFunc("GET /cloud/delegation-v2/tree", s.cloud(s.handleTree))
+	m.HandleFunc("POST /cloud/delegation-v2/delegate", s.cloud(s.handleDelegate))
+	m.HandleFunc("GET /cloud/delegation-v2/token/{id}", s.cloud(s.handleCloudToken))
+	m.HandleFunc("PATCH /cloud/delegation-v2/token/{id}", s.cloud(s.handleCloudPatch))
+	m.HandleFunc("GET /cloud/delegation-v2/token/{id}/usage", s.cloud(s.handleUsage))
+	m.HandleFunc("POST /cloud/delegation-v2/revoke/{id}", s.cloud(s.handleRevoke))
+	m.HandleFunc("GET /cloud/delegation-v2/cost-rollups", s.cloud(s.handleRollups))
+	m.HandleFunc("GET /cloud/reports/threats", s.cloud(s.handleThreats))
+	m.HandleFunc("GET /cloud/audit/events", s.cloud(s.handleAudit))
+
+	// Everything else is a protected route on the data plane
+	m.HandleFunc("/", s.handleProxy)
+	s.mux = m
+}
+
+// admin requires X-Admin-Token when the fixture sets one
+func (s *Server) admin(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.fx.AdminToken != "" && r.Header.Get("X-Admin-Token") != s.fx.AdminToken {
+			writeError(w, http.StatusUnauthorized, "invalid or missing admin token")
+			return
+		}
+		h(w, r)
+	}
+}
+
+// cloud requires the bearer token or session cookie when the fixture sets
+// either
+func (s *Server) cloud(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.fx.BearerToken != "" || s.fx.SessionToken != "" {
+			ok := s.fx.BearerToken != "" && r.Header.Get("Authorization") == "Bearer "+s.fx.BearerToken
+			if c, err := r.Cookie("satgate_session"); err == nil && s.fx.SessionToken != "" && c.Value == s.fx.SessionToken {
+				ok = true
+			}
+			if !ok {
+				writeError(w, http.StatusUnauthorized, "invalid or missing credentials")
+				return
+			}
+		}
+		h(w, r)
+	}
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "ok",
+		"version": s.version(),
+		"uptime":  s.Now().Sub(s.started).Round(time.Second).String(),
+	})
+}
+
+func (s *Server) handlePing(w http.ResponseWriter, r *http.Request) {
+	s.handleHealth(w, r)
+}
+
+func (s *Server) version() string {
+	if s.fx.Version != "" {
+		return s.fx.Version
+	}
+	return "mock"
+}
+
+// newID returns the next token ID. IDs are sequential so output is stable
+// across runs.
+func (s *Server) newID() string {
+	for {
+		s.seq++
+		id := fmt.Sprintf("tok_mock%08d", s.seq)
+		if _, taken := s.tokens[id]; !taken {
+			return id
+		}
+	}
+}
+
+func (s *Server) timestamp() string {
+	return s.Now().UTC().Format(time.RFC3339)
+}
+
+// snapshot returns a copy of t with its status as of now
+func (s *Server) snapshot(t *Token) Token {
+	out := *t
+	out.Labels = copyLabels(t.Labels)
+	out.Routes = append([]string(nil), t.Routes...)
+	if out.Status == "active" && out.ExpiresAt != "" {
+		if exp, err := time.Parse(time.RFC3339, out.ExpiresAt); err == nil && !s.Now().Before(exp) {
+			out.Status = "expired"
+		}
+	}
+	return out
+}
+
+// children returns the IDs of t's direct children in creation order
+func (s *Server) children(id string) []string {
+	var out []string
+	for _, cid := range s.order {
+		if s.tokens[cid].ParentID == id {
+			out = append(out, cid)
+		}
+	}
+	return out
+}
+
+// audit appends a server-side audit event for a mutation
+func (s *Server) audit(r *http.Request, action string, t *Token, details map[string]interface{}) {
+	s.fx.Audit = append(s.fx.Audit, AuditEvent{
+		ID:        fmt.Sprintf("evt_mock%08d", len(s.fx.Audit)+1),
+		Time:      s.timestamp(),
+		Actor:     "mock-admin",
+		Source:    "api",
+		Action:    action,
+		TokenID:   t.ID,
+		TokenName: t.Name,
+		RequestID: r.Header.Get("X-Request-Id"),
+		Details:   details,
+	})
+}
+
+func (s *Server) handleAudit(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	since, until := timeRange(q.Get("since"), q.Get("until"))
+	s.mu.Lock()
+	var events []AuditEvent
+	for _, e := range s.fx.Audit {
+		if !inRange(e.Time, since, until) ||
+			(q.Get("actor") != "" && e.Actor != q.Get("actor")) ||
+			(q.Get("action") != "" && e.Action != q.Get("action")) ||
+			(q.Get("token_id") != "" && e.TokenID != q.Get("token_id")) {
+			continue
+		}
+		events = append(events, e)
+	}
+	s.mu.Unlock()
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Time < events[j].Time })
+
+	page, next := paginate(len(events), q.Get("cursor"), q.Get("limit"))
+	writeJSON(w, http.StatusOK, map[string]interface{}{"events": nonNil(events[page[0]:page[1]]), "next_cursor": next})
+}
+
+// paginate returns the [start, end) window for an offset cursor and the
+// cursor of the next page, blank on the last one
+func paginate(n int, cursor, limit string) ([2]int, string) {
+	start, _ := strconv.Atoi(cursor)
+	size, err := strconv.Atoi(limit)
+	if err != nil || size <= 0 {
+		size = 100
+	}
+	if start < 0 || start > n {
+		start = n
+	}
+	end := start + size
+	if end >= n {
+		return [2]int{start, n}, ""
+	}
+	return [2]int{start, end}, strconv.Itoa(end)
+}
+
+// timeRange parses RFC 3339 since/until query values; blank is unbounded
+func timeRange(since, until string) (time.Time, time.Time) {
+	var from, to time.Time
+	if t, err := time.Parse(time.RFC3339, since); err == nil {
+		from = t
+	}
+	if t, err := time.Parse(time.RFC3339, until); err == nil {
+		to = t
+	}
+	return from, to
+}
+
+func inRange(ts string, since, until time.Time) bool {
+	if since.IsZero() && until.IsZero() {
+		return true
+	}
+	t, err := time.Parse(time.RFC3339, ts)
+	if err != nil {
+		return false
+	}
+	return (since.IsZero() || !t.Before(since)) && (until.IsZero() || t.Before(until))
+}
+
+// parseExpiry accepts a span such as 30d or 12h, or an RFC 3339 time
+func parseExpiry(s string, now time.Time) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "never" {
+		return "", nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.UTC().Format(time.RFC3339), nil
+	}
+	d, err := parseSpan(s)
+	if err != nil {
+		return "", err
+	}
+	return now.UTC().Add(d).Format(time.RFC3339), nil
+}
+
+// parseSpan parses a Go duration, with d for days
+func parseSpan(s string) (time.Duration, error) {
+	if strings.HasSuffix(s, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(s, "d"))
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	return d, nil
+}
+
+// readBody reads the request body and puts it back for the handler
+func readBody(r *http.Request) []byte {
+	if r.Body == nil {
+		return nil
+	}
+	data, _ := io.ReadAll(r.Body)
+	r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(data))
+	return data
+}
+
+func decodeBody(r *http.Request, v interface{}) error {
+	data, _ := io.ReadAll(r.Body)
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("invalid JSON body: %w", err)
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, msg string) {
+	writeJSON(w, code, map[string]string{"error": msg})
+}
+
+func copyLabels(in map[string]string) map[string]string {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(in))
+	for k, v := range in {
+		out[k] = v
+	}
+	return out
+}
+
+// nonNil keeps empty lists as [] rather than null in responses
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
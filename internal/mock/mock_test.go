@@ -0,0 +1,354 @@
+package mock
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SatGate-io/satgate-cli/internal/l402"
+)
+
+var testNow = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+func newTestServer(t *testing.T) (*Server, *httptest.Server) {
+	t.Helper()
+	fx, err := Parse(Example)
+	if err != nil {
+		t.Fatalf("parsing example fixture: %v", err)
+	}
+	s := New(fx)
+	s.Now = func() time.Time { return testNow }
+	ts := httptest.NewServer(s)
+	t.Cleanup(ts.Close)
+	return s, ts
+}
+
+// call makes an authenticated request to either surface and decodes the
+// JSON response into out when given
+func call(t *testing.T, ts *httptest.Server, method, path, body string, out interface{}) int {
+	t.Helper()
+	req, err := http.NewRequest(method, ts.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.HasPrefix(path, "/cloud/") {
+		req.Header.Set("Authorization", "Bearer sg_mock")
+	} else {
+		req.Header.Set("X-Admin-Token", "sgk_mock")
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			t.Fatalf("%s %s: decoding %q: %v", method, path, data, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestAuth(t *testing.T) {
+	_, ts := newTestServer(t)
+	for _, path := range []string{"/admin/tokens", "/cloud/delegation-v2/tree"} {
+		resp, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("GET %s without credentials = %d, want 401", path, resp.StatusCode)
+		}
+	}
+	resp, err := http.Get(ts.URL + "/healthz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("GET /healthz = %d, want 200", resp.StatusCode)
+	}
+}
+
+func TestGatewayMintAndRevoke(t *testing.T) {
+	s, ts := newTestServer(t)
+
+	var minted struct {
+		ID       string `json:"id"`
+		Macaroon string `json:"macaroon"`
+	}
+	body := `{"name":"bot","budget":25,"currency":"USD","routes":["/api/openai/*"],"labels":{"team":"ops"},"parent_id":"tok_platform0001","expiry":"7d"}`
+	if code := call(t, ts, "POST", "/admin/tokens/mint", body, &minted); code != http.StatusCreated {
+		t.Fatalf("mint = %d", code)
+	}
+	if minted.ID != "tok_mock00000001" {
+		t.Errorf("ID = %q, want tok_mock00000001", minted.ID)
+	}
+	mac, err := l402.DecodeMacaroon(minted.Macaroon)
+	if err != nil || mac.Identifier != minted.ID {
+		t.Errorf("macaroon identifier = %q (%v), want %s", mac.Identifier, err, minted.ID)
+	}
+
+	tok, ok := s.Token(minted.ID)
+	if !ok || tok.ParentID != "tok_platform0001" || tok.Labels["team"] != "ops" || tok.ExpiresAt != "2026-10-26T12:00:00Z" {
+		t.Errorf("stored token = %+v", tok)
+	}
+
+	var list struct {
+		Tokens []Token `json:"tokens"`
+	}
+	call(t, ts, "GET", "/admin/tokens", "", &list)
+	if len(list.Tokens) != 4 {
+		t.Errorf("listed %d tokens, want 4", len(list.Tokens))
+	}
+
+	// Revoking the root revokes everything delegated from it
+	var revoked struct {
+		Revoked []string `json:"revoked"`
+	}
+	if code := call(t, ts, "DELETE", "/admin/tokens/tok_platform0001/revoke", "", &revoked); code != http.StatusOK {
+		t.Fatalf("revoke = %d", code)
+	}
+	if got := strings.Join(revoked.Revoked, ","); got != "tok_platform0001,tok_support0002,tok_mock00000001" {
+		t.Errorf("revoked = %s", got)
+	}
+	if code := call(t, ts, "DELETE", "/admin/tokens/tok_nope/revoke", "", nil); code != http.StatusNotFound {
+		t.Errorf("revoking unknown token = %d, want 404", code)
+	}
+
+	var events struct {
+		Events []AuditEvent `json:"events"`
+	}
+	call(t, ts, "GET", "/admin/audit/events?action=revoke", "", &events)
+	if len(events.Events) != 1 || events.Events[0].TokenID != "tok_platform0001" {
+		t.Errorf("revoke audit events = %+v", events.Events)
+	}
+}
+
+func TestMintValidation(t *testing.T) {
+	_, ts := newTestServer(t)
+	tests := []struct {
+		name string
+		body string
+		code int
+	}{
+		{"missing name", `{"budget":1}`, http.StatusBadRequest},
+		{"unknown parent", `{"name":"x","budget":1,"parent_id":"tok_nope"}`, http.StatusNotFound},
+		{"over parent budget", `{"name":"x","budget":60,"parent_id":"tok_support0002"}`, http.StatusBadRequest},
+		{"wider scope", `{"name":"x","budget":1,"parent_id":"tok_support0002","routes":["/api/search/*"]}`, http.StatusForbidden},
+		{"currency mismatch", `{"name":"x","budget":1,"currency":"SAT","parent_id":"tok_support0002"}`, http.StatusBadRequest},
+		{"narrower scope", `{"name":"x","budget":50,"parent_id":"tok_support0002","routes":["/api/openai/v1/*"]}`, http.StatusCreated},
+	}
+	for _, tt := range tests {
+		if code := call(t, ts, "POST", "/admin/tokens/mint", tt.body, nil); code != tt.code {
+			t.Errorf("%s: HTTP %d, want %d", tt.name, code, tt.code)
+		}
+	}
+}
+
+func TestCloudDelegateAndLabels(t *testing.T) {
+	s, ts := newTestServer(t)
+
+	var resp struct {
+		Token struct {
+			ID          string `json:"id"`
+			BudgetLimit int64  `json:"budget_limit_credits"`
+			CostCenter  string `json:"costCenter"`
+			Depth       int    `json:"depth"`
+		} `json:"token"`
+		MacaroonToken string `json:"macaroon_token"`
+	}
+	body := `{"name":"kid","budget_limit_credits":1000,"scope":{"routes":["/api/openai/*"]},"parent_id":"tok_support0002","costCenter":"support"}`
+	if code := call(t, ts, "POST", "/cloud/delegation-v2/delegate", body, &resp); code != http.StatusCreated {
+		t.Fatalf("delegate = %d", code)
+	}
+	if resp.Token.BudgetLimit != 1000 || resp.Token.CostCenter != "support" || resp.Token.Depth != 2 || resp.MacaroonToken == "" {
+		t.Errorf("delegated token = %+v", resp)
+	}
+	if tok, _ := s.Token(resp.Token.ID); tok.Budget != 10 || tok.Labels[labelCostCenter] != "support" {
+		t.Errorf("stored token = %+v", tok)
+	}
+
+	// Merge patch: null removes, strings set
+	patch := `{"costCenter":null,"labels":{"owner":"alice","env":null}}`
+	if code := call(t, ts, "PATCH", "/cloud/delegation-v2/token/tok_support0002", patch, nil); code != http.StatusOK {
+		t.Fatalf("patch = %d", code)
+	}
+	tok, _ := s.Token("tok_support0002")
+	if _, ok := tok.Labels[labelCostCenter]; ok || tok.Labels["owner"] != "alice" || tok.Labels["env"] != "" {
+		t.Errorf("labels after patch = %v", tok.Labels)
+	}
+
+	var tree struct {
+		Tree []struct {
+			ID       string `json:"id"`
+			Children []struct {
+				ID       string `json:"id"`
+				Children []struct {
+					ID string `json:"id"`
+				} `json:"children"`
+			} `json:"children"`
+		} `json:"tree"`
+	}
+	call(t, ts, "GET", "/cloud/delegation-v2/tree", "", &tree)
+	if len(tree.Tree) != 2 || tree.Tree[0].Children[0].Children[0].ID != resp.Token.ID {
+		t.Errorf("tree = %+v", tree.Tree)
+	}
+
+	if code := call(t, ts, "POST", "/cloud/delegation-v2/revoke/tok_support0002", "", nil); code != http.StatusOK {
+		t.Fatalf("revoke = %d", code)
+	}
+	if tok, _ := s.Token(resp.Token.ID); tok.Status != "revoked" {
+		t.Errorf("child status = %s, want revoked", tok.Status)
+	}
+}
+
+func TestSpend(t *testing.T) {
+	_, ts := newTestServer(t)
+
+	var summary struct {
+		Agents []struct {
+			Name  string  `json:"name"`
+			Spent float64 `json:"spent"`
+		} `json:"agents"`
+	}
+	call(t, ts, "GET", "/admin/spend?agent=cs-bot", "", &summary)
+	if len(summary.Agents) != 1 || summary.Agents[0].Spent != 150 {
+		t.Errorf("cs-bot spend = %+v", summary.Agents)
+	}
+
+	var grouped struct {
+		Groups []spendGroup `json:"groups"`
+	}
+	call(t, ts, "GET", "/admin/spend?group_by=costCenter&since=2026-10-18T00:00:00Z&until=2026-10-19T00:00:00Z", "", &grouped)
+	got := map[string]float64{}
+	for _, g := range grouped.Groups {
+		got[g.Key] = g.Consumed
+	}
+	if got["eng"] != 35 || got["support"] != 80 || got["research"] != 0 {
+		t.Errorf("grouped spend for 2026-10-18 = %v", got)
+	}
+
+	var rollups struct {
+		Rollups []struct {
+			CostCenter    string `json:"costCenter"`
+			TotalConsumed int64  `json:"totalConsumed"`
+		} `json:"rollups"`
+	}
+	call(t, ts, "GET", "/cloud/delegation-v2/cost-rollups", "", &rollups)
+	if len(rollups.Rollups) != 3 || rollups.Rollups[0].CostCenter != "eng" || rollups.Rollups[0].TotalConsumed != 12050 {
+		t.Errorf("rollups = %+v", rollups.Rollups)
+	}
+}
+
+func TestDataPlane(t *testing.T) {
+	s, ts := newTestServer(t)
+	var minted struct {
+		ID       string `json:"id"`
+		Macaroon string `json:"macaroon"`
+	}
+	call(t, ts, "POST", "/admin/tokens/mint", `{"name":"bot","budget":0.03,"routes":["/api/openai/*"]}`, &minted)
+
+	get := func(path string) *http.Response {
+		req, _ := http.NewRequest("GET", ts.URL+path, nil)
+		req.Header.Set("Authorization", "Bearer "+minted.Macaroon)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	resp := get("/api/openai/v1/models")
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("X-SatGate-Charge") != "0.02" {
+		t.Errorf("allowed request = %d, charge %q", resp.StatusCode, resp.Header.Get("X-SatGate-Charge"))
+	}
+	if tok, _ := s.Token(minted.ID); tok.Spent != 0.02 || tok.LastUsedAt == "" {
+		t.Errorf("after one request: %+v", tok)
+	}
+
+	resp = get("/api/openai/v1/models")
+	if resp.StatusCode != http.StatusForbidden || resp.Header.Get("X-SatGate-Reason") != "budget_exceeded" {
+		t.Errorf("over budget = %d %q", resp.StatusCode, resp.Header.Get("X-SatGate-Reason"))
+	}
+	// Charge routes are paid over Lightning, not from the budget
+	if resp = get("/api/premium/search"); resp.StatusCode != http.StatusOK {
+		t.Errorf("charge route with credential = %d", resp.StatusCode)
+	}
+	if resp, _ := http.Get(ts.URL + "/api/premium/search"); resp.StatusCode != http.StatusPaymentRequired {
+		t.Errorf("charge route without credential = %d, want 402", resp.StatusCode)
+	}
+	if resp, _ := http.Get(ts.URL + "/health"); resp.StatusCode != http.StatusOK {
+		t.Errorf("public route = %d", resp.StatusCode)
+	}
+
+	var report struct {
+		TotalBlocked  int      `json:"total_blocked"`
+		RecentThreats []Threat `json:"recent_threats"`
+	}
+	call(t, ts, "GET", "/admin/reports/threats?since=2026-10-19T00:00:00Z", "", &report)
+	blocked := map[string]string{}
+	for _, th := range report.RecentThreats {
+		blocked[th.Type] = th.TokenID
+	}
+	if report.TotalBlocked != 2 || blocked["budget_exceeded"] != minted.ID || len(blocked) != 2 {
+		t.Errorf("threats from today = %+v", report)
+	}
+}
+
+func TestPagination(t *testing.T) {
+	_, ts := newTestServer(t)
+	var page struct {
+		Payments   []Payment `json:"payments"`
+		NextCursor string    `json:"next_cursor"`
+	}
+	call(t, ts, "GET", "/admin/payments?limit=1", "", &page)
+	if len(page.Payments) != 1 || page.NextCursor != "1" {
+		t.Fatalf("first page = %+v", page)
+	}
+	call(t, ts, "GET", "/admin/payments?limit=1&cursor="+page.NextCursor, "", &page)
+	if len(page.Payments) != 1 || page.NextCursor != "" || page.Payments[0].Status != "expired" {
+		t.Errorf("last page = %+v", page)
+	}
+}
+
+func TestPaymentsWindowBySettlement(t *testing.T) {
+	fx := Fixture{Payments: []Payment{
+		{PaymentHash: "early", Status: "settled", CreatedAt: "2026-10-11T23:59:00Z", SettledAt: "2026-10-12T00:00:30Z"},
+		{PaymentHash: "late", Status: "settled", CreatedAt: "2026-10-12T23:59:50Z", SettledAt: "2026-10-13T00:00:10Z"},
+		{PaymentHash: "unpaid", Status: "expired", CreatedAt: "2026-10-12T08:00:00Z"},
+	}}
+	ts := httptest.NewServer(New(fx))
+	defer ts.Close()
+
+	var page struct {
+		Payments []Payment `json:"payments"`
+	}
+	call(t, ts, "GET", "/admin/payments?since=2026-10-12T00:00:00Z&until=2026-10-13T00:00:00Z", "", &page)
+	var hashes []string
+	for _, p := range page.Payments {
+		hashes = append(hashes, p.PaymentHash)
+	}
+	if got := strings.Join(hashes, ","); got != "early,unpaid" {
+		t.Errorf("payments in window = %s; want early,unpaid", got)
+	}
+}
+
+func TestParseRejectsBadFixtures(t *testing.T) {
+	for _, data := range []string{
+		"tokens: [{id: a}, {id: a}]",
+		"tokens: [{id: a, parent_id: b}]",
+		"tokens: [{name: no-id}]",
+	} {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("Parse(%q) succeeded", data)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package mock
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// handleProxy stands in for the gateway's data plane. Requests to a
+// configured route are checked against the presented macaroon: public
+// routes pass, control routes need an active token in scope with budget
+// left and are charged the route's cost, observe routes are charged but
+// never blocked. Charge routes answer 402 without a credential; the mock
+// does not issue Lightning invoices. Blocked requests are added to the
+// threat report.
+func (s *Server) handleProxy(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.URL.Path, "/admin/") || strings.HasPrefix(r.URL.Path, "/cloud/") {
+		writeError(w, http.StatusNotFound, "mock does not implement "+r.Method+" "+r.URL.Path)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	route := s.matchRoute(r.URL.Path)
+	if route == nil {
+		writeError(w, http.StatusNotFound, "no route configured for "+r.URL.Path)
+		return
+	}
+	policy := route.Policy
+	switch policy {
+	case "chargeback":
+		policy = "observe"
+	case "fiat402":
+		policy = "control"
+	case "l402":
+		policy = "charge"
+	}
+	if policy == "public" {
+		s.allow(w, r, route, nil, 0)
+		return
+	}
+
+	mac := credential(r.Header.Get("Authorization"))
+	t := s.tokenByMacaroon(mac)
+	if t == nil {
+		switch {
+		case policy == "observe":
+			s.allow(w, r, route, nil, 0)
+		case policy == "charge" && mac == "":
+			w.Header().Set("X-SatGate-Price-Sats", strconv.FormatInt(route.PriceSats, 10))
+			s.deny(w, r, http.StatusPaymentRequired, route, nil, "payment_required", "", "payment required")
+		case mac == "":
+			s.deny(w, r, http.StatusUnauthorized, route, nil, "missing_token", "", "missing macaroon")
+		default:
+			s.deny(w, r, http.StatusUnauthorized, route, nil, "invalid_token", "", "unknown macaroon")
+		}
+		return
+	}
+	if policy == "observe" {
+		s.allow(w, r, route, t, route.Cost)
+		return
+	}
+	if policy == "charge" {
+		// Paid per request over Lightning, not from the token's budget
+		s.allow(w, r, route, t, 0)
+		return
+	}
+
+	snap := s.snapshot(t)
+	switch {
+	case snap.Status != "active":
+		caveat := ""
+		if snap.Status == "expired" {
+			caveat = "expires=" + snap.ExpiresAt
+		}
+		s.deny(w, r, http.StatusForbidden, route, t, "token_"+snap.Status, caveat, "token is "+snap.Status)
+	case !covered(r.URL.Path, t.Routes):
+		s.deny(w, r, http.StatusForbidden, route, t, "route_denied", "routes="+strings.Join(t.Routes, ","), "route not in token scope")
+	case t.Budget > 0 && t.Spent+route.Cost > t.Budget+1e-9:
+		s.deny(w, r, http.StatusForbidden, route, t, "budget_exceeded", "budget="+strconv.FormatFloat(t.Budget, 'f', -1, 64), "budget exhausted")
+	default:
+		s.allow(w, r, route, t, route.Cost)
+	}
+}
+
+// matchRoute returns the most specific route for path
+func (s *Server) matchRoute(path string) *Route {
+	var best *Route
+	for i := range s.fx.Routes {
+		rt := &s.fx.Routes[i]
+		if matchRoute(rt.Path, path) && (best == nil || len(rt.Path) > len(best.Path)) {
+			best = rt
+		}
+	}
+	return best
+}
+
+// credential extracts the macaroon from "Bearer <mac>", "L402 <mac>:<preimage>"
+// or "LSAT <mac>:<preimage>"
+func credential(auth string) string {
+	scheme, value, ok := strings.Cut(strings.TrimSpace(auth), " ")
+	if !ok {
+		return ""
+	}
+	switch strings.ToUpper(scheme) {
+	case "BEARER":
+		return strings.TrimSpace(value)
+	case "L402", "LSAT":
+		mac, _, _ := strings.Cut(strings.TrimSpace(value), ":")
+		return mac
+	}
+	return ""
+}
+
+func (s *Server) tokenByMacaroon(mac string) *Token {
+	if mac == "" {
+		return nil
+	}
+	for _, id := range s.order {
+		if t := s.tokens[id]; t.Macaroon == mac {
+			return t
+		}
+	}
+	return nil
+}
+
+func (s *Server) allow(w http.ResponseWriter, r *http.Request, route *Route, t *Token, cost float64) {
+	w.Header().Set("X-SatGate-Decision", "allow")
+	w.Header().Set("X-SatGate-Policy", route.Policy)
+	resp := map[string]interface{}{"ok": true, "route": route.Path}
+	if t != nil {
+		now := s.Now().UTC()
+		t.Spent += cost
+		t.LastUsedAt = now.Format(time.RFC3339)
+		if cost > 0 {
+			if t.RouteSpend == nil {
+				t.RouteSpend = map[string]float64{}
+			}
+			if t.DailySpend == nil {
+				t.DailySpend = map[string]float64{}
+			}
+			t.RouteSpend[route.Path] += cost
+			t.DailySpend[now.Format("2006-01-02")] += cost
+		}
+		w.Header().Set("X-SatGate-Token", t.ID)
+		w.Header().Set("X-SatGate-Charge", strconv.FormatFloat(cost, 'f', -1, 64))
+		resp["token_id"] = t.ID
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) deny(w http.ResponseWriter, r *http.Request, code int, route *Route, t *Token, reason, caveat, msg string) {
+	threat := Threat{
+		Time:   s.timestamp(),
+		Type:   reason,
+		Route:  r.URL.Path,
+		IP:     clientIP(r),
+		Action: "blocked",
+	}
+	w.Header().Set("X-SatGate-Decision", "deny")
+	w.Header().Set("X-SatGate-Policy", route.Policy)
+	w.Header().Set("X-SatGate-Reason", reason)
+	if caveat != "" {
+		w.Header().Set("X-SatGate-Caveat-Failed", caveat)
+	}
+	if t != nil {
+		threat.Agent, threat.TokenID = t.Name, t.ID
+		w.Header().Set("X-SatGate-Token", t.ID)
+	}
+	s.fx.Threats = append(s.fx.Threats, threat)
+	writeJSON(w, code, map[string]string{"error": msg, "reason": reason})
+}
+
+func clientIP(r *http.Request) string {
+	host := r.RemoteAddr
+	if i := strings.LastIndex(host, ":"); i >= 0 {
+		host = host[:i]
+	}
+	return strings.Trim(host, "[]")
+}
+
+// encodeMacaroon builds a v2 binary macaroon whose identifier is the token
+// ID, with the token's scope and expiry as first-party caveats. The
+// signature is a hash of the contents; the mock only compares macaroons
+// byte for byte.
+func encodeMacaroon(t Token) string {
+	var b []byte
+	field := func(typ byte, v string) {
+		b = append(b, typ)
+		b = binary.AppendUvarint(b, uint64(len(v)))
+		b = append(b, v...)
+	}
+	b = append(b, 2)
+	field(1, "satgate-mock")
+	field(2, t.ID)
+	b = append(b, 0)
+	caveats := []string{"token_id = " + t.ID}
+	if len(t.Routes) > 0 {
+		caveats = append(caveats, "routes = "+strings.Join(t.Routes, ","))
+	}
+	if t.ExpiresAt != "" {
+		caveats = append(caveats, "expires = "+t.ExpiresAt)
+	}
+	for _, c := range caveats {
+		field(2, c)
+		b = append(b, 0)
+	}
+	b = append(b, 0)
+	sig := sha256.Sum256(b)
+	field(6, string(sig[:]))
+	return base64.RawURLEncoding.EncodeToString(b)
+}
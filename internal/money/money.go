@@ -0,0 +1,248 @@
+// Package money represents budgets and spend as integer minor units of a
+// currency, so amounts like 0.29 survive the trip from a flag to the wire
+// without float rounding. USD and EUR have cents; sats and msats are their
+// own minor unit.
+package money
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Currency is a supported budget currency
+type Currency struct {
+	Code   string // USD, EUR, SAT, MSAT
+	Digits int    // decimal places of the display unit
+	symbol string // prefix, e.g. "$"
+	unit   string // suffix, e.g. "sats"
+}
+
+var (
+	USD  = Currency{Code: "USD", Digits: 2, symbol: "$"}
+	EUR  = Currency{Code: "EUR", Digits: 2, symbol: "€"}
+	SAT  = Currency{Code: "SAT", unit: "sats"}
+	MSAT = Currency{Code: "MSAT", unit: "msats"}
+)
+
+var currencies = map[string]Currency{
+	"USD": USD, "$": USD,
+	"EUR": EUR, "€": EUR,
+	"SAT": SAT, "SATS": SAT,
+	"MSAT": MSAT, "MSATS": MSAT,
+}
+
+// Lookup returns the currency for a code, case-insensitively. A blank code
+// is USD, the default on both surfaces.
+func Lookup(code string) (Currency, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return USD, nil
+	}
+	if c, ok := currencies[code]; ok {
+		return c, nil
+	}
+	return Currency{}, fmt.Errorf("unsupported currency %q (use USD, EUR, SAT or MSAT)", code)
+}
+
+func (c Currency) String() string { return c.Code }
+
+func (c Currency) scale() int64 {
+	return int64(math.Pow10(c.Digits))
+}
+
+// Amount is a quantity of a currency in its minor units (cents, sats,
+// msats)
+type Amount struct {
+	Units    int64
+	Currency Currency
+}
+
+// New returns units minor units of c
+func New(units int64, c Currency) Amount {
+	return Amount{Units: units, Currency: c}
+}
+
+// Parse reads a decimal amount such as "0.29", "$5" or "1500 sats". A
+// symbol or unit in s overrides c. More decimal places than the currency
+// has is an error rather than a silent rounding.
+func Parse(s string, c Currency) (Amount, error) {
+	in := s
+	s = strings.TrimSpace(s)
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+	for _, sym := range []string{"$", "€"} {
+		if rest, ok := strings.CutPrefix(s, sym); ok {
+			c, s = currencies[sym], strings.TrimSpace(rest)
+		}
+	}
+	if i := strings.IndexFunc(s, func(r rune) bool { return r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' }); i > 0 {
+		cur, err := Lookup(s[i:])
+		if err != nil {
+			return Amount{}, err
+		}
+		c, s = cur, strings.TrimSpace(s[:i])
+	}
+	s = strings.ReplaceAll(s, "_", "")
+
+	whole, frac, _ := strings.Cut(s, ".")
+	if whole == "" && frac == "" || !digits(whole) || !digits(frac) {
+		return Amount{}, fmt.Errorf("invalid amount %q", in)
+	}
+	if len(frac) > c.Digits {
+		if c.Digits == 0 {
+			return Amount{}, fmt.Errorf("invalid amount %q: %s has no fractional units", in, c.unit)
+		}
+		return Amount{}, fmt.Errorf("invalid amount %q: %s has at most %d decimal places", in, c.Code, c.Digits)
+	}
+	frac += strings.Repeat("0", c.Digits-len(frac))
+	n := strings.TrimLeft(whole+frac, "0")
+	if n == "" {
+		n = "0"
+	}
+	units, err := strconv.ParseInt(n, 10, 64)
+	if err != nil {
+		return Amount{}, fmt.Errorf("invalid amount %q: too large", in)
+	}
+	if neg {
+		units = -units
+	}
+	return Amount{Units: units, Currency: c}, nil
+}
+
+func digits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// FromFloat converts an API amount in display units, rounding to the
+// nearest minor unit
+func FromFloat(v float64, c Currency) Amount {
+	return Amount{Units: int64(math.Round(v * float64(c.scale()))), Currency: c}
+}
+
+// FromCredits converts cloud credits, which are US cents
+func FromCredits(credits float64) Amount {
+	return Amount{Units: int64(math.Round(credits)), Currency: USD}
+}
+
+// Credits returns the amount as cloud credits. Cloud budgets are USD only.
+func (a Amount) Credits() (int64, error) {
+	if a.Currency != USD {
+		return 0, fmt.Errorf("cloud budgets are in USD credits; %s is not supported on the cloud surface", a.Currency)
+	}
+	return a.Units, nil
+}
+
+// IsZero reports whether the amount is zero, which budgets treat as
+// unlimited
+func (a Amount) IsZero() bool { return a.Units == 0 }
+
+// Float returns the amount in display units, for arithmetic such as
+// utilization ratios
+func (a Amount) Float() float64 {
+	return float64(a.Units) / float64(a.Currency.scale())
+}
+
+// Decimal returns the exact display-unit value without symbol, e.g.
+// "0.29" or "1500", suitable for a JSON number
+func (a Amount) Decimal() string {
+	units := a.Units
+	sign := ""
+	if units < 0 {
+		sign, units = "-", -units
+	}
+	if a.Currency.Digits == 0 {
+		return sign + strconv.FormatInt(units, 10)
+	}
+	scale := a.Currency.scale()
+	return fmt.Sprintf("%s%d.%0*d", sign, units/scale, a.Currency.Digits, units%scale)
+}
+
+// String formats the amount for display: $0.29, €12.50, 1500 sats
+func (a Amount) String() string {
+	d := a.Decimal()
+	sign := ""
+	if strings.HasPrefix(d, "-") {
+		sign, d = "-", d[1:]
+	}
+	switch {
+	case a.Currency.symbol != "":
+		return sign + a.Currency.symbol + d
+	case a.Currency.unit != "":
+		return sign + d + " " + a.Currency.unit
+	}
+	return sign + d + " " + a.Currency.Code
+}
+
+// In converts between sats and msats, which needs no exchange rate.
+// Converting msats to sats rounds to the nearest sat.
+func (a Amount) In(c Currency) (Amount, error) {
+	switch {
+	case a.Currency == c:
+		return a, nil
+	case a.Currency == SAT && c == MSAT:
+		return Amount{Units: a.Units * 1000, Currency: MSAT}, nil
+	case a.Currency == MSAT && c == SAT:
+		return Amount{Units: int64(math.Round(float64(a.Units) / 1000)), Currency: SAT}, nil
+	}
+	return Amount{}, fmt.Errorf("converting %s to %s needs an exchange rate", a.Currency, c)
+}
+
+// Format displays an API amount given in display units of the named
+// currency. Unknown codes are shown with two decimals and the code.
+func Format(v float64, code string) string {
+	c, err := Lookup(code)
+	if err != nil {
+		c = Currency{Code: strings.ToUpper(code), Digits: 2}
+	}
+	return FromFloat(v, c).String()
+}
+
+// Totals sums amounts per currency, since spend in different currencies
+// cannot be added without a rate
+type Totals map[string]float64
+
+// Add adds v display units of the named currency (blank = USD)
+func (t Totals) Add(v float64, code string) {
+	code = strings.ToUpper(code)
+	if c, err := Lookup(code); err == nil {
+		code = c.Code
+	}
+	t[code] += v
+}
+
+// Single returns the total and its currency when only one currency is
+// present
+func (t Totals) Single() (float64, string, bool) {
+	if len(t) != 1 {
+		return 0, "", false
+	}
+	for code, v := range t {
+		return v, code, true
+	}
+	return 0, "", false
+}
+
+// String formats each currency's total, e.g. "$12.00 + 1500 sats"
+func (t Totals) String() string {
+	if len(t) == 0 {
+		return Format(0, "USD")
+	}
+	codes := make([]string, 0, len(t))
+	for code := range t {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	parts := make([]string, len(codes))
+	for i, code := range codes {
+		parts[i] = Format(t[code], code)
+	}
+	return strings.Join(parts, " + ")
+}
@@ -0,0 +1,112 @@
+package money
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		cur  Currency
+		want Amount
+	}{
+		{"0.29", USD, New(29, USD)},
+		{"5", USD, New(500, USD)},
+		{"$5", EUR, New(500, USD)},
+		{"€12.5", USD, New(1250, EUR)},
+		{"1500 sats", USD, New(1500, SAT)},
+		{"1_000", SAT, New(1000, SAT)},
+		{"250msat", USD, New(250, MSAT)},
+		{".5", USD, New(50, USD)},
+		{"-3", SAT, New(-3, SAT)},
+	} {
+		got, err := Parse(tc.in, tc.cur)
+		if err != nil || got != tc.want {
+			t.Errorf("Parse(%q, %s) = %v, %v; want %v", tc.in, tc.cur, got, err, tc.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		cur  Currency
+		want string
+	}{
+		{"0.299", USD, "at most 2 decimal places"},
+		{"1.5", SAT, "sats has no fractional units"},
+		{"abc", USD, "invalid amount"},
+		{"", USD, "invalid amount"},
+		{"5 GBP", USD, `unsupported currency "GBP"`},
+		{"99999999999999999999", SAT, "too large"},
+	} {
+		if _, err := Parse(tc.in, tc.cur); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("Parse(%q, %s) error = %v; want %q", tc.in, tc.cur, err, tc.want)
+		}
+	}
+}
+
+func TestCredits(t *testing.T) {
+	a, _ := Parse("0.29", USD)
+	if credits, err := a.Credits(); err != nil || credits != 29 {
+		t.Errorf("0.29 USD = %d credits, %v; want 29", credits, err)
+	}
+	if _, err := New(100, SAT).Credits(); err == nil {
+		t.Error("sats converted to cloud credits")
+	}
+	if got := FromCredits(29); got != New(29, USD) {
+		t.Errorf("FromCredits(29) = %v", got)
+	}
+}
+
+func TestIn(t *testing.T) {
+	if got, err := New(3, SAT).In(MSAT); err != nil || got != New(3000, MSAT) {
+		t.Errorf("3 sats in msats = %v, %v", got, err)
+	}
+	if got, err := New(1500, MSAT).In(SAT); err != nil || got != New(2, SAT) {
+		t.Errorf("1500 msats in sats = %v, %v; want 2 sats (rounded)", got, err)
+	}
+	if got, err := New(1499, MSAT).In(SAT); err != nil || got != New(1, SAT) {
+		t.Errorf("1499 msats in sats = %v, %v; want 1 sat", got, err)
+	}
+	if _, err := New(100, USD).In(SAT); err == nil || !strings.Contains(err.Error(), "needs an exchange rate") {
+		t.Errorf("USD to SAT: %v", err)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	for _, tc := range []struct {
+		v    float64
+		code string
+		want string
+	}{
+		{0.29, "USD", "$0.29"},
+		{1000, "", "$1000.00"},
+		{12.5, "eur", "€12.50"},
+		{1500, "SAT", "1500 sats"},
+		{250, "MSAT", "250 msats"},
+		{-4.5, "USD", "-$4.50"},
+		{3.456, "GBP", "3.46 GBP"},
+	} {
+		if got := Format(tc.v, tc.code); got != tc.want {
+			t.Errorf("Format(%g, %q) = %q; want %q", tc.v, tc.code, got, tc.want)
+		}
+	}
+	if got := New(29, USD).Decimal(); got != "0.29" {
+		t.Errorf("Decimal = %q", got)
+	}
+}
+
+func TestTotals(t *testing.T) {
+	tot := Totals{}
+	tot.Add(10, "usd")
+	tot.Add(2.5, "")
+	tot.Add(1500, "sats")
+	if got := tot.String(); got != "1500 sats + $12.50" {
+		t.Errorf("Totals = %q", got)
+	}
+	if _, _, ok := tot.Single(); ok {
+		t.Error("Single() ok with two currencies")
+	}
+}
@@ -0,0 +1,202 @@
+// Package policy evaluates an organization's token lifecycle policy against
+// a mint request before it is sent, so obviously non-compliant tokens are
+// stopped at the CLI rather than discovered in a later audit.
+package policy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/SatGate-io/satgate-cli/internal/money"
+	"gopkg.in/yaml.v3"
+)
+
+// Policy is the org policy file (default ~/.satgate/policy.yaml).
+// Every rule is optional; the zero value allows everything.
+type Policy struct {
+	// MaxBudget caps the budget per currency, e.g. {USD: 1000, SAT: 500000}.
+	// A SAT cap also applies to MSAT budgets and vice versa. Once any cap
+	// is set, unlimited budgets and currencies without a cap are rejected.
+	MaxBudget map[string]float64 `yaml:"max_budget"`
+
+	// MaxExpiryDays requires an expiry no later than this many days out
+	MaxExpiryDays int `yaml:"max_expiry_days"`
+
+	// ForbidWildcardRoutes rejects tokens scoped to all routes
+	ForbidWildcardRoutes bool `yaml:"forbid_wildcard_routes"`
+
+	// NamePattern is a regular expression agent names must match
+	NamePattern string `yaml:"name_pattern"`
+
+	// RequireParent forces delegation under an existing token, except for
+	// the OS users listed in Admins
+	RequireParent bool     `yaml:"require_parent"`
+	Admins        []string `yaml:"admins"`
+
+	path string
+}
+
+// MintRequest is the subset of a mint the policy looks at
+type MintRequest struct {
+	Agent  string
+	Budget money.Amount  // zero = unlimited
+	Expiry time.Duration // 0 = never expires
+	Routes []string      // empty = all routes
+	Parent string
+	User   string // OS user performing the mint
+}
+
+// Violation is one broken rule
+type Violation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// DefaultPath returns ~/.satgate/policy.yaml
+func DefaultPath() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".satgate", "policy.yaml")
+}
+
+// Load reads a policy file. A missing file at the default location means
+// no policy (nil, nil); an explicitly configured file must exist.
+func Load(path string) (*Policy, error) {
+	explicit := path != ""
+	if !explicit {
+		path = DefaultPath()
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) && !explicit {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading policy file: %w", err)
+	}
+
+	p := &Policy{path: path}
+	if err := yaml.Unmarshal(data, p); err != nil {
+		return nil, fmt.Errorf("parsing policy file %s: %w", path, err)
+	}
+	for cur := range p.MaxBudget {
+		if _, err := money.Lookup(cur); err != nil {
+			return nil, fmt.Errorf("policy file %s: max_budget: %w", path, err)
+		}
+	}
+	if p.NamePattern != "" {
+		if _, err := regexp.Compile(p.NamePattern); err != nil {
+			return nil, fmt.Errorf("policy file %s: invalid name_pattern: %w", path, err)
+		}
+	}
+	return p, nil
+}
+
+// Path returns the file the policy was loaded from
+func (p *Policy) Path() string {
+	return p.path
+}
+
+// Evaluate returns every rule the request breaks
+func (p *Policy) Evaluate(req MintRequest) []Violation {
+	var v []Violation
+
+	if len(p.MaxBudget) > 0 {
+		if rule := p.budgetViolation(req.Budget); rule != "" {
+			v = append(v, Violation{"max_budget", rule})
+		}
+	}
+
+	if p.MaxExpiryDays > 0 {
+		limit := time.Duration(p.MaxExpiryDays) * 24 * time.Hour
+		if req.Expiry <= 0 {
+			v = append(v, Violation{"max_expiry_days", fmt.Sprintf("an expiry is required (at most %dd)", p.MaxExpiryDays)})
+		} else if req.Expiry > limit {
+			v = append(v, Violation{"max_expiry_days", fmt.Sprintf("expiry %s exceeds the maximum of %dd", formatDays(req.Expiry), p.MaxExpiryDays)})
+		}
+	}
+
+	if p.ForbidWildcardRoutes {
+		if len(req.Routes) == 0 {
+			v = append(v, Violation{"forbid_wildcard_routes", "tokens must be scoped to explicit routes (--routes)"})
+		}
+		for _, r := range req.Routes {
+			if r == "*" || r == "/*" {
+				v = append(v, Violation{"forbid_wildcard_routes", fmt.Sprintf("wildcard route %q is not allowed", r)})
+			}
+		}
+	}
+
+	if p.NamePattern != "" {
+		if re := regexp.MustCompile(p.NamePattern); !re.MatchString(req.Agent) {
+			v = append(v, Violation{"name_pattern", fmt.Sprintf("agent name %q does not match %s", req.Agent, p.NamePattern)})
+		}
+	}
+
+	if p.RequireParent && req.Parent == "" && !p.isAdmin(req.User) {
+		v = append(v, Violation{"require_parent", fmt.Sprintf("user %q must mint under a parent token (--parent)", req.User)})
+	}
+
+	return v
+}
+
+// budgetViolation checks a budget against the cap for its currency and,
+// for sats and msats, the cap in the other unit. It returns "" when the
+// budget is within every cap that applies.
+func (p *Policy) budgetViolation(budget money.Amount) string {
+	var caps []money.Amount
+	for code, max := range p.MaxBudget {
+		cur, _ := money.Lookup(code) // validated by Load
+		caps = append(caps, money.FromFloat(max, cur))
+	}
+	sort.Slice(caps, func(i, j int) bool { return caps[i].Currency.Code < caps[j].Currency.Code })
+
+	var capped []string
+	applies := false
+	for _, max := range caps {
+		capped = append(capped, max.Currency.Code)
+		limit, err := max.In(budget.Currency)
+		if err != nil {
+			continue
+		}
+		applies = true
+		b := budget
+		if max.Currency == money.MSAT {
+			b, _ = budget.In(money.MSAT) // compare in msats, not a rounded cap
+			limit = max
+		}
+		switch {
+		case budget.IsZero():
+			return fmt.Sprintf("unlimited budget not allowed; set --budget ≤ %s", max)
+		case b.Units > limit.Units:
+			return fmt.Sprintf("budget %s exceeds the maximum of %s", budget, max)
+		}
+	}
+	switch {
+	case applies:
+		return ""
+	case budget.IsZero():
+		return fmt.Sprintf("unlimited budget not allowed; set a --budget in %s", strings.Join(capped, ", "))
+	}
+	return fmt.Sprintf("no budget cap is configured for %s; use one of %s", budget.Currency, strings.Join(capped, ", "))
+}
+
+func (p *Policy) isAdmin(user string) bool {
+	for _, a := range p.Admins {
+		if a == user {
+			return true
+		}
+	}
+	return false
+}
+
+func formatDays(d time.Duration) string {
+	days := d.Hours() / 24
+	if days == float64(int(days)) {
+		return fmt.Sprintf("%dd", int(days))
+	}
+	return d.String()
+}
@@ -0,0 +1,70 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SatGate-io/satgate-cli/internal/money"
+)
+
+func TestMaxBudget(t *testing.T) {
+	p := &Policy{MaxBudget: map[string]float64{"usd": 1000, "SAT": 500000}}
+	for _, tc := range []struct {
+		name   string
+		budget money.Amount
+		want   string // substring of the violation, "" = allowed
+	}{
+		{"usd within cap", money.New(100000, money.USD), ""},
+		{"usd over cap", money.New(100001, money.USD), "budget $1000.01 exceeds the maximum of $1000.00"},
+		{"usd unlimited", money.New(0, money.USD), "unlimited budget not allowed; set --budget ≤ $1000.00"},
+		{"sats within cap", money.New(500000, money.SAT), ""},
+		{"msats use the sat cap", money.New(500000000, money.MSAT), ""},
+		{"msats over the sat cap", money.New(500000001, money.MSAT), "budget 500000001 msats exceeds the maximum of 500000 sats"},
+		{"msats unlimited", money.New(0, money.MSAT), "unlimited budget not allowed; set --budget ≤ 500000 sats"},
+		{"eur has no cap", money.New(100, money.EUR), "no budget cap is configured for EUR; use one of SAT, USD"},
+		{"eur unlimited", money.New(0, money.EUR), "unlimited budget not allowed; set a --budget in SAT, USD"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			v := p.Evaluate(MintRequest{Agent: "ci", Budget: tc.budget})
+			if tc.want == "" {
+				if len(v) != 0 {
+					t.Fatalf("violations = %+v; want none", v)
+				}
+				return
+			}
+			if len(v) != 1 || v[0].Rule != "max_budget" || !strings.Contains(v[0].Message, tc.want) {
+				t.Fatalf("violations = %+v; want max_budget %q", v, tc.want)
+			}
+		})
+	}
+}
+
+func TestMaxBudgetMsatCapAppliesToSats(t *testing.T) {
+	p := &Policy{MaxBudget: map[string]float64{"MSAT": 1500}}
+	if v := p.Evaluate(MintRequest{Budget: money.New(1, money.SAT)}); len(v) != 0 {
+		t.Errorf("1 sat under a 1500 msat cap: %+v", v)
+	}
+	// 2 sats is 2000 msats, over the cap even though 1500 msats rounds to 2 sats
+	if v := p.Evaluate(MintRequest{Budget: money.New(2, money.SAT)}); len(v) != 1 {
+		t.Errorf("2 sats under a 1500 msat cap: %+v", v)
+	}
+}
+
+func TestNoMaxBudgetAllowsAnyCurrency(t *testing.T) {
+	p := &Policy{}
+	for _, b := range []money.Amount{money.New(0, money.USD), money.New(99999, money.EUR)} {
+		if v := p.Evaluate(MintRequest{Budget: b}); len(v) != 0 {
+			t.Errorf("%s: %+v", b, v)
+		}
+	}
+}
+
+func TestLoadRejectsUnknownCurrency(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	os.WriteFile(path, []byte("max_budget:\n  GBP: 100\n"), 0600)
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), `unsupported currency "GBP"`) {
+		t.Errorf("Load = %v", err)
+	}
+}
@@ -0,0 +1,267 @@
+// Package rates converts amounts between currencies for display, using a
+// pluggable rate source: a static rates file, the rates the gateway
+// reports, or an HTTP price endpoint. Every conversion carries the rate,
+// source and timestamp used so output can say how it was computed.
+package rates
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Table is a set of rates from one source. Rates are keyed "FROM-TO" and
+// give the price of one FROM in TO, e.g. "BTC-USD": 65000.
+type Table struct {
+	Rates  map[string]float64
+	Source string
+	AsOf   time.Time
+}
+
+// Quote is the rate used for one conversion
+type Quote struct {
+	From   string    `json:"from"`
+	To     string    `json:"to"`
+	Rate   float64   `json:"rate"` // 1 From = Rate To
+	Source string    `json:"source"`
+	AsOf   time.Time `json:"as_of,omitempty"`
+}
+
+// sub-units of bitcoin, priced through BTC
+var btcUnits = map[string]float64{"BTC": 1, "SAT": 1e-8, "SATS": 1e-8, "MSAT": 1e-11, "MSATS": 1e-11}
+
+// base maps a currency to the code rates are quoted in and the factor
+// from one unit of it to one unit of that code
+func base(code string) (string, float64) {
+	code = strings.ToUpper(code)
+	if code == "" {
+		return "USD", 1
+	}
+	if f, ok := btcUnits[code]; ok {
+		return "BTC", f
+	}
+	return code, 1
+}
+
+// Rate returns the price of one from in to. Besides a direct quote it
+// uses the inverse pair, or crosses through USD, EUR or BTC.
+func (t *Table) Rate(from, to string) (Quote, error) {
+	q := Quote{From: strings.ToUpper(from), To: strings.ToUpper(to), Source: t.Source, AsOf: t.AsOf}
+	fb, ff := base(from)
+	tb, tf := base(to)
+
+	r, ok := 1.0, fb == tb
+	if !ok {
+		r, ok = t.pair(fb, tb)
+	}
+	for _, pivot := range []string{"USD", "EUR", "BTC"} {
+		if ok {
+			break
+		}
+		a, okA := t.pair(fb, pivot)
+		b, okB := t.pair(pivot, tb)
+		r, ok = a*b, okA && okB
+	}
+	if !ok {
+		return q, fmt.Errorf("no %s-%s rate in %s", fb, tb, t.Source)
+	}
+	q.Rate = r * ff / tf
+	return q, nil
+}
+
+func (t *Table) pair(from, to string) (float64, bool) {
+	if from == to {
+		return 1, true
+	}
+	if r, ok := t.Rates[from+"-"+to]; ok && r > 0 {
+		return r, true
+	}
+	if r, ok := t.Rates[to+"-"+from]; ok && r > 0 {
+		return 1 / r, true
+	}
+	return 0, false
+}
+
+// Provider loads a rate table
+type Provider interface {
+	Load() (*Table, error)
+	String() string
+}
+
+// Getter fetches a gateway admin API path, as client.Client.Get does
+type Getter func(path string) ([]byte, int, error)
+
+// Clock stamps rates that carry no timestamp of their own with the time
+// they were fetched
+type Clock func() time.Time
+
+// DefaultPath returns ~/.satgate/rates.yaml
+func DefaultPath() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".satgate", "rates.yaml")
+}
+
+// Sources lists the accepted --rates values, for help and error text
+const Sources = "static, file:PATH, gateway, or an http(s) URL"
+
+// Parse selects a provider: "static" (the default rates file),
+// "file:PATH", "gateway" or an http(s) URL. Blank picks the default rates
+// file if it exists and the gateway otherwise. get is nil where there is
+// no gateway to ask, as on the cloud surface; the gateway source then
+// fails when a conversion first needs it.
+func Parse(spec string, get Getter, now Clock) (Provider, error) {
+	switch {
+	case spec == "":
+		if _, err := os.Stat(DefaultPath()); err == nil {
+			return fileProvider{path: DefaultPath()}, nil
+		}
+		return gatewayProvider{get: get, now: now}, nil
+	case spec == "static":
+		return fileProvider{path: DefaultPath()}, nil
+	case strings.HasPrefix(spec, "file:"), strings.HasPrefix(spec, "static:"):
+		_, path, _ := strings.Cut(spec, ":")
+		return fileProvider{path: path}, nil
+	case spec == "gateway":
+		return gatewayProvider{get: get, now: now}, nil
+	case strings.HasPrefix(spec, "http://"), strings.HasPrefix(spec, "https://"):
+		return httpProvider{url: spec, now: now}, nil
+	}
+	return nil, fmt.Errorf("invalid rate source %q (use %s)", spec, Sources)
+}
+
+// document is the rates payload shared by every source. YAML is a superset
+// of JSON, so one decoder reads files and HTTP responses alike. A single
+// quote ({"pair": "BTC-USD", "rate": 65000}) is accepted too.
+type document struct {
+	Rates     map[string]float64 `yaml:"rates"`
+	Source    string             `yaml:"source"`
+	AsOf      string             `yaml:"as_of"`
+	UpdatedAt string             `yaml:"updated_at"`
+	Timestamp string             `yaml:"timestamp"`
+	Pair      string             `yaml:"pair"`
+	From      string             `yaml:"from"`
+	To        string             `yaml:"to"`
+	Rate      float64            `yaml:"rate"`
+	Price     float64            `yaml:"price"`
+}
+
+func decode(data []byte, source string, fallback time.Time) (*Table, error) {
+	var doc document
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing rates from %s: %w", source, err)
+	}
+	t := &Table{Rates: map[string]float64{}, Source: source, AsOf: fallback}
+	for pair, r := range doc.Rates {
+		t.Rates[normalizePair(pair)] = r
+	}
+	if doc.Pair == "" && doc.From != "" && doc.To != "" {
+		doc.Pair = doc.From + "-" + doc.To
+	}
+	if r := firstPositive(doc.Rate, doc.Price); doc.Pair != "" && r > 0 {
+		t.Rates[normalizePair(doc.Pair)] = r
+	}
+	if len(t.Rates) == 0 {
+		return nil, fmt.Errorf("no rates in %s", source)
+	}
+	if doc.Source != "" {
+		t.Source = doc.Source + " via " + source
+	}
+	for _, ts := range []string{doc.AsOf, doc.UpdatedAt, doc.Timestamp} {
+		if parsed, err := time.Parse(time.RFC3339, ts); err == nil {
+			t.AsOf = parsed
+			break
+		}
+	}
+	return t, nil
+}
+
+// normalizePair accepts BTC-USD, BTC/USD, btc_usd and BTCUSD-style keys
+// with a separator
+func normalizePair(pair string) string {
+	pair = strings.ToUpper(strings.TrimSpace(pair))
+	return strings.NewReplacer("/", "-", "_", "-", ":", "-").Replace(pair)
+}
+
+func firstPositive(values ...float64) float64 {
+	for _, v := range values {
+		if v > 0 {
+			return v
+		}
+	}
+	return 0
+}
+
+// fileProvider reads a static rates file. Without as_of the file's
+// modification time is the rate timestamp.
+type fileProvider struct{ path string }
+
+func (f fileProvider) String() string { return "static " + f.path }
+
+func (f fileProvider) Load() (*Table, error) {
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return nil, fmt.Errorf("reading rates file: %w", err)
+	}
+	var mtime time.Time
+	if info, err := os.Stat(f.path); err == nil {
+		mtime = info.ModTime().UTC()
+	}
+	return decode(data, f.String(), mtime)
+}
+
+// gatewayProvider uses the rates the gateway prices L402 routes with
+type gatewayProvider struct {
+	get Getter
+	now Clock
+}
+
+func (g gatewayProvider) String() string { return "gateway" }
+
+func (g gatewayProvider) Load() (*Table, error) {
+	if g.get == nil {
+		return nil, fmt.Errorf("no gateway to fetch rates from; pass a rates file or URL with --rates")
+	}
+	data, code, err := g.get("/admin/rates")
+	if err != nil {
+		return nil, fmt.Errorf("fetching gateway rates: %w", err)
+	}
+	if code != 200 {
+		return nil, fmt.Errorf("fetching gateway rates: API returned HTTP %d (configure a rates file or URL with --rates)", code)
+	}
+	return decode(data, "gateway", g.now().UTC())
+}
+
+// httpProvider fetches a price endpoint returning the rates document
+type httpProvider struct {
+	url string
+	now Clock
+}
+
+func (h httpProvider) String() string { return h.url }
+
+func (h httpProvider) Load() (*Table, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get(h.url)
+	if err != nil {
+		return nil, fmt.Errorf("fetching rates: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("fetching rates: %w", err)
+	}
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("fetching rates from %s: HTTP %d", h.url, resp.StatusCode)
+	}
+	if !json.Valid(data) {
+		return nil, fmt.Errorf("rates from %s are not JSON", h.url)
+	}
+	return decode(data, h.url, h.now().UTC())
+}
@@ -0,0 +1,220 @@
+package rates
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var fixedNow = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+func fixedClock() time.Time { return fixedNow }
+
+func TestRate(t *testing.T) {
+	table := &Table{Source: "test", Rates: map[string]float64{
+		"BTC-USD": 65000,
+		"EUR-USD": 1.08,
+		"GBP-EUR": 1.2,
+		"JPY-BTC": 1e-7,
+	}}
+	for _, tc := range []struct {
+		from, to string
+		want     float64
+	}{
+		{"BTC", "USD", 65000},                // direct
+		{"usd", "btc", 1.0 / 65000},          // inverted
+		{"SAT", "USD", 0.00065},              // sub-unit priced through BTC
+		{"USD", "MSAT", 1e11 / 65000},        // inverted sub-unit
+		{"SAT", "MSAT", 1000},                // same base
+		{"EUR", "EUR", 1},                    // identity
+		{"", "USD", 1},                       // blank is USD
+		{"BTC", "EUR", 65000 / 1.08},         // crossed through USD
+		{"GBP", "USD", 1.2 * 1.08},           // crossed through EUR
+		{"JPY", "SAT", 10},                   // crossed through BTC
+		{"JPY", "USD", 1e-7 * 65000},         // direct BTC leg, then USD
+		{"SATS", "EUR", 1e-8 * 65000 / 1.08}, // sub-unit crossed
+		{"EUR", "SAT", 1.08 / 65000 * 1e8},   // crossed into a sub-unit
+	} {
+		q, err := table.Rate(tc.from, tc.to)
+		if err != nil {
+			t.Errorf("Rate(%s, %s): %v", tc.from, tc.to, err)
+			continue
+		}
+		if math.Abs(q.Rate-tc.want) > 1e-9*math.Max(1, tc.want) {
+			t.Errorf("Rate(%s, %s) = %v; want %v", tc.from, tc.to, q.Rate, tc.want)
+		}
+		if q.From != strings.ToUpper(tc.from) || q.To != strings.ToUpper(tc.to) || q.Source != "test" {
+			t.Errorf("Rate(%s, %s) quote = %+v", tc.from, tc.to, q)
+		}
+	}
+}
+
+func TestRateMissing(t *testing.T) {
+	table := &Table{Source: "test", Rates: map[string]float64{"BTC-USD": 65000, "EUR-USD": 1.08, "GBP-EUR": 1.2, "CHF-JPY": 170, "XAU-USD": 0}}
+	// Only one pivot is tried per conversion, so GBP-EUR-USD-BTC is too far
+	for _, pair := range [][2]string{{"CHF", "USD"}, {"SAT", "CHF"}, {"XAU", "USD"}, {"GBP", "SAT"}} {
+		if _, err := table.Rate(pair[0], pair[1]); err == nil || !strings.Contains(err.Error(), "in test") {
+			t.Errorf("Rate(%s, %s) = %v; want a missing-rate error", pair[0], pair[1], err)
+		}
+	}
+}
+
+func TestNormalizePair(t *testing.T) {
+	for in, want := range map[string]string{
+		"BTC-USD":   "BTC-USD",
+		"btc/usd":   "BTC-USD",
+		" eur_usd ": "EUR-USD",
+		"sat:eur":   "SAT-EUR",
+	} {
+		if got := normalizePair(in); got != want {
+			t.Errorf("normalizePair(%q) = %q; want %q", in, got, want)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	fallback := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		name, doc string
+		rates     map[string]float64
+		source    string
+		asOf      time.Time
+	}{
+		{"yaml", "rates:\n  btc/usd: 65000\n  EUR_USD: 1.08\n",
+			map[string]float64{"BTC-USD": 65000, "EUR-USD": 1.08}, "src", fallback},
+		{"json with source and as_of", `{"source": "treasury", "as_of": "2026-10-19T09:00:00Z", "rates": {"BTC-USD": 65000}}`,
+			map[string]float64{"BTC-USD": 65000}, "treasury via src", time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)},
+		{"single pair quote", `{"pair": "BTC/EUR", "price": 60000, "timestamp": "2026-10-19T10:00:00Z"}`,
+			map[string]float64{"BTC-EUR": 60000}, "src", time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)},
+		{"from and to quote", `{"from": "btc", "to": "usd", "rate": 64000, "updated_at": "not a time"}`,
+			map[string]float64{"BTC-USD": 64000}, "src", fallback},
+	} {
+		table, err := decode([]byte(tc.doc), "src", fallback)
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if fmt.Sprint(table.Rates) != fmt.Sprint(tc.rates) || table.Source != tc.source || !table.AsOf.Equal(tc.asOf) {
+			t.Errorf("%s: got %v %q %v; want %v %q %v", tc.name, table.Rates, table.Source, table.AsOf, tc.rates, tc.source, tc.asOf)
+		}
+	}
+
+	for _, doc := range []string{"rates: {}", `{"pair": "BTC-USD"}`, "rates: [1, 2"} {
+		if _, err := decode([]byte(doc), "src", fallback); err == nil {
+			t.Errorf("decode(%q) succeeded", doc)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	get := func(string) ([]byte, int, error) { return []byte(`{"rates": {"BTC-USD": 65000}}`), 200, nil }
+
+	for spec, want := range map[string]string{
+		"":                  "gateway",
+		"gateway":           "gateway",
+		"static":            "static " + DefaultPath(),
+		"file:/tmp/r.yaml":  "static /tmp/r.yaml",
+		"https://fx.test/q": "https://fx.test/q",
+	} {
+		p, err := Parse(spec, get, fixedClock)
+		if err != nil || p.String() != want {
+			t.Errorf("Parse(%q) = %v, %v; want %s", spec, p, err, want)
+		}
+	}
+	if _, err := Parse("coinbase", get, fixedClock); err == nil {
+		t.Error("Parse accepted an unknown source")
+	}
+
+	// The default rates file wins over the gateway once it exists
+	if err := os.MkdirAll(filepath.Dir(DefaultPath()), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(DefaultPath(), []byte("rates: {BTC-USD: 60000}\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if p, _ := Parse("", get, fixedClock); p.String() != "static "+DefaultPath() {
+		t.Errorf("Parse with a rates file = %v", p)
+	}
+}
+
+func TestGatewayProvider(t *testing.T) {
+	var asked []string
+	get := func(path string) ([]byte, int, error) {
+		asked = append(asked, path)
+		return []byte(`{"rates": {"BTC-USD": 65000}}`), 200, nil
+	}
+	p, _ := Parse("gateway", get, fixedClock)
+	table, err := p.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !table.AsOf.Equal(fixedNow) || table.Rates["BTC-USD"] != 65000 || strings.Join(asked, ",") != "/admin/rates" {
+		t.Errorf("table = %+v after asking %v", table, asked)
+	}
+
+	p, _ = Parse("gateway", func(string) ([]byte, int, error) { return nil, 404, nil }, fixedClock)
+	if _, err := p.Load(); err == nil || !strings.Contains(err.Error(), "HTTP 404") {
+		t.Errorf("Load from a gateway without rates: %v", err)
+	}
+
+	// No gateway to ask, as on the cloud surface
+	p, _ = Parse("", nil, fixedClock)
+	if _, err := p.Load(); err == nil || !strings.Contains(err.Error(), "--rates") {
+		t.Errorf("Load without a gateway: %v", err)
+	}
+}
+
+func TestHTTPProvider(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/quote":
+			fmt.Fprint(w, `{"pair": "BTC-USD", "price": 65000}`)
+		case "/yaml":
+			fmt.Fprint(w, "rates:\n  BTC-USD: 65000\n")
+		default:
+			http.Error(w, "not found", 404)
+		}
+	}))
+	defer srv.Close()
+
+	p, _ := Parse(srv.URL+"/quote", nil, fixedClock)
+	table, err := p.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !table.AsOf.Equal(fixedNow) || table.Source != srv.URL+"/quote" {
+		t.Errorf("table = %+v", table)
+	}
+	for path, want := range map[string]string{"/yaml": "not JSON", "/missing": "HTTP 404"} {
+		p, _ := Parse(srv.URL+path, nil, fixedClock)
+		if _, err := p.Load(); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Load %s = %v; want %q", path, err, want)
+		}
+	}
+}
+
+func TestFileProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.yaml")
+	if err := os.WriteFile(path, []byte("rates:\n  EUR-USD: 1.08\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	mtime := time.Date(2026, 10, 18, 8, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+	p, _ := Parse("file:"+path, nil, fixedClock)
+	table, err := p.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !table.AsOf.Equal(mtime) || table.Source != "static "+path {
+		t.Errorf("table = %+v; want the file's mtime", table)
+	}
+}
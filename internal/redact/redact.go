@@ -0,0 +1,134 @@
+// Package redact removes credentials and macaroons from HTTP headers and
+// bodies before they are written to a cassette or a debug log. Token IDs,
+// names and amounts are kept; they are what makes the output useful.
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// Placeholder replaces every credential
+const Placeholder = "[REDACTED]"
+
+// Redactor scrubs known credential values in addition to the patterns
+// every Redactor recognises
+type Redactor struct {
+	secrets []string
+}
+
+// New returns a Redactor for the given credential values; empty ones are
+// ignored
+func New(secrets ...string) *Redactor {
+	r := &Redactor{}
+	for _, s := range secrets {
+		if s != "" {
+			r.secrets = append(r.secrets, s)
+		}
+	}
+	return r
+}
+
+// secretHeaders are never shown, whatever their value
+var secretHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"X-Admin-Token":       true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+}
+
+// SecretHeader reports whether a header carries a credential
+func SecretHeader(name string) bool {
+	return secretHeaders[http.CanonicalHeaderKey(name)]
+}
+
+// Header returns the value to show for a header
+func (r *Redactor) Header(name, value string) string {
+	if SecretHeader(name) {
+		return Placeholder
+	}
+	return r.Text(value)
+}
+
+// JSON returns data with credential fields and values replaced. Data with
+// nothing to redact is returned as is, so number formats and key order
+// survive. It reports false when data is not JSON.
+func (r *Redactor) JSON(data []byte) ([]byte, bool) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil || dec.More() {
+		return nil, false
+	}
+	changed := false
+	v = r.value(v, "", &changed)
+	if !changed {
+		return data, true
+	}
+	out, _ := json.Marshal(v)
+	return out, true
+}
+
+// Body redacts a body as JSON when it is JSON and as text otherwise
+func (r *Redactor) Body(data []byte) string {
+	if out, ok := r.JSON(data); ok {
+		return string(out)
+	}
+	return r.Text(string(data))
+}
+
+func (r *Redactor) value(v interface{}, key string, changed *bool) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, child := range val {
+			val[k] = r.value(child, k, changed)
+		}
+		return val
+	case []interface{}:
+		for i := range val {
+			val[i] = r.value(val[i], key, changed)
+		}
+		return val
+	case string:
+		out := r.Text(val)
+		if secretKey(key) && !strings.HasPrefix(val, "tok_") {
+			out = Placeholder
+		}
+		if out != val {
+			*changed = true
+		}
+		return out
+	}
+	return v
+}
+
+// macaroonPattern matches base64 v2 macaroons, which start with 0x02 0x01
+var macaroonPattern = regexp.MustCompile(`AgE[A-Za-z0-9+/_-]{16,}={0,2}`)
+
+// Text removes the known credentials and macaroons from free text
+func (r *Redactor) Text(s string) string {
+	for _, secret := range r.secrets {
+		s = strings.ReplaceAll(s, secret, Placeholder)
+	}
+	return macaroonPattern.ReplaceAllString(s, Placeholder)
+}
+
+// secretKey reports whether a JSON key holds a credential
+func secretKey(key string) bool {
+	key = strings.ToLower(key)
+	switch key {
+	case "token", "access_token", "refresh_token", "id_token", "api_key", "apikey",
+		"admin_token", "bearer_token", "session_token", "authorization":
+		return true
+	}
+	for _, s := range []string{"macaroon", "secret", "password", "preimage", "session"} {
+		if strings.Contains(key, s) && !strings.HasSuffix(key, "_id") {
+			return true
+		}
+	}
+	return false
+}
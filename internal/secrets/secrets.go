@@ -0,0 +1,325 @@
+// Package secrets delivers a newly minted macaroon somewhere other than the
+// terminal: a 0600 file, a line in an env file, a Kubernetes Secret
+// manifest, a Vault-compatible KV write, or the stdin of a command. Once a
+// secret has been delivered the CLI does not print it.
+package secrets
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Secret is a minted credential and what it belongs to
+type Secret struct {
+	Value   string
+	TokenID string
+	Agent   string
+}
+
+// Options tune how sinks name what they write
+type Options struct {
+	// Key is the env var, Secret data key or Vault field name. Each sink
+	// has its own default when empty.
+	Key string
+	// Name is the Kubernetes Secret name (default satgate-<agent>)
+	Name string
+	// Force lets file and k8s sinks replace an existing file, and with it
+	// whatever secret it held
+	Force bool
+}
+
+// Sink is one --output-secret destination
+type Sink interface {
+	// Check reports a problem Deliver would hit, such as a file it must not
+	// replace, so it can be caught before a token is minted
+	Check() error
+	Deliver(s Secret) error
+	String() string
+}
+
+// Kinds lists the accepted sink prefixes, for help and error text
+const Kinds = "file:PATH, env:PATH, k8s:PATH, vault:PATH|URL, exec:COMMAND"
+
+// Parse turns "kind:target" into a Sink
+func Parse(spec string, opts Options) (Sink, error) {
+	kind, target, ok := strings.Cut(spec, ":")
+	target = strings.TrimSpace(target)
+	if !ok || target == "" {
+		return nil, fmt.Errorf("invalid secret destination %q (use %s)", spec, Kinds)
+	}
+	switch kind {
+	case "file":
+		return fileSink{path: target, force: opts.Force}, nil
+	case "env":
+		key := firstNonEmpty(opts.Key, "SATGATE_MACAROON")
+		if !envKeyPattern.MatchString(key) {
+			return nil, fmt.Errorf("invalid env var name %q", key)
+		}
+		return envSink{path: target, key: key}, nil
+	case "k8s":
+		return k8sSink{path: target, key: firstNonEmpty(opts.Key, "macaroon"), name: opts.Name, force: opts.Force}, nil
+	case "vault":
+		return newVaultSink(target, firstNonEmpty(opts.Key, "macaroon"))
+	case "exec":
+		return execSink{command: target}, nil
+	}
+	return nil, fmt.Errorf("unknown secret destination %q (use %s)", kind, Kinds)
+}
+
+// writePrivate replaces path with data at mode 0600. The data goes to a
+// temp file created 0600 in the same directory, which is then renamed over
+// path, so the secret is never readable under a looser mode and a reader
+// never sees a half-written file.
+func writePrivate(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if dir != "." {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return err
+		}
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// ErrExists is returned by file and k8s sinks whose file already exists
+// when Options.Force is not set
+var ErrExists = errors.New("already exists")
+
+// checkNew refuses to replace path unless force is set
+func checkNew(path string, force bool) error {
+	if _, err := os.Lstat(path); err == nil && !force {
+		return fmt.Errorf("%s %w", path, ErrExists)
+	}
+	return nil
+}
+
+type fileSink struct {
+	path  string
+	force bool
+}
+
+func (f fileSink) String() string { return "file:" + f.path }
+
+func (f fileSink) Check() error { return checkNew(f.path, f.force) }
+
+func (f fileSink) Deliver(s Secret) error {
+	if err := f.Check(); err != nil {
+		return err
+	}
+	return writePrivate(f.path, []byte(s.Value+"\n"))
+}
+
+var envKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// envSink sets KEY=value in a dotenv-style file, replacing an existing
+// assignment of the same key and keeping every other line
+type envSink struct{ path, key string }
+
+// envLocks serializes deliveries to the same env file, which several
+// tokens minted in parallel may share under different keys
+var envLocks sync.Map // absolute path → *sync.Mutex
+
+func lockFile(path string) func() {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	mu, _ := envLocks.LoadOrStore(path, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	return mu.(*sync.Mutex).Unlock
+}
+
+func (e envSink) String() string { return "env:" + e.path + " (" + e.key + ")" }
+
+func (e envSink) Check() error { return nil }
+
+func (e envSink) Deliver(s Secret) error {
+	line := e.key + "=" + envQuote(s.Value)
+	defer lockFile(e.path)()
+
+	existing, err := os.ReadFile(e.path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	var lines []string
+	replaced := false
+	if len(existing) > 0 {
+		for _, l := range strings.Split(strings.TrimRight(string(existing), "\n"), "\n") {
+			trimmed := strings.TrimPrefix(strings.TrimSpace(l), "export ")
+			if strings.HasPrefix(trimmed, e.key+"=") {
+				if !replaced {
+					lines = append(lines, line)
+					replaced = true
+				}
+				continue
+			}
+			lines = append(lines, l)
+		}
+	}
+	if !replaced {
+		lines = append(lines, line)
+	}
+	return writePrivate(e.path, []byte(strings.Join(lines, "\n")+"\n"))
+}
+
+// envQuote double-quotes values a dotenv parser would otherwise split
+func envQuote(v string) string {
+	if !strings.ContainsAny(v, " \t\"'#$\\`\n") {
+		return v
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`", "\n", `\n`)
+	return `"` + r.Replace(v) + `"`
+}
+
+// k8sSink writes a Secret manifest to disk for kubectl apply
+type k8sSink struct {
+	path, key, name string
+	force           bool
+}
+
+func (k k8sSink) String() string { return "k8s:" + k.path }
+
+func (k k8sSink) Check() error { return checkNew(k.path, k.force) }
+
+var dnsLabelInvalid = regexp.MustCompile(`[^a-z0-9-]+`)
+
+func (k k8sSink) Deliver(s Secret) error {
+	if err := k.Check(); err != nil {
+		return err
+	}
+	name := k.name
+	if name == "" {
+		name = "satgate-" + strings.Trim(dnsLabelInvalid.ReplaceAllString(strings.ToLower(s.Agent), "-"), "-")
+	}
+	meta := map[string]interface{}{
+		"name":   strings.TrimRight(name, "-"),
+		"labels": map[string]string{"app.kubernetes.io/managed-by": "satgate-cli"},
+	}
+	if s.TokenID != "" {
+		meta["annotations"] = map[string]string{"satgate.io/token-id": s.TokenID}
+	}
+	manifest := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata":   meta,
+		"type":       "Opaque",
+		"stringData": map[string]string{k.key: s.Value},
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(manifest); err != nil {
+		return err
+	}
+	return writePrivate(k.path, buf.Bytes())
+}
+
+// vaultSink writes {key: secret} to a KV engine. Paths containing /data/
+// are treated as KV v2 and the fields are wrapped in "data".
+type vaultSink struct {
+	url, key string
+	http     *http.Client
+}
+
+func newVaultSink(target, key string) (Sink, error) {
+	url := target
+	if !strings.HasPrefix(target, "http://") && !strings.HasPrefix(target, "https://") {
+		addr := os.Getenv("VAULT_ADDR")
+		if addr == "" {
+			return nil, fmt.Errorf("vault:%s needs VAULT_ADDR, or give a full URL", target)
+		}
+		url = strings.TrimRight(addr, "/") + "/v1/" + strings.TrimPrefix(strings.TrimPrefix(target, "/"), "v1/")
+	}
+	if os.Getenv("VAULT_TOKEN") == "" {
+		return nil, fmt.Errorf("vault destination needs VAULT_TOKEN")
+	}
+	return vaultSink{url: url, key: key, http: &http.Client{Timeout: 30 * time.Second}}, nil
+}
+
+func (v vaultSink) String() string { return "vault:" + v.url }
+
+func (v vaultSink) Check() error { return nil }
+
+func (v vaultSink) Deliver(s Secret) error {
+	fields := map[string]string{v.key: s.Value}
+	if s.TokenID != "" {
+		fields["token_id"] = s.TokenID
+	}
+	var body interface{} = fields
+	if strings.Contains(v.url, "/data/") {
+		body = map[string]interface{}{"data": fields}
+	}
+	data, _ := json.Marshal(body)
+
+	req, err := http.NewRequest("POST", v.url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Vault-Token", os.Getenv("VAULT_TOKEN"))
+	if ns := os.Getenv("VAULT_NAMESPACE"); ns != "" {
+		req.Header.Set("X-Vault-Namespace", ns)
+	}
+	resp, err := v.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 && resp.StatusCode != 204 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("vault returned HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// execSink runs a shell command with the secret on stdin. Its output goes
+// to stderr so stdout stays clean for --json.
+type execSink struct{ command string }
+
+func (e execSink) String() string { return "exec:" + e.command }
+
+func (e execSink) Check() error { return nil }
+
+func (e execSink) Deliver(s Secret) error {
+	cmd := exec.Command("sh", "-c", e.command)
+	cmd.Stdin = strings.NewReader(s.Value + "\n")
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), "SATGATE_TOKEN_ID="+s.TokenID, "SATGATE_AGENT="+s.Agent)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %w", e.command, err)
+	}
+	return nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
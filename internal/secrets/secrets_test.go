@@ -0,0 +1,246 @@
+package secrets
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestWritePrivateReplacesLooseFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "macaroon")
+	if err := os.WriteFile(path, []byte("old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := writePrivate(path, []byte("AgEsecret\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("mode = %o; want 600", mode)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "AgEsecret\n" {
+		t.Errorf("content = %q", data)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("temp file left behind: %v", entries)
+	}
+}
+
+var testSecret = Secret{Value: "AgEsecret", TokenID: "tok_abc123", Agent: "CS Bot"}
+
+func parse(t *testing.T, spec string, opts Options) Sink {
+	t.Helper()
+	sink, err := Parse(spec, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sink
+}
+
+func TestFileSinkRefusesExisting(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets", "cs-bot.macaroon")
+	sink := parse(t, "file:"+path, Options{})
+	if err := sink.Check(); err != nil {
+		t.Fatalf("Check before first write: %v", err)
+	}
+	if err := sink.Deliver(testSecret); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "AgEsecret\n" {
+		t.Errorf("content = %q", data)
+	}
+
+	if err := sink.Check(); !errors.Is(err, ErrExists) {
+		t.Errorf("Check of an existing file = %v; want ErrExists", err)
+	}
+	if err := sink.Deliver(Secret{Value: "AgEother"}); !errors.Is(err, ErrExists) {
+		t.Errorf("Deliver over an existing file = %v; want ErrExists", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "AgEsecret\n" {
+		t.Errorf("refused write changed the file: %q", data)
+	}
+
+	forced := parse(t, "file:"+path, Options{Force: true})
+	if err := forced.Deliver(Secret{Value: "AgEother"}); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "AgEother\n" {
+		t.Errorf("forced content = %q", data)
+	}
+}
+
+func TestEnvSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("# proxy\nOPENAI_KEY=sk-1\nexport PROXY_TOKEN=old\nPROXY_TOKEN=older\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Replaces every assignment of the key with one line where the first was
+	if err := parse(t, "env:"+path, Options{Key: "PROXY_TOKEN"}).Deliver(testSecret); err != nil {
+		t.Fatal(err)
+	}
+	want := "# proxy\nOPENAI_KEY=sk-1\nPROXY_TOKEN=AgEsecret\n"
+	if data, _ := os.ReadFile(path); string(data) != want {
+		t.Errorf("replaced:\n%s\nwant:\n%s", data, want)
+	}
+
+	// Appends a key the file does not have, quoting what a parser would split
+	if err := parse(t, "env:"+path, Options{}).Deliver(Secret{Value: `a b"$c`}); err != nil {
+		t.Fatal(err)
+	}
+	want += `SATGATE_MACAROON="a b\"\$c"` + "\n"
+	if data, _ := os.ReadFile(path); string(data) != want {
+		t.Errorf("appended:\n%s\nwant:\n%s", data, want)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("mode = %o; want 600", info.Mode().Perm())
+	}
+
+	if _, err := Parse("env:"+path, Options{Key: "BAD-KEY"}); err == nil {
+		t.Error("Parse accepted an invalid env var name")
+	}
+}
+
+func TestK8sSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cs-bot.yaml")
+	sink := parse(t, "k8s:"+path, Options{})
+	if err := sink.Deliver(testSecret); err != nil {
+		t.Fatal(err)
+	}
+	want := `apiVersion: v1
+kind: Secret
+metadata:
+  annotations:
+    satgate.io/token-id: tok_abc123
+  labels:
+    app.kubernetes.io/managed-by: satgate-cli
+  name: satgate-cs-bot
+stringData:
+  macaroon: AgEsecret
+type: Opaque
+`
+	if data, _ := os.ReadFile(path); string(data) != want {
+		t.Errorf("manifest:\n%s\nwant:\n%s", data, want)
+	}
+	if err := sink.Deliver(testSecret); !errors.Is(err, ErrExists) {
+		t.Errorf("second Deliver = %v; want ErrExists", err)
+	}
+
+	named := parse(t, "k8s:"+path, Options{Key: "token", Name: "proxy-creds", Force: true})
+	if err := named.Deliver(testSecret); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "name: proxy-creds\n") || !strings.Contains(string(data), "  token: AgEsecret\n") {
+		t.Errorf("manifest with --secret-name and --secret-key:\n%s", data)
+	}
+}
+
+func TestVaultSink(t *testing.T) {
+	type request struct {
+		path, token, namespace string
+		body                   map[string]interface{}
+	}
+	var got []request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := request{path: r.URL.Path, token: r.Header.Get("X-Vault-Token"), namespace: r.Header.Get("X-Vault-Namespace")}
+		json.NewDecoder(r.Body).Decode(&req.body)
+		got = append(got, req)
+		if strings.Contains(r.URL.Path, "denied") {
+			http.Error(w, `{"errors":["permission denied"]}`, 403)
+			return
+		}
+		w.WriteHeader(204)
+	}))
+	defer srv.Close()
+
+	t.Setenv("VAULT_ADDR", "")
+	t.Setenv("VAULT_TOKEN", "")
+	if _, err := Parse("vault:secret/satgate", Options{}); err == nil || !strings.Contains(err.Error(), "VAULT_ADDR") {
+		t.Errorf("Parse without VAULT_ADDR: %v", err)
+	}
+	t.Setenv("VAULT_ADDR", srv.URL+"/")
+	if _, err := Parse("vault:secret/satgate", Options{}); err == nil || !strings.Contains(err.Error(), "VAULT_TOKEN") {
+		t.Errorf("Parse without VAULT_TOKEN: %v", err)
+	}
+	t.Setenv("VAULT_TOKEN", "hvs.test")
+	t.Setenv("VAULT_NAMESPACE", "team-a")
+
+	// KV v1 takes the fields as the body; KV v2 (a /data/ path) wraps them
+	if err := parse(t, "vault:secret/satgate/cs-bot", Options{}).Deliver(testSecret); err != nil {
+		t.Fatal(err)
+	}
+	if err := parse(t, "vault:"+srv.URL+"/v1/kv/data/cs-bot", Options{Key: "token"}).Deliver(testSecret); err != nil {
+		t.Fatal(err)
+	}
+	err := parse(t, "vault:/v1/secret/denied", Options{}).Deliver(testSecret)
+	if err == nil || !strings.Contains(err.Error(), "HTTP 403") || !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("Deliver to a denied path: %v", err)
+	}
+
+	want := []request{
+		{"/v1/secret/satgate/cs-bot", "hvs.test", "team-a", map[string]interface{}{"macaroon": "AgEsecret", "token_id": "tok_abc123"}},
+		{"/v1/kv/data/cs-bot", "hvs.test", "team-a", map[string]interface{}{"data": map[string]interface{}{"token": "AgEsecret", "token_id": "tok_abc123"}}},
+		{"/v1/secret/denied", "hvs.test", "team-a", map[string]interface{}{"macaroon": "AgEsecret", "token_id": "tok_abc123"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("requests:\n%+v\nwant:\n%+v", got, want)
+	}
+}
+
+func TestExecSink(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out")
+	sink := parse(t, `exec:cat > `+out+` && echo "$SATGATE_TOKEN_ID $SATGATE_AGENT" >> `+out, Options{})
+	if err := sink.Deliver(testSecret); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(out); string(data) != "AgEsecret\ntok_abc123 CS Bot\n" {
+		t.Errorf("command saw %q", data)
+	}
+
+	err := parse(t, "exec:exit 3", Options{}).Deliver(testSecret)
+	if err == nil || !strings.Contains(err.Error(), "exit status 3") {
+		t.Errorf("failing command: %v", err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, spec := range []string{"file:", "file", "s3:bucket/key", ":x"} {
+		if _, err := Parse(spec, Options{}); err == nil {
+			t.Errorf("Parse(%q) succeeded", spec)
+		}
+	}
+}
+
+func TestEnvSinkConcurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			key := fmt.Sprintf("TOKEN_%02d", i)
+			if err := parse(t, "env:"+path, Options{Key: key}).Deliver(Secret{Value: "v" + key}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	data, _ := os.ReadFile(path)
+	if lines := strings.Split(strings.TrimSpace(string(data)), "\n"); len(lines) != 20 {
+		t.Errorf("%d of 20 keys survived concurrent writes:\n%s", len(lines), data)
+	}
+}
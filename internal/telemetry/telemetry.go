@@ -0,0 +1,411 @@
+// Package telemetry records OpenTelemetry spans and request metrics for CLI
+// API calls and exports them as OTLP/HTTP JSON, either to a collector or to a
+// local file. It is a no-op unless Init is called with tracing enabled.
+package telemetry
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Span kinds and status codes as defined by the OTLP protocol
+const (
+	KindInternal = 1
+	KindClient   = 3
+
+	StatusUnset = 0
+	StatusOK    = 1
+	StatusError = 2
+)
+
+// Options configures where telemetry is exported
+type Options struct {
+	Endpoint       string // OTLP/HTTP base URL, e.g. http://localhost:4318
+	File           string // append OTLP JSON lines to this file instead
+	ServiceVersion string
+	RootName       string // name of the span covering the whole invocation
+}
+
+// Span is a single timed operation
+type Span struct {
+	traceID  string
+	spanID   string
+	parentID string
+	name     string
+	kind     int
+	start    time.Time
+	end      time.Time
+	attrs    map[string]interface{}
+	status   int
+	message  string
+	tracer   *tracer
+}
+
+type tracer struct {
+	opts    Options
+	root    *Span
+	mu      sync.Mutex
+	spans   []*Span
+	reqs    map[string]*histogram
+	flushed time.Time // start of the current metrics interval
+}
+
+// current is the active tracer; spans are started from concurrent
+// goroutines (bulk minting), so it is only read and written under currentMu
+var (
+	currentMu sync.Mutex
+	current   *tracer
+)
+
+func active() *tracer {
+	currentMu.Lock()
+	defer currentMu.Unlock()
+	return current
+}
+
+// Init enables telemetry for this process and starts the root span
+func Init(opts Options) {
+	t := &tracer{opts: opts, reqs: map[string]*histogram{}}
+	now := time.Now()
+	t.flushed = now
+	t.root = &Span{
+		traceID: randomHex(16),
+		spanID:  randomHex(8),
+		name:    opts.RootName,
+		kind:    KindInternal,
+		start:   now,
+		attrs:   map[string]interface{}{},
+		tracer:  t,
+	}
+	currentMu.Lock()
+	current = t
+	currentMu.Unlock()
+}
+
+// Enabled reports whether Init has been called
+func Enabled() bool {
+	return active() != nil
+}
+
+// StartSpan starts a client span under the invocation's root span.
+// It returns nil when telemetry is disabled; all Span methods accept nil.
+func StartSpan(name string) *Span {
+	t := active()
+	if t == nil {
+		return nil
+	}
+	return &Span{
+		traceID:  t.root.traceID,
+		spanID:   randomHex(8),
+		parentID: t.root.spanID,
+		name:     name,
+		kind:     KindClient,
+		start:    time.Now(),
+		attrs:    map[string]interface{}{},
+		tracer:   t,
+	}
+}
+
+// SetAttr sets a span attribute
+func (s *Span) SetAttr(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	s.attrs[key] = value
+}
+
+// SetError marks the span as failed
+func (s *Span) SetError(msg string) {
+	if s == nil {
+		return
+	}
+	s.status = StatusError
+	s.message = msg
+}
+
+// Traceparent returns the W3C trace context header value for this span
+func (s *Span) Traceparent() string {
+	if s == nil {
+		return ""
+	}
+	return fmt.Sprintf("00-%s-%s-01", s.traceID, s.spanID)
+}
+
+// End finishes the span and records it for export
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.end = time.Now()
+	if s.status == StatusUnset {
+		s.status = StatusOK
+	}
+	s.tracer.mu.Lock()
+	s.tracer.spans = append(s.tracer.spans, s)
+	s.tracer.mu.Unlock()
+}
+
+// RecordRequest adds one API call to the request duration histogram
+func RecordRequest(method, path string, status int, surface string, d time.Duration) {
+	t := active()
+	if t == nil {
+		return
+	}
+	attrs := map[string]interface{}{
+		"http.request.method":       method,
+		"url.template":              path,
+		"http.response.status_code": status,
+		"satgate.surface":           surface,
+	}
+	key := fmt.Sprintf("%s %s %d %s", method, path, status, surface)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	h, ok := t.reqs[key]
+	if !ok {
+		h = newHistogram(attrs)
+		t.reqs[key] = h
+	}
+	h.observe(d.Seconds())
+}
+
+// Shutdown ends the root span and exports everything recorded. err, if
+// non-nil, marks the root span as failed.
+func Shutdown(err error) error {
+	currentMu.Lock()
+	t := current
+	current = nil
+	currentMu.Unlock()
+	if t == nil {
+		return nil
+	}
+	if err != nil {
+		t.root.SetError(err.Error())
+	}
+	t.root.End()
+
+	t.mu.Lock()
+	traces, metrics := t.traceRequest(), t.metricsRequest()
+	t.mu.Unlock()
+	return t.export(traces, metrics)
+}
+
+// Flush exports the spans and requests recorded so far and forgets them,
+// leaving the root span open. Long-running commands call it so their
+// telemetry does not wait for, or pile up until, the process exits.
+func Flush() error {
+	t := active()
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	if len(t.spans) == 0 && len(t.reqs) == 0 {
+		t.mu.Unlock()
+		return nil
+	}
+	traces, metrics := t.traceRequest(), t.metricsRequest()
+	t.spans = nil
+	t.reqs = map[string]*histogram{}
+	t.flushed = time.Now()
+	t.mu.Unlock()
+	return t.export(traces, metrics)
+}
+
+func (t *tracer) export(traces, metrics interface{}) error {
+	if t.opts.File != "" {
+		return appendJSONLines(t.opts.File, traces, metrics)
+	}
+	endpoint := strings.TrimRight(t.opts.Endpoint, "/")
+	if err := post(endpoint+"/v1/traces", traces); err != nil {
+		return err
+	}
+	return post(endpoint+"/v1/metrics", metrics)
+}
+
+// --- OTLP/JSON encoding ---
+
+func (t *tracer) resource() map[string]interface{} {
+	return map[string]interface{}{
+		"attributes": encodeAttrs(map[string]interface{}{
+			"service.name":    "satgate-cli",
+			"service.version": t.opts.ServiceVersion,
+		}),
+	}
+}
+
+func (t *tracer) scope() map[string]interface{} {
+	return map[string]interface{}{
+		"name":    "github.com/SatGate-io/satgate-cli",
+		"version": t.opts.ServiceVersion,
+	}
+}
+
+func (t *tracer) traceRequest() map[string]interface{} {
+	spans := make([]map[string]interface{}, 0, len(t.spans))
+	for _, s := range t.spans {
+		span := map[string]interface{}{
+			"traceId":           s.traceID,
+			"spanId":            s.spanID,
+			"name":              s.name,
+			"kind":              s.kind,
+			"startTimeUnixNano": fmt.Sprint(s.start.UnixNano()),
+			"endTimeUnixNano":   fmt.Sprint(s.end.UnixNano()),
+			"attributes":        encodeAttrs(s.attrs),
+			"status":            map[string]interface{}{"code": s.status, "message": s.message},
+		}
+		if s.parentID != "" {
+			span["parentSpanId"] = s.parentID
+		}
+		spans = append(spans, span)
+	}
+	return map[string]interface{}{
+		"resourceSpans": []interface{}{map[string]interface{}{
+			"resource": t.resource(),
+			"scopeSpans": []interface{}{map[string]interface{}{
+				"scope": t.scope(),
+				"spans": spans,
+			}},
+		}},
+	}
+}
+
+func (t *tracer) metricsRequest() map[string]interface{} {
+	keys := make([]string, 0, len(t.reqs))
+	for k := range t.reqs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	now := fmt.Sprint(time.Now().UnixNano())
+	start := fmt.Sprint(t.flushed.UnixNano())
+	points := make([]map[string]interface{}, 0, len(keys))
+	for _, k := range keys {
+		h := t.reqs[k]
+		counts := make([]string, len(h.counts))
+		for i, c := range h.counts {
+			counts[i] = fmt.Sprint(c)
+		}
+		points = append(points, map[string]interface{}{
+			"attributes":        encodeAttrs(h.attrs),
+			"startTimeUnixNano": start,
+			"timeUnixNano":      now,
+			"count":             fmt.Sprint(h.count),
+			"sum":               h.sum,
+			"bucketCounts":      counts,
+			"explicitBounds":    durationBounds,
+		})
+	}
+	return map[string]interface{}{
+		"resourceMetrics": []interface{}{map[string]interface{}{
+			"resource": t.resource(),
+			"scopeMetrics": []interface{}{map[string]interface{}{
+				"scope": t.scope(),
+				"metrics": []interface{}{map[string]interface{}{
+					"name":        "satgate.cli.request.duration",
+					"description": "Duration of SatGate API calls made by the CLI",
+					"unit":        "s",
+					"histogram": map[string]interface{}{
+						"aggregationTemporality": 1, // delta: each invocation reports its own calls
+						"dataPoints":             points,
+					},
+				}},
+			}},
+		}},
+	}
+}
+
+func encodeAttrs(attrs map[string]interface{}) []interface{} {
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	out := make([]interface{}, 0, len(keys))
+	for _, k := range keys {
+		var v map[string]interface{}
+		switch val := attrs[k].(type) {
+		case int:
+			v = map[string]interface{}{"intValue": fmt.Sprint(val)}
+		case int64:
+			v = map[string]interface{}{"intValue": fmt.Sprint(val)}
+		case float64:
+			v = map[string]interface{}{"doubleValue": val}
+		case bool:
+			v = map[string]interface{}{"boolValue": val}
+		default:
+			v = map[string]interface{}{"stringValue": fmt.Sprint(val)}
+		}
+		out = append(out, map[string]interface{}{"key": k, "value": v})
+	}
+	return out
+}
+
+// durationBounds are the OTel semantic-convention buckets for HTTP durations
+var durationBounds = []float64{0.005, 0.01, 0.025, 0.05, 0.075, 0.1, 0.25, 0.5, 0.75, 1, 2.5, 5, 7.5, 10}
+
+type histogram struct {
+	attrs  map[string]interface{}
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+func newHistogram(attrs map[string]interface{}) *histogram {
+	return &histogram{attrs: attrs, counts: make([]uint64, len(durationBounds)+1)}
+}
+
+func (h *histogram) observe(v float64) {
+	i := sort.SearchFloat64s(durationBounds, v)
+	h.counts[i]++
+	h.count++
+	h.sum += v
+}
+
+// --- exporters ---
+
+func post(url string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("exporting telemetry: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("exporting telemetry: %s returned HTTP %d", url, resp.StatusCode)
+	}
+	return nil
+}
+
+func appendJSONLines(path string, payloads ...interface{}) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("opening trace file: %w", err)
+	}
+	defer f.Close()
+	enc := json.NewEncoder(f)
+	for _, p := range payloads {
+		if err := enc.Encode(p); err != nil {
+			return fmt.Errorf("writing trace file: %w", err)
+		}
+	}
+	return nil
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,214 @@
+// Package templates holds named mint templates: the budget, expiry, routes,
+// labels and parent a team mints the same shape of token with. Templates
+// come from the templates section of the config file and from YAML files in
+// a templates directory (default ~/.satgate/templates), one per template.
+// String fields may reference variables, e.g. {{.Agent}} or {{.Team}}.
+package templates
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Template is a set of mint defaults. Empty fields leave the mint's own
+// flags (or their defaults) in effect.
+type Template struct {
+	Description string            `yaml:"description,omitempty"`
+	Name        string            `yaml:"name,omitempty"`   // token name; defaults to --agent
+	Budget      string            `yaml:"budget,omitempty"` // e.g. 50, 0.29, 1500sats
+	Currency    string            `yaml:"currency,omitempty"`
+	Expiry      string            `yaml:"expiry,omitempty"`
+	Routes      []string          `yaml:"routes,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+	Parent      string            `yaml:"parent,omitempty"`
+
+	// Source is where the template was loaded from: "config" or a file path
+	Source string `yaml:"-"`
+}
+
+// DefaultDir returns ~/.satgate/templates
+func DefaultDir() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".satgate", "templates")
+}
+
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+// ValidName reports whether name is usable as a template (and file) name
+func ValidName(name string) bool {
+	return namePattern.MatchString(name)
+}
+
+// Load returns every template, keyed by name. Templates from dir override
+// those of the same name in the config file. A missing dir is not an error.
+func Load(fromConfig map[string]Template, dir string) (map[string]Template, error) {
+	all := map[string]Template{}
+	for name, t := range fromConfig {
+		t.Source = "config"
+		all[name] = t
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.y*ml"))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range files {
+		ext := filepath.Ext(path)
+		if ext != ".yaml" && ext != ".yml" {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading template: %w", err)
+		}
+		var t Template
+		if err := yaml.Unmarshal(data, &t); err != nil {
+			return nil, fmt.Errorf("parsing template %s: %w", path, err)
+		}
+		t.Source = path
+		all[strings.TrimSuffix(filepath.Base(path), ext)] = t
+	}
+	return all, nil
+}
+
+// Names returns template names in sorted order
+func Names(all map[string]Template) []string {
+	names := make([]string, 0, len(all))
+	for name := range all {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Save writes t to dir/<name>.yaml, refusing to replace an existing file
+// unless overwrite is set, and returns the path written
+func Save(dir, name string, t Template, overwrite bool) (string, error) {
+	if !ValidName(name) {
+		return "", fmt.Errorf("invalid template name %q (lowercase letters, digits, '.', '_', '-')", name)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("creating templates dir: %w", err)
+	}
+	path := filepath.Join(dir, name+".yaml")
+	if _, err := os.Stat(path); err == nil && !overwrite {
+		return "", fmt.Errorf("template %s already exists at %s", name, path)
+	}
+	data, err := yaml.Marshal(t)
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return "", fmt.Errorf("writing template: %w", err)
+	}
+	return path, nil
+}
+
+var varPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ValidVar reports whether key can be referenced from a template: letters,
+// digits and '_', not starting with a digit. team-name cannot, since
+// {{.Team-name}} does not parse.
+func ValidVar(key string) bool {
+	return varPattern.MatchString(key)
+}
+
+// Vars builds the substitution data: Agent (when given) plus each
+// key=value, with the key's first letter upper-cased so team=support is
+// available as {{.Team}}. Keys that are not ValidVar, such as a
+// cost-center label, are left out.
+func Vars(agent string, pairs map[string]string) map[string]string {
+	vars := map[string]string{}
+	if agent != "" {
+		vars["Agent"] = agent
+	}
+	for k, v := range pairs {
+		if !ValidVar(k) {
+			continue
+		}
+		r := []rune(k)
+		r[0] = unicode.ToUpper(r[0])
+		vars[string(r)] = v
+	}
+	return vars
+}
+
+// Validate parses every string field, so syntax errors surface when a
+// template is created rather than when it is used
+func (t Template) Validate() error {
+	fields := map[string]string{"name": t.Name, "description": t.Description, "budget": t.Budget, "currency": t.Currency, "expiry": t.Expiry, "parent": t.Parent}
+	for i, r := range t.Routes {
+		fields[fmt.Sprintf("routes[%d]", i)] = r
+	}
+	for k, v := range t.Labels {
+		fields["labels."+k] = v
+	}
+	for field, s := range fields {
+		if _, err := template.New(field).Parse(s); err != nil {
+			return fmt.Errorf("template field %s: %w", field, err)
+		}
+	}
+	return nil
+}
+
+// Render substitutes vars into every string field. Referencing a variable
+// that was not supplied is an error rather than an empty string.
+func (t Template) Render(vars map[string]string) (Template, error) {
+	out := t
+	var err error
+	render := func(field, s string) string {
+		if err != nil {
+			return s
+		}
+		out, rerr := Expand(s, vars)
+		if rerr != nil {
+			err = fmt.Errorf("template field %s: %w (pass it with --var)", field, rerr)
+			return s
+		}
+		return out
+	}
+
+	out.Name = render("name", t.Name)
+	out.Description = render("description", t.Description)
+	out.Budget = render("budget", t.Budget)
+	out.Currency = render("currency", t.Currency)
+	out.Expiry = render("expiry", t.Expiry)
+	out.Parent = render("parent", t.Parent)
+	out.Routes = make([]string, len(t.Routes))
+	for i, r := range t.Routes {
+		out.Routes[i] = render("routes", r)
+	}
+	if t.Labels != nil {
+		out.Labels = make(map[string]string, len(t.Labels))
+		for k, v := range t.Labels {
+			out.Labels[k] = render("labels."+k, v)
+		}
+	}
+	return out, err
+}
+
+// Expand substitutes vars into a single string, erroring on variables that
+// were not supplied
+func Expand(s string, vars map[string]string) (string, error) {
+	if !strings.Contains(s, "{{") {
+		return s, nil
+	}
+	tmpl, err := template.New("").Option("missingkey=error").Parse(s)
+	if err != nil {
+		return s, err
+	}
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, vars); err != nil {
+		return s, err
+	}
+	return b.String(), nil
+}
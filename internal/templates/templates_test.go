@@ -0,0 +1,153 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestVars(t *testing.T) {
+	got := Vars("triage", map[string]string{
+		"team":        "support",
+		"env_name":    "prod",
+		"Region":      "eu",
+		"team-name":   "x",
+		"cost-center": "cc-1",
+		"1st":         "y",
+		"":            "z",
+	})
+	want := map[string]string{"Agent": "triage", "Team": "support", "Env_name": "prod", "Region": "eu"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Vars = %v; want %v", got, want)
+	}
+	if got := Vars("", nil); len(got) != 0 {
+		t.Errorf("Vars with no agent = %v; want empty", got)
+	}
+}
+
+func TestValidVar(t *testing.T) {
+	for key, want := range map[string]bool{
+		"team": true, "team_name": true, "_x": true, "T2": true,
+		"": false, "team-name": false, "2t": false, "a.b": false, "a b": false,
+	} {
+		if got := ValidVar(key); got != want {
+			t.Errorf("ValidVar(%q) = %v; want %v", key, got, want)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	tmpl := Template{
+		Name:   "{{.Team}}-{{.Agent}}",
+		Budget: "50",
+		Routes: []string{"/api/{{.Team}}/*"},
+		Labels: map[string]string{"team": "{{.Team}}", "env": "prod"},
+	}
+	got, err := tmpl.Render(Vars("triage", map[string]string{"team": "support"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Template{
+		Name:   "support-triage",
+		Budget: "50",
+		Routes: []string{"/api/support/*"},
+		Labels: map[string]string{"team": "support", "env": "prod"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Render = %+v; want %+v", got, want)
+	}
+	if tmpl.Name != "{{.Team}}-{{.Agent}}" || tmpl.Labels["team"] != "{{.Team}}" {
+		t.Errorf("Render modified its receiver: %+v", tmpl)
+	}
+}
+
+func TestRenderMissingVar(t *testing.T) {
+	tmpl := Template{Name: "{{.Agent}}", Labels: map[string]string{"team": "{{.Team}}"}}
+	_, err := tmpl.Render(Vars("triage", nil))
+	if err == nil {
+		t.Fatal("Render with a missing variable succeeded")
+	}
+	for _, want := range []string{"labels.team", "Team", "--var"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+}
+
+func TestExpand(t *testing.T) {
+	for _, tc := range []struct {
+		in, want string
+		err      bool
+	}{
+		{"plain", "plain", false},
+		{"secrets/{{.Agent}}.macaroon", "secrets/triage.macaroon", false},
+		{"{{.Missing}}", "{{.Missing}}", true},
+		{"{{.Agent", "{{.Agent", true},
+	} {
+		got, err := Expand(tc.in, map[string]string{"Agent": "triage"})
+		if got != tc.want || (err != nil) != tc.err {
+			t.Errorf("Expand(%q) = %q, %v; want %q, error %v", tc.in, got, err, tc.want, tc.err)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("support-bot.yaml", "budget: 75\nexpiry: 7d\n")
+	write("ops.yml", "budget: 10\n")
+	write("notes.yamlx", "budget: 1\n")
+
+	all, err := Load(map[string]Template{
+		"support-bot": {Budget: "50", Expiry: "30d"},
+		"batch":       {Budget: "5"},
+	}, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := Names(all), []string{"batch", "ops", "support-bot"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Names = %v; want %v", got, want)
+	}
+	if got := all["support-bot"]; got.Budget != "75" || got.Expiry != "7d" || got.Source != filepath.Join(dir, "support-bot.yaml") {
+		t.Errorf("file did not override config entry: %+v", got)
+	}
+	if got := all["batch"]; got.Source != "config" {
+		t.Errorf("batch source = %q; want config", got.Source)
+	}
+
+	if all, err := Load(nil, filepath.Join(dir, "missing")); err != nil || len(all) != 0 {
+		t.Errorf("Load of a missing dir = %v, %v; want empty, nil", all, err)
+	}
+
+	write("broken.yaml", "budget: [\n")
+	if _, err := Load(nil, dir); err == nil || !strings.Contains(err.Error(), "broken.yaml") {
+		t.Errorf("Load with a broken file: %v", err)
+	}
+}
+
+func TestSave(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "templates")
+	path, err := Save(dir, "support-bot", Template{Budget: "50"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Save(dir, "support-bot", Template{Budget: "60"}, false); err == nil {
+		t.Error("Save replaced an existing template without overwrite")
+	}
+	if _, err := Save(dir, "support-bot", Template{Budget: "60"}, true); err != nil {
+		t.Errorf("Save with overwrite: %v", err)
+	}
+	all, err := Load(nil, dir)
+	if err != nil || all["support-bot"].Budget != "60" || all["support-bot"].Source != path {
+		t.Errorf("Load after Save = %+v, %v", all, err)
+	}
+	if _, err := Save(dir, "Support Bot", Template{}, false); err == nil {
+		t.Error("Save accepted an invalid name")
+	}
+}